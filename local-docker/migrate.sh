@@ -7,7 +7,7 @@ COMPOSE_FILES="-f docker-compose.infrastructure.yml \
                -f docker-compose.services.yml"
 
 if [ $# -eq 0 ]; then
-  SERVICES="user-migrate team-migrate board-migrate"
+  SERVICES="user-migrate team-migrate board-migrate saga-migrate"
 else
   SERVICES="$@"
 fi
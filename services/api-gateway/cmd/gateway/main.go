@@ -11,9 +11,11 @@ import (
 
 	"api-gateway/internal/clients"
 	"api-gateway/internal/config"
+	"api-gateway/internal/middleware"
 	"api-gateway/internal/router"
 	"api-gateway/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -34,7 +36,43 @@ func main() {
 	}
 	defer grpcClients.Close()
 
-	r := router.New(cfg, grpcClients, log)
+	// Redis общий для denylist токенов и лимитов запросов. Недоступный Redis
+	// не мешает старту: пока его нет, запросы идут без этих проверок
+	var redisClient *redis.Client
+	if cfg.TokenDenylistEnabled || (cfg.RateLimitEnabled && cfg.RateLimitBackend == "redis") {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		defer redisClient.Close()
+
+		pingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := redisClient.Ping(pingCtx).Err(); err != nil {
+			log.Warn("Redis is unavailable", "addr", cfg.Redis.Addr, "error", err)
+		}
+		cancel()
+	}
+
+	var denylist middleware.TokenDenylist
+	if cfg.TokenDenylistEnabled {
+		denylist = middleware.NewRedisTokenDenylist(redisClient)
+	}
+
+	var limiter middleware.RateLimiter
+	if cfg.RateLimitEnabled {
+		switch cfg.RateLimitBackend {
+		case "memory":
+			limiter = middleware.NewMemoryRateLimiter()
+		case "redis":
+			limiter = middleware.NewRedisRateLimiter(redisClient)
+		default:
+			log.Fatal("Unknown rate limit backend", "backend", cfg.RateLimitBackend)
+		}
+		log.Info("Rate limiting enabled", "backend", cfg.RateLimitBackend, "rps", cfg.RateLimitRPS)
+	}
+
+	r := router.New(cfg, grpcClients, limiter, denylist, log)
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
@@ -9,6 +9,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"api-gateway/internal/config"
+	"shiroyama/authz/sessionrpc"
 
 	authv1 "github.com/cms-crs/protos/gen/go/auth_service"
 	boardv1 "github.com/cms-crs/protos/gen/go/board_service"
@@ -25,6 +26,8 @@ type GRPCClients struct {
 	BoardClient boardv1.BoardServiceClient
 	TaskClient  taskv1.TaskServiceClient
 	//ActivityClient activityv1.ActivityServiceClient
+
+	SessionClient *sessionrpc.Client
 }
 
 func NewGRPCClients(cfg *config.Config) (*GRPCClients, error) {
@@ -55,6 +58,7 @@ func NewGRPCClients(cfg *config.Config) (*GRPCClients, error) {
 	clients.TeamClient = teamv1.NewTeamServiceClient(clients.connections["team"])
 	clients.BoardClient = boardv1.NewBoardServiceClient(clients.connections["board"])
 	clients.TaskClient = taskv1.NewTaskServiceClient(clients.connections["task"])
+	clients.SessionClient = sessionrpc.NewClient(clients.connections["auth"])
 	//clients.CommentClient = commentv1.NewCommentServiceClient(clients.connections["comment"])
 	//clients.ActivityClient = activityv1.NewActivityServiceClient(clients.connections["activity"])
 
@@ -0,0 +1,33 @@
+package clients
+
+import (
+	"context"
+
+	boardv1 "github.com/cms-crs/protos/gen/go/board_service"
+	teamv1 "github.com/cms-crs/protos/gen/go/team_service"
+)
+
+// UserTeamRoles возвращает роли пользователя по ID команды.
+func (c *GRPCClients) UserTeamRoles(ctx context.Context, userID string) (map[string]string, error) {
+	response, err := c.TeamClient.GetUserTeams(ctx, &teamv1.GetUserTeamsRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]string, len(response.Teams))
+	for _, team := range response.Teams {
+		roles[team.Team.Id] = team.Role
+	}
+
+	return roles, nil
+}
+
+// BoardTeam возвращает ID команды, которой принадлежит доска.
+func (c *GRPCClients) BoardTeam(ctx context.Context, boardID string) (string, error) {
+	response, err := c.BoardClient.GetBoard(ctx, &boardv1.GetBoardRequest{Id: boardID})
+	if err != nil {
+		return "", err
+	}
+
+	return response.Board.TeamId, nil
+}
@@ -17,19 +17,40 @@ type Config struct {
 	AllowedMethods []string `json:"allowed_methods"`
 	AllowedHeaders []string `json:"allowed_headers"`
 
-	JWTSecret            string `json:"jwt_secret"`
-	AccessTokenDuration  int    `json:"access_token_duration"`
-	RefreshTokenDuration int    `json:"refresh_token_duration"`
+	// Открытые ключи auth-service для проверки access token
+	JWKSURL string `json:"jwks_url"`
+	// Сколько секунд шлюз помнит JWKS; новый kid запрашивается сразу
+	JWKSCacheTTL int `json:"jwks_cache_ttl"`
+
+	AccessTokenDuration  int `json:"access_token_duration"`
+	RefreshTokenDuration int `json:"refresh_token_duration"`
+	// Проверять jti access token по denylist в Redis, который ведёт auth-service
+	TokenDenylistEnabled bool `json:"token_denylist_enabled"`
 
 	Services ServiceConfig `json:"services"`
 
+	// Сколько секунд шлюз помнит роли пользователя в командах
+	TeamRoleCacheTTL int `json:"team_role_cache_ttl"`
+
 	RateLimitEnabled bool `json:"rate_limit_enabled"`
 	RateLimitRPS     int  `json:"rate_limit_rps"`
+	// Бюджет на минуту для /auth/*, где ключ — IP клиента: подбор паролей
+	// и массовая регистрация должны упираться в лимит раньше обычных запросов
+	RateLimitAuthRPM int `json:"rate_limit_auth_rpm"`
+	// memory — счётчики в памяти реплики, redis — общие для всех реплик
+	RateLimitBackend string      `json:"rate_limit_backend"`
+	Redis            RedisConfig `json:"redis"`
 
 	LogLevel  string `json:"log_level"`
 	LogFormat string `json:"log_format"`
 }
 
+type RedisConfig struct {
+	Addr     string `json:"addr"`
+	Password string `json:"password"`
+	DB       int    `json:"db"`
+}
+
 type ServiceConfig struct {
 	AuthService  string `json:"auth_service"`
 	UserService  string `json:"user_service"`
@@ -48,11 +69,13 @@ func Load() (*Config, error) {
 
 		AllowedOrigins: strings.Split(getEnv("ALLOWED_ORIGINS", "*"), ","),
 		AllowedMethods: strings.Split(getEnv("ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"), ","),
-		AllowedHeaders: strings.Split(getEnv("ALLOWED_HEADERS", "Accept,Authorization,Content-Type,X-CSRF-Token"), ","),
+		AllowedHeaders: strings.Split(getEnv("ALLOWED_HEADERS", "Accept,Authorization,Content-Type,X-CSRF-Token,X-Request-ID,traceparent"), ","),
 
-		JWTSecret:            getEnv("JWT_SECRET", "your-secret-key"),
+		JWKSURL:              getEnv("JWKS_URL", "http://localhost:8081/.well-known/jwks.json"),
+		JWKSCacheTTL:         getEnvInt("JWKS_CACHE_TTL", 300),
 		AccessTokenDuration:  getEnvInt("ACCESS_TOKEN_DURATION", 3600),
 		RefreshTokenDuration: getEnvInt("REFRESH_TOKEN_DURATION", 604800),
+		TokenDenylistEnabled: getEnvBool("TOKEN_DENYLIST_ENABLED", true),
 
 		Services: ServiceConfig{
 			AuthService:  getEnv("AUTH_SERVICE_URL", "localhost:44046"),
@@ -63,8 +86,17 @@ func Load() (*Config, error) {
 			//ActivityService: getEnv("ACTIVITY_SERVICE_URL", "localhost:50007"),
 		},
 
+		TeamRoleCacheTTL: getEnvInt("TEAM_ROLE_CACHE_TTL", 30),
+
 		RateLimitEnabled: getEnvBool("RATE_LIMIT_ENABLED", false),
 		RateLimitRPS:     getEnvInt("RATE_LIMIT_RPS", 100),
+		RateLimitAuthRPM: getEnvInt("RATE_LIMIT_AUTH_RPM", 20),
+		RateLimitBackend: getEnv("RATE_LIMIT_BACKEND", "memory"),
+		Redis: RedisConfig{
+			Addr:     getEnv("REDIS_HOST", "localhost") + ":" + getEnv("REDIS_PORT", "6379"),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       getEnvInt("REDIS_DB", 0),
+		},
 
 		LogLevel:  getEnv("LOG_LEVEL", "info"),
 		LogFormat: getEnv("LOG_FORMAT", "json"),
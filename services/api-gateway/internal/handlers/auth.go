@@ -11,6 +11,8 @@ import (
 	"api-gateway/internal/utils"
 	"api-gateway/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+	"shiroyama/authz/sessionrpc"
 
 	authv1 "github.com/cms-crs/protos/gen/go/auth_service"
 )
@@ -21,6 +23,15 @@ type AuthHandler struct {
 	logger      logger.Logger
 }
 
+// withClient передаёт auth-service устройство пользователя: по нему
+// различаются сессии.
+func withClient(ctx context.Context, c *gin.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		"x-client-user-agent", c.Request.UserAgent(),
+		"x-client-ip", c.ClientIP(),
+	)
+}
+
 func NewAuthHandler(grpcClients *clients.GRPCClients, cfg *config.Config, log logger.Logger) *AuthHandler {
 	return &AuthHandler{
 		grpcClients: grpcClients,
@@ -98,7 +109,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
-	response, err := authClient.Login(ctx, &authv1.LoginRequest{
+	response, err := authClient.Login(withClient(ctx, c), &authv1.LoginRequest{
 		Email:    req.Email,
 		Password: req.Password,
 	})
@@ -143,7 +154,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
-	response, err := authClient.RefreshToken(ctx, &authv1.RefreshTokenRequest{
+	response, err := authClient.RefreshToken(withClient(ctx, c), &authv1.RefreshTokenRequest{
 		RefreshToken: req.RefreshToken,
 	})
 	if err != nil {
@@ -201,19 +212,18 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
 
 // Logout godoc
 // @Summary Logout user
-// @Description Logout user and invalidate token
+// @Description Logout user: revoke refresh token and the access token from the Authorization header
 // @Tags auth
-// @Accept json
 // @Produce json
-// @Param request body models.LogoutRequest true "Logout request"
+// @Security Bearer
 // @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response
+// @Failure 401 {object} models.Response
 // @Failure 500 {object} models.Response
 // @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	var req models.LogoutRequest
-	if err := utils.BindAndValidate(c, &req); err != nil {
-		utils.ValidationErrorResponse(c, err)
+	token := c.GetString("access_token")
+	if token == "" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
 		return
 	}
 
@@ -225,7 +235,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	defer cancel()
 
 	_, err := authClient.Logout(ctx, &authv1.LogoutRequest{
-		Token: req.Token,
+		Token: token,
 	})
 	if err != nil {
 		h.logger.Error("Failed to logout user", "error", err)
@@ -236,3 +246,70 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	h.logger.Info("User logged out successfully")
 	utils.SuccessResponse(c, gin.H{"message": "Logged out successfully"})
 }
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description List active sessions of the current user, one per device
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.Response{data=models.ListSessionsResponse}
+// @Failure 401 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	sessions, err := h.grpcClients.SessionClient.ListSessions(ctx, &sessionrpc.ListSessionsRequest{})
+	if err != nil {
+		h.logger.Error("Failed to list sessions", "error", err)
+		code, message := utils.GRPCErrorToHTTP(err)
+		utils.ErrorResponse(c, code, message)
+		return
+	}
+
+	response := &models.ListSessionsResponse{Sessions: make([]models.Session, 0, len(sessions.Sessions))}
+	for _, session := range sessions.Sessions {
+		response.Sessions = append(response.Sessions, models.Session{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.Current,
+		})
+	}
+
+	utils.SuccessResponse(c, response)
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description Revoke one of the current user's sessions and its tokens
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Session ID"
+// @Success 200 {object} models.Response
+// @Failure 401 {object} models.Response
+// @Failure 404 {object} models.Response
+// @Failure 500 {object} models.Response
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID := utils.GetParamID(c, "id")
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := h.grpcClients.SessionClient.RevokeSession(ctx, &sessionrpc.RevokeSessionRequest{SessionID: sessionID}); err != nil {
+		h.logger.Error("Failed to revoke session", "session_id", sessionID, "error", err)
+		code, message := utils.GRPCErrorToHTTP(err)
+		utils.ErrorResponse(c, code, message)
+		return
+	}
+
+	h.logger.Info("Session revoked", "session_id", sessionID)
+	utils.SuccessResponse(c, gin.H{"message": "Session revoked successfully"})
+}
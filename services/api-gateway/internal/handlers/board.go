@@ -352,6 +352,7 @@ func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 // @Success 200 {object} models.Response{data=models.GetUserBoardsResponse}
 // @Failure 400 {object} models.Response
 // @Failure 401 {object} models.Response
+// @Failure 403 {object} models.Response
 // @Failure 500 {object} models.Response
 // @Router /api/v1/users/{id}/boards [get]
 func (h *BoardHandler) GetUserBoards(c *gin.Context) {
@@ -361,6 +362,12 @@ func (h *BoardHandler) GetUserBoards(c *gin.Context) {
 		return
 	}
 
+	currentUserID, exists := utils.GetUserIDFromContext(c)
+	if !exists || currentUserID != userID {
+		utils.ErrorResponse(c, http.StatusForbidden, "You can only view your own boards")
+		return
+	}
+
 	h.logger.Info("Getting user boards", "user_id", userID)
 
 	boardClient := h.grpcClients.GetBoardClient().(boardv1.BoardServiceClient)
@@ -376,6 +383,9 @@ func (h *BoardHandler) GetUserBoards(c *gin.Context) {
 			case codes.InvalidArgument:
 				utils.ErrorResponse(c, http.StatusBadRequest, st.Message())
 				return
+			case codes.PermissionDenied:
+				utils.ErrorResponse(c, http.StatusForbidden, "Permission denied")
+				return
 			case codes.NotFound:
 				utils.ErrorResponse(c, http.StatusNotFound, "User not found")
 				return
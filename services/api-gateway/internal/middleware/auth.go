@@ -1,23 +1,20 @@
 package middleware
 
 import (
-	"fmt"
-	"github.com/golang-jwt/jwt/v5"
 	"net/http"
 	"strings"
 
-	"api-gateway/internal/config"
 	"api-gateway/internal/utils"
+	"api-gateway/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+	"shiroyama/authz"
 )
 
-type Claims struct {
-	Email string `json:"email"`
-	Role  string `json:"role"`
-	jwt.RegisteredClaims
-}
-
-func Auth(cfg *config.Config) gin.HandlerFunc {
+// Auth проверяет access token и отклоняет отозванные через Logout — сами по
+// себе или вместе с сессией. denylist равен nil, если проверка отзыва
+// выключена; при недоступном Redis запрос пропускается, как и в RateLimit.
+func Auth(keys *authz.JWKS, denylist TokenDenylist, log logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -33,30 +30,39 @@ func Auth(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		fmt.Println(tokenString)
-		fmt.Println(cfg.JWTSecret)
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(cfg.JWTSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			fmt.Println(err.Error())
-			fmt.Println(token.Valid)
+		claims, err := authz.ParseAccessToken(tokenString, keys)
+		if err != nil {
+			log.Debug("Invalid token", "error", err)
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token")
 			c.Abort()
 			return
 		}
 
+		if denylist != nil {
+			denied, err := denylist.IsDenied(c.Request.Context(), claims.ID, claims.SessionID)
+			if err != nil {
+				log.Warn("Token denylist check failed, allowing request", "error", err)
+			} else if denied {
+				utils.ErrorResponse(c, http.StatusUnauthorized, "Token has been revoked")
+				c.Abort()
+				return
+			}
+		}
+
 		c.Set("user_id", claims.Subject)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("access_token", tokenString)
+
+		// Сервисы сами проверяют токен и права пользователя в команде
+		ctx := metadata.AppendToOutgoingContext(c.Request.Context(), "authorization", "Bearer "+tokenString)
+		c.Request = c.Request.WithContext(ctx)
 
 		c.Next()
 	}
 }
 
-func OptionalAuth(cfg *config.Config) gin.HandlerFunc {
+func OptionalAuth(keys *authz.JWKS) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -70,12 +76,8 @@ func OptionalAuth(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(cfg.JWTSecret), nil
-		})
-
-		if err == nil && token.Valid {
+		claims, err := authz.ParseAccessToken(tokenString, keys)
+		if err == nil {
 			c.Set("user_id", claims.Subject)
 			c.Set("email", claims.Email)
 			c.Set("role", claims.Role)
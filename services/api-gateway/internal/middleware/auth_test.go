@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"shiroyama/authz"
+)
+
+// keyServer отдаёт JWKS, как auth-service, и подписывает токены его ключами.
+type keyServer struct {
+	*httptest.Server
+
+	mu   sync.Mutex
+	keys map[string]ed25519.PrivateKey
+}
+
+func newKeyServer(t *testing.T, kids ...string) *keyServer {
+	t.Helper()
+
+	ks := &keyServer{keys: make(map[string]ed25519.PrivateKey)}
+	for _, kid := range kids {
+		ks.addKey(t, kid)
+	}
+
+	ks.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		ks.mu.Lock()
+		defer ks.mu.Unlock()
+
+		type jwk struct {
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Kid string `json:"kid"`
+		}
+		set := struct {
+			Keys []jwk `json:"keys"`
+		}{}
+		for kid, key := range ks.keys {
+			x := base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+			set.Keys = append(set.Keys, jwk{Kty: "OKP", Crv: "Ed25519", X: x, Kid: kid})
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(ks.Close)
+
+	return ks
+}
+
+func (ks *keyServer) addKey(t *testing.T, kid string) {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	ks.mu.Lock()
+	ks.keys[kid] = key
+	ks.mu.Unlock()
+}
+
+func (ks *keyServer) sign(t *testing.T, kid, jti string) string {
+	t.Helper()
+
+	return ks.signClaims(t, kid, testClaims(jti))
+}
+
+func (ks *keyServer) signClaims(t *testing.T, kid string, claims authz.Claims) string {
+	t.Helper()
+
+	ks.mu.Lock()
+	key := ks.keys[kid]
+	ks.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func (ks *keyServer) jwks() *authz.JWKS {
+	return authz.NewJWKS(ks.URL, time.Minute)
+}
+
+func testClaims(jti string) authz.Claims {
+	return authz.Claims{
+		Type: authz.AccessToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
+
+// tokenDenylist помнит отозванные jti и сессии, как Redis после Logout.
+type tokenDenylist struct {
+	denied   map[string]bool
+	sessions map[string]bool
+	err      error
+}
+
+func (d *tokenDenylist) IsDenied(_ context.Context, jti, sessionID string) (bool, error) {
+	if d.err != nil {
+		return false, d.err
+	}
+	return d.denied[jti] || d.sessions[sessionID], nil
+}
+
+func doAuthRequest(keys *authz.JWKS, denylist TokenDenylist, token string) int {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/me", Auth(keys, denylist, discardLogger{}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestAuthRejectsRevokedToken(t *testing.T) {
+	ks := newKeyServer(t, "key-1")
+	keys := ks.jwks()
+	denylist := &tokenDenylist{denied: map[string]bool{"revoked": true}}
+
+	if code := doAuthRequest(keys, denylist, ks.sign(t, "key-1", "revoked")); code != http.StatusUnauthorized {
+		t.Fatalf("revoked token: expected 401, got %d", code)
+	}
+	if code := doAuthRequest(keys, denylist, ks.sign(t, "key-1", "active")); code != http.StatusOK {
+		t.Fatalf("active token: expected 200, got %d", code)
+	}
+	// Токены, выпущенные до появления jti, denylist не проверяет
+	if code := doAuthRequest(keys, denylist, ks.sign(t, "key-1", "")); code != http.StatusOK {
+		t.Fatalf("token without jti: expected 200, got %d", code)
+	}
+}
+
+func TestAuthFailsOpenWhenDenylistIsUnavailable(t *testing.T) {
+	ks := newKeyServer(t, "key-1")
+	denylist := &tokenDenylist{err: errors.New("connection refused")}
+
+	if code := doAuthRequest(ks.jwks(), denylist, ks.sign(t, "key-1", "revoked")); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+}
+
+func TestAuthRejectsForeignTokens(t *testing.T) {
+	ks := newKeyServer(t, "key-1")
+	keys := ks.jwks()
+
+	other := newKeyServer(t, "key-1", "key-2")
+	if code := doAuthRequest(keys, nil, other.sign(t, "key-1", "")); code != http.StatusUnauthorized {
+		t.Fatalf("token signed by another key: expected 401, got %d", code)
+	}
+	if code := doAuthRequest(keys, nil, other.sign(t, "key-2", "")); code != http.StatusUnauthorized {
+		t.Fatalf("token with unknown kid: expected 401, got %d", code)
+	}
+
+	// Симметричный токен не принимается, даже если подписан открытым ключом
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims(""))
+	hmac.Header["kid"] = "key-1"
+	public := ks.keys["key-1"].Public().(ed25519.PublicKey)
+	signed, err := hmac.SignedString([]byte(public))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if code := doAuthRequest(keys, nil, signed); code != http.StatusUnauthorized {
+		t.Fatalf("HS256 token: expected 401, got %d", code)
+	}
+}
+
+func TestAuthRejectsRefreshToken(t *testing.T) {
+	ks := newKeyServer(t, "key-1")
+	keys := ks.jwks()
+
+	refresh := testClaims("refresh")
+	refresh.Type = authz.RefreshToken
+	if code := doAuthRequest(keys, nil, ks.signClaims(t, "key-1", refresh)); code != http.StatusUnauthorized {
+		t.Fatalf("refresh token: expected 401, got %d", code)
+	}
+
+	untyped := testClaims("untyped")
+	untyped.Type = ""
+	if code := doAuthRequest(keys, nil, ks.signClaims(t, "key-1", untyped)); code != http.StatusUnauthorized {
+		t.Fatalf("token without type: expected 401, got %d", code)
+	}
+}
+
+func TestAuthRejectsTokensOfRevokedSession(t *testing.T) {
+	ks := newKeyServer(t, "key-1")
+	keys := ks.jwks()
+	denylist := &tokenDenylist{sessions: map[string]bool{"revoked": true}}
+
+	// Токен выдан обменом refresh token, о котором отзыв сессии не знал
+	revoked := testClaims("issued-concurrently")
+	revoked.SessionID = "revoked"
+	if code := doAuthRequest(keys, denylist, ks.signClaims(t, "key-1", revoked)); code != http.StatusUnauthorized {
+		t.Fatalf("token of revoked session: expected 401, got %d", code)
+	}
+
+	active := testClaims("active")
+	active.SessionID = "active"
+	if code := doAuthRequest(keys, denylist, ks.signClaims(t, "key-1", active)); code != http.StatusOK {
+		t.Fatalf("token of active session: expected 200, got %d", code)
+	}
+}
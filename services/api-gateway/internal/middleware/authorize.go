@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"api-gateway/internal/utils"
+	"api-gateway/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"shiroyama/authz"
+)
+
+// TeamRoleResolver возвращает роль пользователя в команде.
+type TeamRoleResolver interface {
+	Role(ctx context.Context, userID, teamID string) (authz.Role, error)
+}
+
+// TeamLocator находит команду, которой принадлежит ресурс запроса.
+type TeamLocator func(c *gin.Context) (string, error)
+
+// TeamFromParam берёт ID команды из параметра пути.
+func TeamFromParam(name string) TeamLocator {
+	return func(c *gin.Context) (string, error) {
+		return c.Param(name), nil
+	}
+}
+
+// TeamFromLookup находит команду по ID ресурса из параметра пути, например
+// команду доски через board-service.
+func TeamFromLookup(name string, lookup func(ctx context.Context, id string) (string, error)) TeamLocator {
+	return func(c *gin.Context) (string, error) {
+		return lookup(c.Request.Context(), c.Param(name))
+	}
+}
+
+// Authorize пропускает запрос, только если роль пользователя в команде
+// ресурса даёт право permission. Должен стоять после Auth. В отличие от
+// RateLimit, без team-service запрос отклоняется.
+func Authorize(roles TeamRoleResolver, permission authz.Permission, locate TeamLocator, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := utils.GetUserIDFromContext(c)
+		if !ok || userID == "" {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+			c.Abort()
+			return
+		}
+
+		teamID, err := locate(c)
+		if err != nil {
+			log.Warn("Failed to resolve team of resource", "path", c.FullPath(), "error", err)
+			code, message := utils.GRPCErrorToHTTP(err)
+			utils.ErrorResponse(c, code, message)
+			c.Abort()
+			return
+		}
+
+		role, err := roles.Role(c.Request.Context(), userID, teamID)
+		if err != nil {
+			log.Error("Failed to resolve team role", "user_id", userID, "team_id", teamID, "error", err)
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "Failed to check permissions")
+			c.Abort()
+			return
+		}
+
+		if !role.Can(permission) {
+			log.Info("Permission denied",
+				"user_id", userID,
+				"team_id", teamID,
+				"role", string(role),
+				"permission", string(permission),
+			)
+			utils.ErrorResponse(c, http.StatusForbidden, "Permission denied")
+			c.Abort()
+			return
+		}
+
+		c.Set("team_role", string(role))
+		c.Next()
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"shiroyama/authz"
+)
+
+// teamRoleSource отдаёт роли из карты и считает обращения к team-service.
+type teamRoleSource struct {
+	roles map[string]map[string]string
+	calls int
+	err   error
+}
+
+func (s *teamRoleSource) UserTeamRoles(_ context.Context, userID string) (map[string]string, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.roles[userID], nil
+}
+
+func newAuthorizedRouter(source authz.TeamRoleSource) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	roles := authz.NewTeamRoles(source, time.Minute)
+	teamParam := TeamFromParam("team_id")
+	boardParam := TeamFromLookup("id", func(_ context.Context, boardID string) (string, error) {
+		if boardID != "board-1" {
+			return "", status.Error(codes.NotFound, "board not found")
+		}
+		return "team-1", nil
+	})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("user_id", userID)
+		}
+	})
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/teams/:team_id", Authorize(roles, authz.PermissionRead, teamParam, discardLogger{}), ok)
+	r.PUT("/teams/:team_id", Authorize(roles, authz.PermissionManageTeam, teamParam, discardLogger{}), ok)
+	r.PUT("/boards/:id", Authorize(roles, authz.PermissionWrite, boardParam, discardLogger{}), ok)
+	r.DELETE("/boards/:id", Authorize(roles, authz.PermissionDeleteBoards, boardParam, discardLogger{}), ok)
+
+	return r
+}
+
+func TestAuthorizeAppliesPermissionMatrix(t *testing.T) {
+	r := newAuthorizedRouter(&teamRoleSource{roles: map[string]map[string]string{
+		"admin":  {"team-1": "admin"},
+		"member": {"team-1": "member"},
+		"viewer": {"team-1": "viewer"},
+	}})
+
+	tests := []struct {
+		user, method, path string
+		want               int
+	}{
+		{"viewer", http.MethodGet, "/teams/team-1", http.StatusOK},
+		{"viewer", http.MethodPut, "/boards/board-1", http.StatusForbidden},
+		{"member", http.MethodPut, "/boards/board-1", http.StatusOK},
+		{"member", http.MethodDelete, "/boards/board-1", http.StatusForbidden},
+		{"member", http.MethodPut, "/teams/team-1", http.StatusForbidden},
+		{"admin", http.MethodDelete, "/boards/board-1", http.StatusOK},
+		{"admin", http.MethodPut, "/teams/team-1", http.StatusOK},
+		// Чужая команда недоступна даже администратору другой команды
+		{"admin", http.MethodGet, "/teams/team-2", http.StatusForbidden},
+		{"", http.MethodGet, "/teams/team-1", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		if code := doRequest(r, tt.method, tt.path, tt.user).Code; code != tt.want {
+			t.Errorf("%s %s as %q: expected %d, got %d", tt.method, tt.path, tt.user, tt.want, code)
+		}
+	}
+}
+
+func TestAuthorizeReportsMissingResource(t *testing.T) {
+	r := newAuthorizedRouter(&teamRoleSource{roles: map[string]map[string]string{
+		"admin": {"team-1": "admin"},
+	}})
+
+	if code := doRequest(r, http.MethodPut, "/boards/board-2", "admin").Code; code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+}
+
+func TestAuthorizeFailsClosed(t *testing.T) {
+	r := newAuthorizedRouter(&teamRoleSource{err: errors.New("connection refused")})
+
+	if code := doRequest(r, http.MethodGet, "/teams/team-1", "admin").Code; code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", code)
+	}
+}
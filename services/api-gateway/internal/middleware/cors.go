@@ -7,11 +7,20 @@ import (
 )
 
 func CORS(cfg *config.Config) gin.HandlerFunc {
+	exposeHeaders := []string{
+		"Content-Length",
+		RequestIDHeader,
+		"Retry-After",
+		"X-RateLimit-Limit",
+		"X-RateLimit-Remaining",
+		"X-RateLimit-Reset",
+	}
+
 	config := cors.Config{
 		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     cfg.AllowedMethods,
 		AllowHeaders:     cfg.AllowedHeaders,
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    exposeHeaders,
 		AllowCredentials: true,
 	}
 
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Auth-service кладёт jti отозванных access token и ID отозванных сессий под
+// теми же префиксами.
+const (
+	deniedTokenKeyPrefix   = "denylist:"
+	deniedSessionKeyPrefix = "denylist:session:"
+)
+
+// Проверка идёт на каждый защищённый запрос, поэтому медленный Redis не
+// должен задерживать его дольше этого.
+const denylistTimeout = 50 * time.Millisecond
+
+// TokenDenylist отвечает, отозван ли access token с данным jti сам или
+// вместе с сессией sessionID.
+type TokenDenylist interface {
+	IsDenied(ctx context.Context, jti, sessionID string) (bool, error)
+}
+
+// RedisTokenDenylist читает denylist, который ведёт auth-service при Logout.
+// Ключи живут до истечения токена, так что список не растёт.
+type RedisTokenDenylist struct {
+	client redis.UniversalClient
+}
+
+func NewRedisTokenDenylist(client redis.UniversalClient) *RedisTokenDenylist {
+	return &RedisTokenDenylist{client: client}
+}
+
+func (d *RedisTokenDenylist) IsDenied(ctx context.Context, jti, sessionID string) (bool, error) {
+	const op = "RedisTokenDenylist.IsDenied"
+
+	var keys []string
+	if jti != "" {
+		keys = append(keys, deniedTokenKeyPrefix+jti)
+	}
+	if sessionID != "" {
+		keys = append(keys, deniedSessionKeyPrefix+sessionID)
+	}
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, denylistTimeout)
+	defer cancel()
+
+	count, err := d.client.Exists(ctx, keys...).Result()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count > 0, nil
+}
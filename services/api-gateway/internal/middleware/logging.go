@@ -17,6 +17,8 @@ func Logging(log logger.Logger) gin.HandlerFunc {
 			"method", param.Method,
 			"path", param.Path,
 			"error", param.ErrorMessage,
+			"request_id", param.Keys["request_id"],
+			"trace_id", param.Keys["trace_id"],
 		)
 		return ""
 	})
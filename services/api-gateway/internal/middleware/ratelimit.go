@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"api-gateway/internal/utils"
+	"api-gateway/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Budget — сколько запросов разрешено за окно Per.
+type Budget struct {
+	Requests int
+	Per      time.Duration
+}
+
+// RateLimits — бюджеты по маршрутам. Ключ маршрута — метод и шаблон пути
+// gin, например "POST /api/v1/auth/login"; остальные маршруты получают Default.
+type RateLimits struct {
+	Default Budget
+	Routes  map[string]Budget
+}
+
+func (l RateLimits) budget(route string) Budget {
+	if budget, ok := l.Routes[route]; ok {
+		return budget
+	}
+	return l.Default
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// RateLimiter считает запросы по ключу в окне бюджета.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, budget Budget) (RateLimitResult, error)
+}
+
+// RateLimit ограничивает запросы аутентифицированного пользователя, а без
+// user_id в контексте (маршруты /auth/*) — IP клиента. У каждого маршрута
+// свой счётчик. Должен стоять после Auth, иначе все запросы считаются по IP.
+func RateLimit(limiter RateLimiter, limits RateLimits, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		budget := limits.budget(route)
+		if budget.Requests <= 0 || budget.Per <= 0 {
+			c.Next()
+			return
+		}
+
+		subject := "ip:" + c.ClientIP()
+		if userID, ok := utils.GetUserIDFromContext(c); ok && userID != "" {
+			subject = "user:" + userID
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), route+"|"+subject, budget)
+		if err != nil {
+			// Без хранилища счётчиков шлюз продолжает работать без лимита
+			log.Warn("Rate limiter unavailable", "route", route, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(time.Until(result.ResetAt).Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "Rate limit exceeded")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Как часто MemoryRateLimiter удаляет истёкшие окна.
+const rateLimitSweepInterval = time.Minute
+
+// MemoryRateLimiter считает запросы фиксированными окнами в памяти реплики.
+// Подходит для одного экземпляра шлюза: у нескольких реплик счётчики свои.
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		windows: make(map[string]*rateWindow),
+		now:     time.Now,
+	}
+}
+
+func (l *MemoryRateLimiter) Allow(_ context.Context, key string, budget Budget) (RateLimitResult, error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		for windowKey, window := range l.windows {
+			if !now.Before(window.resetAt) {
+				delete(l.windows, windowKey)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	window, ok := l.windows[key]
+	if !ok || !now.Before(window.resetAt) {
+		window = &rateWindow{resetAt: now.Add(budget.Per)}
+		l.windows[key] = window
+	}
+	window.count++
+
+	return newRateLimitResult(budget, window.count, window.resetAt), nil
+}
+
+func newRateLimitResult(budget Budget, count int, resetAt time.Time) RateLimitResult {
+	return RateLimitResult{
+		Allowed:   count <= budget.Requests,
+		Limit:     budget.Requests,
+		Remaining: max(budget.Requests-count, 0),
+		ResetAt:   resetAt,
+	}
+}
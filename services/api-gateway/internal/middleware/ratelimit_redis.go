@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// Счётчик и срок окна меняются одним скриптом: иначе при падении между
+// INCR и PEXPIRE ключ остался бы без TTL и навсегда заблокировал клиента.
+var rateLimitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// RedisRateLimiter считает запросы фиксированными окнами в Redis, поэтому
+// лимит общий для всех реплик шлюза.
+type RedisRateLimiter struct {
+	client redis.UniversalClient
+}
+
+func NewRedisRateLimiter(client redis.UniversalClient) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client}
+}
+
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, budget Budget) (RateLimitResult, error) {
+	const op = "RedisRateLimiter.Allow"
+
+	values, err := rateLimitScript.Run(ctx, l.client, []string{rateLimitKeyPrefix + key}, budget.Per.Milliseconds()).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(values) != 2 {
+		return RateLimitResult{}, fmt.Errorf("%s: unexpected script result %v", op, values)
+	}
+
+	ttl := time.Duration(values[1]) * time.Millisecond
+	if ttl < 0 {
+		ttl = budget.Per
+	}
+
+	return newRateLimitResult(budget, int(values[0]), time.Now().Add(ttl)), nil
+}
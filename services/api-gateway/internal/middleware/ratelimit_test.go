@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Debug(string, ...any) {}
+func (discardLogger) Info(string, ...any)  {}
+func (discardLogger) Warn(string, ...any)  {}
+func (discardLogger) Error(string, ...any) {}
+func (discardLogger) Fatal(string, ...any) {}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, Budget) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("connection refused")
+}
+
+func newRateLimitedRouter(limiter RateLimiter, limits RateLimits) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	authenticate := func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("user_id", userID)
+		}
+	}
+
+	r.Use(authenticate, RateLimit(limiter, limits, discardLogger{}))
+	r.GET("/boards/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/auth/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return r
+}
+
+func doRequest(r *gin.Engine, method, path, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimitRejectsRequestsOverBudget(t *testing.T) {
+	r := newRateLimitedRouter(NewMemoryRateLimiter(), RateLimits{
+		Default: Budget{Requests: 2, Per: time.Minute},
+	})
+
+	for i := 0; i < 2; i++ {
+		if code := doRequest(r, http.MethodGet, "/boards/1", "user-1").Code; code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, code)
+		}
+	}
+
+	resp := doRequest(r, http.MethodGet, "/boards/1", "user-1")
+	if resp.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", resp.Code)
+	}
+	if resp.Header().Get("X-RateLimit-Limit") != "2" || resp.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("unexpected rate limit headers: %v", resp.Header())
+	}
+
+	retryAfter, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Fatalf("unexpected Retry-After %q", resp.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimitKeysOnUserThenIP(t *testing.T) {
+	r := newRateLimitedRouter(NewMemoryRateLimiter(), RateLimits{
+		Default: Budget{Requests: 1, Per: time.Minute},
+	})
+
+	// У разных пользователей с одного IP бюджеты раздельные
+	if code := doRequest(r, http.MethodGet, "/boards/1", "user-1").Code; code != http.StatusOK {
+		t.Fatalf("user-1: expected 200, got %d", code)
+	}
+	if code := doRequest(r, http.MethodGet, "/boards/1", "user-2").Code; code != http.StatusOK {
+		t.Fatalf("user-2: expected 200, got %d", code)
+	}
+
+	// Без пользователя считается IP
+	if code := doRequest(r, http.MethodPost, "/auth/login", "").Code; code != http.StatusOK {
+		t.Fatalf("first login: expected 200, got %d", code)
+	}
+	if code := doRequest(r, http.MethodPost, "/auth/login", "").Code; code != http.StatusTooManyRequests {
+		t.Fatalf("second login: expected 429, got %d", code)
+	}
+}
+
+func TestRateLimitUsesRouteBudgets(t *testing.T) {
+	r := newRateLimitedRouter(NewMemoryRateLimiter(), RateLimits{
+		Default: Budget{Requests: 100, Per: time.Second},
+		Routes: map[string]Budget{
+			"POST /auth/login": {Requests: 1, Per: time.Minute},
+		},
+	})
+
+	doRequest(r, http.MethodPost, "/auth/login", "")
+	if code := doRequest(r, http.MethodPost, "/auth/login", "").Code; code != http.StatusTooManyRequests {
+		t.Fatalf("login: expected 429, got %d", code)
+	}
+
+	// Исчерпанный бюджет входа не трогает другие маршруты
+	resp := doRequest(r, http.MethodGet, "/boards/1", "")
+	if resp.Code != http.StatusOK || resp.Header().Get("X-RateLimit-Limit") != "100" {
+		t.Fatalf("boards: expected 200 with limit 100, got %d %v", resp.Code, resp.Header())
+	}
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	r := newRateLimitedRouter(failingLimiter{}, RateLimits{
+		Default: Budget{Requests: 1, Per: time.Minute},
+	})
+
+	for i := 0; i < 3; i++ {
+		if code := doRequest(r, http.MethodGet, "/boards/1", "user-1").Code; code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, code)
+		}
+	}
+}
+
+func TestMemoryRateLimiterStartsNewWindow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	budget := Budget{Requests: 1, Per: time.Second}
+	if result, _ := limiter.Allow(context.Background(), "key", budget); !result.Allowed {
+		t.Fatal("first request must be allowed")
+	}
+	if result, _ := limiter.Allow(context.Background(), "key", budget); result.Allowed {
+		t.Fatal("second request in the window must be rejected")
+	}
+
+	now = now.Add(time.Second)
+	result, _ := limiter.Allow(context.Background(), "key", budget)
+	if !result.Allowed || result.Remaining != 0 || !result.ResetAt.Equal(now.Add(time.Second)) {
+		t.Fatalf("expected a fresh window, got %+v", result)
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent"
+)
+
+// Trace назначает запросу request ID и W3C traceparent, если клиент их не
+// прислал, и передаёт их в gRPC-метаданных всем вызовам сервисов. Дальше
+// user-service кладёт их в заголовки событий, и по ним склеиваются логи
+// саги в оркестраторе и участниках.
+func Trace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = randomHex(16)
+		}
+
+		traceID, flags, ok := parseTraceparent(c.GetHeader(TraceparentHeader))
+		if !ok {
+			traceID, flags = randomHex(16), "01"
+		}
+		// Шлюз — отдельный участник трассы, поэтому сервисам уходит новый parent-id
+		traceparent := "00-" + traceID + "-" + randomHex(8) + "-" + flags
+
+		c.Set("request_id", requestID)
+		c.Set("trace_id", traceID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := metadata.AppendToOutgoingContext(c.Request.Context(),
+			"x-request-id", requestID,
+			"traceparent", traceparent,
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+func parseTraceparent(value string) (traceID, flags string, ok bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return "", "", false
+	}
+	if !isLowerHex(parts[1], 32) || !isLowerHex(parts[2], 16) || !isLowerHex(parts[3], 2) {
+		return "", "", false
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false
+	}
+	return parts[1], parts[3], true
+}
+
+func isLowerHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(size int) string {
+	buf := make([]byte, size)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -14,12 +14,16 @@ import (
 	"api-gateway/internal/models"
 	"api-gateway/internal/utils"
 	"api-gateway/pkg/logger"
+	"shiroyama/authz"
 )
 
-func New(cfg *config.Config, grpcClients *clients.GRPCClients, log logger.Logger) *gin.Engine {
+// New собирает маршруты шлюза. limiter равен nil, если ограничение частоты
+// запросов выключено, denylist — если выключена проверка отозванных токенов.
+func New(cfg *config.Config, grpcClients *clients.GRPCClients, limiter middleware.RateLimiter, denylist middleware.TokenDenylist, log logger.Logger) *gin.Engine {
 	r := gin.New()
 
 	// Middleware
+	r.Use(middleware.Trace())
 	r.Use(middleware.Recovery(log))
 	r.Use(middleware.Logging(log))
 	r.Use(middleware.CORS(cfg))
@@ -35,19 +39,41 @@ func New(cfg *config.Config, grpcClients *clients.GRPCClients, log logger.Logger
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	limits := rateLimits(cfg)
+
+	keys := authz.NewJWKS(cfg.JWKSURL, time.Duration(cfg.JWKSCacheTTL)*time.Second)
+
+	// Права в команде: viewer только читает, member меняет доски и задачи,
+	// admin управляет командой, участниками и удаляет доски. Команду списков
+	// и задач шлюз узнать не может, их права проверяют board- и task-service.
+	roles := authz.NewTeamRoles(grpcClients, time.Duration(cfg.TeamRoleCacheTTL)*time.Second)
+	teamParam := middleware.TeamFromParam("team_id")
+	boardParam := middleware.TeamFromLookup("id", grpcClients.BoardTeam)
+	can := func(permission authz.Permission, locate middleware.TeamLocator) gin.HandlerFunc {
+		return middleware.Authorize(roles, permission, locate, log)
+	}
+
 	v1 := r.Group("/api/v1")
 
 	auth := v1.Group("/auth")
+	if limiter != nil {
+		auth.Use(middleware.RateLimit(limiter, limits, log))
+	}
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
 		auth.POST("/refresh", authHandler.RefreshToken)
-		auth.POST("/logout", authHandler.Logout)
+		auth.POST("/logout", middleware.Auth(keys, denylist, log), authHandler.Logout)
 		auth.POST("/validate", authHandler.ValidateToken)
+		auth.GET("/sessions", middleware.Auth(keys, denylist, log), authHandler.ListSessions)
+		auth.DELETE("/sessions/:id", middleware.Auth(keys, denylist, log), authHandler.RevokeSession)
 	}
 
 	protected := v1.Group("")
-	protected.Use(middleware.Auth(cfg))
+	protected.Use(middleware.Auth(keys, denylist, log))
+	if limiter != nil {
+		protected.Use(middleware.RateLimit(limiter, limits, log))
+	}
 
 	users := protected.Group("/users")
 	{
@@ -64,27 +90,27 @@ func New(cfg *config.Config, grpcClients *clients.GRPCClients, log logger.Logger
 	teams := protected.Group("/teams")
 	{
 		teams.POST("", teamHandler.CreateTeam)
-		teams.GET("/:team_id", teamHandler.GetTeam)
-		teams.PUT("/:team_id", teamHandler.UpdateTeam)
-		teams.DELETE("/:team_id", teamHandler.DeleteTeam)
+		teams.GET("/:team_id", can(authz.PermissionRead, teamParam), teamHandler.GetTeam)
+		teams.PUT("/:team_id", can(authz.PermissionManageTeam, teamParam), teamHandler.UpdateTeam)
+		teams.DELETE("/:team_id", can(authz.PermissionManageTeam, teamParam), teamHandler.DeleteTeam)
 
-		teams.POST("/:team_id/members", teamHandler.AddUserToTeam)
-		teams.DELETE("/:team_id/members/:user_id", teamHandler.RemoveUserFromTeam)
-		teams.PUT("/:team_id/members/:user_id/role", teamHandler.UpdateUserRole)
-		teams.GET("/:team_id/members", teamHandler.GetTeamMembers)
+		teams.POST("/:team_id/members", can(authz.PermissionManageMembers, teamParam), teamHandler.AddUserToTeam)
+		teams.DELETE("/:team_id/members/:user_id", can(authz.PermissionManageMembers, teamParam), teamHandler.RemoveUserFromTeam)
+		teams.PUT("/:team_id/members/:user_id/role", can(authz.PermissionManageMembers, teamParam), teamHandler.UpdateUserRole)
+		teams.GET("/:team_id/members", can(authz.PermissionRead, teamParam), teamHandler.GetTeamMembers)
 
-		teams.GET("/:team_id/boards", boardHandler.GetTeamBoards)
+		teams.GET("/:team_id/boards", can(authz.PermissionRead, teamParam), boardHandler.GetTeamBoards)
 	}
 
 	boards := protected.Group("/boards")
 	{
 		boards.POST("", boardHandler.CreateBoard)
-		boards.GET("/:id", boardHandler.GetBoard)
-		boards.PUT("/:id", boardHandler.UpdateBoard)
-		boards.DELETE("/:id", boardHandler.DeleteBoard)
+		boards.GET("/:id", can(authz.PermissionRead, boardParam), boardHandler.GetBoard)
+		boards.PUT("/:id", can(authz.PermissionWrite, boardParam), boardHandler.UpdateBoard)
+		boards.DELETE("/:id", can(authz.PermissionDeleteBoards, boardParam), boardHandler.DeleteBoard)
 
-		boards.POST("/:id/lists", boardHandler.CreateList)
-		boards.PUT("/:id/lists/reorder", boardHandler.ReorderLists)
+		boards.POST("/:id/lists", can(authz.PermissionWrite, boardParam), boardHandler.CreateList)
+		boards.PUT("/:id/lists/reorder", can(authz.PermissionWrite, boardParam), boardHandler.ReorderLists)
 	}
 
 	lists := protected.Group("/lists")
@@ -104,6 +130,26 @@ func New(cfg *config.Config, grpcClients *clients.GRPCClients, log logger.Logger
 	return r
 }
 
+// rateLimits — бюджеты маршрутов. Вход, регистрация и обновление токена
+// считаются по IP и ограничены RateLimitAuthRPM. Удаление пользователя и
+// команды запускает сагу во всех сервисах, поэтому разрешено редко.
+// Остальные маршруты получают RateLimitRPS на пользователя.
+func rateLimits(cfg *config.Config) middleware.RateLimits {
+	authBudget := middleware.Budget{Requests: cfg.RateLimitAuthRPM, Per: time.Minute}
+	sagaBudget := middleware.Budget{Requests: 5, Per: time.Minute}
+
+	return middleware.RateLimits{
+		Default: middleware.Budget{Requests: cfg.RateLimitRPS, Per: time.Second},
+		Routes: map[string]middleware.Budget{
+			"POST /api/v1/auth/register":    authBudget,
+			"POST /api/v1/auth/login":       authBudget,
+			"POST /api/v1/auth/refresh":     authBudget,
+			"DELETE /api/v1/users/:id":      sagaBudget,
+			"DELETE /api/v1/teams/:team_id": sagaBudget,
+		},
+	}
+}
+
 func healthCheck(c *gin.Context) {
 	utils.SuccessResponse(c, models.HealthResponse{
 		Status:    "healthy",
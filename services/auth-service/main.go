@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"shiroyama/events"
 	"syscall"
 )
 
@@ -34,7 +35,7 @@ func main() {
 	}
 
 	go func() {
-		if err := consumer.Start(ctx, []string{"user-deletion-saga"}); err != nil {
+		if err := consumer.Start(ctx, []string{events.AuthCommandsTopic}); err != nil {
 			log.Error("Kafka consumer stopped with error", "error", err)
 			cancel()
 		}
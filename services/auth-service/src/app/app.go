@@ -3,8 +3,10 @@ package app
 import (
 	"authservice/src/config"
 	"authservice/src/handler"
+	"authservice/src/keys"
 	"authservice/src/repository"
 	"authservice/src/service"
+	"context"
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
@@ -12,7 +14,10 @@ import (
 	"gorm.io/gorm"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
+	"shiroyama/authz/sessionrpc"
+	"time"
 )
 
 type App struct {
@@ -21,6 +26,7 @@ type App struct {
 	log  *slog.Logger
 	conf *config.Config
 	gRPC *grpc.Server
+	jwks *http.Server
 	conn *grpc.ClientConn
 }
 
@@ -30,14 +36,17 @@ func New(db *gorm.DB, rdb *redis.Client, cfg *config.Config, logger *slog.Logger
 		panic(err)
 	}
 
-	gRPCServer := grpc.NewServer()
+	// RPC сессий передают сообщения в JSON, см. sessionrpc.
+	gRPCServer := grpc.NewServer(sessionrpc.ServerCodec())
 
 	authRepository, err := repository.NewAuthRepository(db, rdb, cfg, logger)
 	if err != nil {
 		panic(err)
 	}
 
-	authService := service.NewAuthService(authRepository, logger, cfg, userConnection)
+	keySet := keys.MustLoad(cfg.JWT, logger)
+
+	authService := service.NewAuthService(authRepository, logger, cfg, keySet, userConnection)
 	handler.RegisterServer(gRPCServer, authService, logger)
 
 	return &App{
@@ -46,6 +55,7 @@ func New(db *gorm.DB, rdb *redis.Client, cfg *config.Config, logger *slog.Logger
 		log:  logger,
 		conf: cfg,
 		gRPC: gRPCServer,
+		jwks: handler.NewJWKSServer(cfg.JWT.JWKSPort, keySet, logger),
 		conn: userConnection,
 	}
 }
@@ -69,6 +79,13 @@ func (app *App) Run() error {
 		return err
 	}
 
+	go func() {
+		log.Info("Starting JWKS server", slog.String("addr", app.jwks.Addr))
+		if err := app.jwks.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("JWKS server stopped", "error", err)
+		}
+	}()
+
 	log.Info("Starting gRPC server", listener.Addr().String())
 
 	if err = app.gRPC.Serve(listener); err != nil {
@@ -91,5 +108,11 @@ func (app *App) Stop() {
 	if err != nil {
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := app.jwks.Shutdown(ctx); err != nil {
+		log.Error("Failed to stop JWKS server", "error", err)
+	}
+
 	app.gRPC.GracefulStop()
 }
@@ -5,4 +5,12 @@ import "time"
 type JWTConfig struct {
 	AccessTokenTTL  time.Duration `yaml:"accessTTL"`
 	RefreshTokenTTL int           `yaml:"refreshTTL"`
+	// Каталог с ключами подписи: <kid>.pem, закрытый ключ Ed25519 в PKCS#8.
+	// Пустой каталог — ключ генерируется при старте, только для разработки
+	KeysDir string `yaml:"keysDir" env:"JWT_KEYS_DIR"`
+	// kid ключа, которым подписываются новые токены. Остальные ключи из
+	// каталога только публикуются в JWKS, пока выданные ими токены живы
+	ActiveKeyID string `yaml:"activeKeyID" env:"JWT_ACTIVE_KEY_ID"`
+	// Порт HTTP-сервера с /.well-known/jwks.json
+	JWKSPort int `yaml:"jwksPort" env:"JWKS_PORT" env-default:"8081"`
 }
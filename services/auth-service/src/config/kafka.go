@@ -1,5 +1,28 @@
 package config
 
+import "time"
+
 type KafkaConfig struct {
-	Brokers []string `yaml:"brokers"`
+	Brokers    []string         `yaml:"brokers"`
+	Inbox      InboxConfig      `yaml:"inbox"`
+	DeadLetter DeadLetterConfig `yaml:"deadLetter"`
+	Outbox     OutboxConfig     `yaml:"outbox"`
+}
+
+type InboxConfig struct {
+	Retention       time.Duration `yaml:"retention" env-default:"168h"`
+	CleanupInterval time.Duration `yaml:"cleanupInterval" env-default:"1h"`
+}
+
+type OutboxConfig struct {
+	PollInterval    time.Duration `yaml:"pollInterval" env-default:"1s"`
+	BatchSize       int           `yaml:"batchSize" env-default:"100"`
+	Retention       time.Duration `yaml:"retention" env-default:"72h"`
+	CleanupInterval time.Duration `yaml:"cleanupInterval" env-default:"1h"`
+}
+
+type DeadLetterConfig struct {
+	// Сколько раз обработать сообщение, прежде чем отправить его в <topic>.dlq
+	MaxAttempts int           `yaml:"maxAttempts" env-default:"3"`
+	Backoff     time.Duration `yaml:"backoff" env-default:"500ms"`
 }
@@ -3,7 +3,8 @@ package config
 import "time"
 
 type RedisConfig struct {
-	Port int           `yaml:"port"`
-	Host string        `yaml:"host"`
-	TTL  time.Duration `yaml:"ttl"`
+	Port     int           `yaml:"port"`
+	Host     string        `yaml:"host"`
+	Password string        `yaml:"password" env:"REDIS_PASSWORD"`
+	TTL      time.Duration `yaml:"ttl"`
 }
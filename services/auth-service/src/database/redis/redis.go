@@ -2,6 +2,7 @@ package redis
 
 import (
 	"authservice/src/config"
+	"fmt"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -16,7 +17,8 @@ func MustConnect(cfg *config.Config) *redis.Client {
 
 func Connect(cfg *config.Config) (*redis.Client, error) {
 	rdb := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379", // or "redis:6379" if running from another service in Docker
+		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
 	})
 
 	return rdb, nil
@@ -3,8 +3,9 @@ package dto
 import "github.com/go-playground/validator"
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email    string     `json:"email" validate:"required,email"`
+	Password string     `json:"password" validate:"required"`
+	Client   ClientInfo `json:"-"`
 }
 
 type LoginResponse struct {
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// ClientInfo описывает устройство, с которого пришёл запрос. Шлюз передаёт
+// его в метаданных вызова.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type RefreshResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
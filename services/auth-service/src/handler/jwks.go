@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"authservice/src/keys"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// NewJWKSServer отдаёт открытые ключи подписи токенов. По ним шлюз и
+// сервисы проверяют токены, не зная закрытого ключа.
+func NewJWKSServer(port int, keySet *keys.KeySet, logger *slog.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.JWKS"
+
+		body, err := keySet.JWKS()
+		if err != nil {
+			logger.Error("Failed to encode JWKS", slog.String("op", op), "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_, _ = w.Write(body)
+	})
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
@@ -8,13 +8,17 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log/slog"
+	"shiroyama/authz/sessionrpc"
 )
 
 type AuthService interface {
 	Register(ctx context.Context, request dto.RegisterRequest) (*dto.RegisterResponse, error)
 	Login(ctx context.Context, request dto.LoginRequest) (*dto.LoginResponse, error)
 	ValidateToken(ctx context.Context, token string) (bool, error)
-	RefreshToken(ctx context.Context, refreshToken string) (string, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*dto.RefreshResponse, error)
+	Logout(ctx context.Context, accessToken string) error
+	ListSessions(ctx context.Context, accessToken string) ([]dto.Session, error)
+	RevokeSession(ctx context.Context, accessToken, sessionID string) error
 }
 
 type server struct {
@@ -30,6 +34,7 @@ func RegisterServer(gRPC *grpc.Server, authService AuthService, logger *slog.Log
 	}
 
 	auth.RegisterAuthServiceServer(gRPC, &authServer)
+	sessionrpc.RegisterServer(gRPC, &authServer)
 }
 
 func (s *server) Register(ctx context.Context, in *auth.RegisterRequest) (*auth.RegisterResponse, error) {
@@ -70,6 +75,7 @@ func (s *server) Login(ctx context.Context, in *auth.LoginRequest) (*auth.LoginR
 	loginRequest := dto.LoginRequest{
 		Email:    in.GetEmail(),
 		Password: in.GetPassword(),
+		Client:   clientInfo(ctx),
 	}
 
 	if err := loginRequest.Validate(); err != nil {
@@ -112,14 +118,31 @@ func (s *server) RefreshToken(ctx context.Context, in *auth.RefreshTokenRequest)
 
 	log := s.logger.With(slog.String("op", op))
 
-	accessToken, err := s.authService.RefreshToken(ctx, in.RefreshToken)
+	tokens, err := s.authService.RefreshToken(ctx, in.RefreshToken)
 	if err != nil {
 		log.Debug("refresh token failed", "error", err)
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
 	}
 
 	return &auth.RefreshTokenResponse{
-		RefreshToken: in.RefreshToken,
-		AccessToken:  accessToken,
+		RefreshToken: tokens.RefreshToken,
+		AccessToken:  tokens.AccessToken,
 	}, nil
 }
+
+func (s *server) Logout(ctx context.Context, in *auth.LogoutRequest) (*auth.LogoutResponse, error) {
+	const op = "grpc.Logout"
+
+	log := s.logger.With(slog.String("op", op))
+
+	if in.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	if err := s.authService.Logout(ctx, in.GetToken()); err != nil {
+		log.Debug("logout failed", "error", err)
+		return nil, err
+	}
+
+	return &auth.LogoutResponse{}, nil
+}
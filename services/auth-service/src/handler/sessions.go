@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"authservice/src/dto"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"shiroyama/authz/sessionrpc"
+	"strings"
+)
+
+// Метаданные, в которых шлюз передаёт устройство пользователя.
+const (
+	clientUserAgentKey = "x-client-user-agent"
+	clientIPKey        = "x-client-ip"
+)
+
+func (s *server) ListSessions(ctx context.Context, _ *sessionrpc.ListSessionsRequest) (*sessionrpc.ListSessionsResponse, error) {
+	const op = "grpc.ListSessions"
+
+	log := s.logger.With(slog.String("op", op))
+
+	token, ok := accessToken(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "token is required")
+	}
+
+	sessions, err := s.authService.ListSessions(ctx, token)
+	if err != nil {
+		log.Debug("list sessions failed", "error", err)
+		return nil, err
+	}
+
+	response := &sessionrpc.ListSessionsResponse{Sessions: make([]sessionrpc.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, sessionrpc.Session{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.Current,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *server) RevokeSession(ctx context.Context, in *sessionrpc.RevokeSessionRequest) (*sessionrpc.RevokeSessionResponse, error) {
+	const op = "grpc.RevokeSession"
+
+	log := s.logger.With(slog.String("op", op))
+
+	token, ok := accessToken(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "token is required")
+	}
+
+	if in.SessionID == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	if err := s.authService.RevokeSession(ctx, token, in.SessionID); err != nil {
+		log.Debug("revoke session failed", "error", err)
+		return nil, err
+	}
+
+	return &sessionrpc.RevokeSessionResponse{}, nil
+}
+
+// accessToken достаёт access token, который шлюз кладёт в метаданные вызова.
+func accessToken(ctx context.Context) (string, bool) {
+	token := strings.TrimPrefix(firstMetadataValue(ctx, "authorization"), "Bearer ")
+	return token, token != ""
+}
+
+func clientInfo(ctx context.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		UserAgent: firstMetadataValue(ctx, clientUserAgentKey),
+		IP:        firstMetadataValue(ctx, clientIPKey),
+	}
+}
+
+func firstMetadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
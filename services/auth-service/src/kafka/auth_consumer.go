@@ -2,74 +2,45 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
-	"github.com/IBM/sarama"
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"log/slog"
-	"time"
+	"shiroyama/events"
 )
 
 type AuthRepository interface {
-	BeginTx(ctx context.Context) (*gorm.DB, error)
 	SoftDeleteUserTx(ctx context.Context, tx *gorm.DB, userID string) error
 	RestoreUserTx(ctx context.Context, tx *gorm.DB, userID string) error
 }
 
 type AuthConsumer struct {
-	userRepo      AuthRepository
-	kafkaProducer *Producer
-	logger        *slog.Logger
+	userRepo AuthRepository
+	inbox    *Inbox
+	outbox   *Outbox
+	logger   *slog.Logger
 }
 
-func NewAuthConsumer(repo AuthRepository, producer *Producer, logger *slog.Logger) *AuthConsumer {
+func NewAuthConsumer(repo AuthRepository, inbox *Inbox, outbox *Outbox, logger *slog.Logger) *AuthConsumer {
 	return &AuthConsumer{
-		userRepo:      repo,
-		kafkaProducer: producer,
-		logger:        logger,
+		userRepo: repo,
+		inbox:    inbox,
+		outbox:   outbox,
+		logger:   logger,
 	}
 }
 
-func (uc *AuthConsumer) Setup(_ sarama.ConsumerGroupSession) error {
-	uc.logger.Info("Consumer group session setup")
-	return nil
-}
+func (uc *AuthConsumer) HandleEvent(ctx context.Context, event events.Event) error {
+	ctx = events.ContextWithTrace(ctx, event.Trace())
 
-func (uc *AuthConsumer) Cleanup(_ sarama.ConsumerGroupSession) error {
-	uc.logger.Info("Consumer group session cleanup")
-	return nil
-}
-
-func (uc *AuthConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for msg := range claim.Messages() {
-		uc.logger.Info("Consumed message", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
-
-		var event Event
-		if err := json.Unmarshal(msg.Value, &event); err != nil {
-			uc.logger.Error("Failed to unmarshal event", "error", err)
-			// можно skip или retry
-			session.MarkMessage(msg, "")
-			continue
-		}
-
-		err := uc.HandleEvent(session.Context(), event)
-		if err != nil {
-			uc.logger.Error("Failed to handle event", "error", err)
-			// Тут можно не отмечать сообщение как обработанное, чтобы попытаться заново
-			// или сделать dead-letter, зависит от логики
-		} else {
-			session.MarkMessage(msg, "")
-		}
-	}
-
-	return nil
-}
+	uc.logger.Info("Processing event",
+		"event_id", event.ID,
+		"event_type", event.Type,
+		"saga_id", event.SagaID,
+		"trace", event.Trace())
 
-func (uc *AuthConsumer) HandleEvent(ctx context.Context, event Event) error {
 	switch event.Type {
-	case UserDeletionRequested:
+	case events.AuthUserDeleteRequested:
 		return uc.handleUserDeletion(ctx, event)
-	case UserDeletionRollback:
+	case events.AuthUserDeleteRollback:
 		return uc.handleUserDeletionRollback(ctx, event)
 	default:
 		uc.logger.Warn("Unhandled event type", "type", event.Type)
@@ -77,88 +48,123 @@ func (uc *AuthConsumer) HandleEvent(ctx context.Context, event Event) error {
 	}
 }
 
-func (uc *AuthConsumer) handleUserDeletion(ctx context.Context, event Event) error {
-	tx, err := uc.userRepo.BeginTx(ctx)
-	if err != nil {
-		return err
-	}
-
-	if err := uc.userRepo.SoftDeleteUserTx(ctx, tx, event.UserID); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			uc.logger.Error("Failed to rollback transaction", "error", rbErr)
+func (uc *AuthConsumer) handleUserDeletion(ctx context.Context, event events.Event) error {
+	var (
+		fresh     bool
+		deleteErr error
+	)
+
+	err := uc.outbox.InTx(ctx, func(tx *gorm.DB) error {
+		var err error
+		fresh, err = uc.inbox.MarkProcessedTx(ctx, tx, event.ID)
+		if err != nil || !fresh {
+			return err
 		}
 
-		rollbackEvent := Event{
-			ID:        generateUUID(),
-			Type:      UserDeletionRollback,
-			UserID:    event.UserID,
-			Timestamp: time.Now(),
-			SagaID:    event.SagaID,
-			Data: map[string]interface{}{
-				"error": err.Error(),
-			},
+		if deleteErr = uc.userRepo.SoftDeleteUserTx(ctx, tx, event.UserID); deleteErr != nil {
+			return deleteErr
 		}
-		_ = uc.kafkaProducer.PublishEvent("user-deletion-saga", rollbackEvent)
-
-		return err
-	}
 
-	if err := tx.Commit(); err != nil {
-		rollbackEvent := Event{
-			ID:        generateUUID(),
-			Type:      UserDeletionRollback,
-			UserID:    event.UserID,
-			Timestamp: time.Now(),
-			SagaID:    event.SagaID,
-			Data: map[string]interface{}{
-				"error": err,
-			},
-		}
-		_ = uc.kafkaProducer.PublishEvent("user-deletion-saga", rollbackEvent)
+		deletedEvent := events.New(events.AuthUserDeleted, event.UserID, event.SagaID, nil)
 
-		return err.Error
-	}
+		// Событие фиксируется вместе с удалением — relay отправит его после коммита
+		return uc.outbox.EnqueueTx(ctx, tx, events.AuthEventsTopic, deletedEvent)
+	})
+	if deleteErr != nil {
+		uc.logger.Error("Failed to delete user", "user_id", event.UserID, "saga_id", event.SagaID, "error", deleteErr)
 
-	completedEvent := Event{
-		ID:        generateUUID(),
-		Type:      UserDeletionCompleted,
-		UserID:    event.UserID,
-		Timestamp: time.Now(),
-		SagaID:    event.SagaID,
+		// Отказ доставлен саге — повторять событие не нужно
+		return uc.enqueueFailure(ctx, event, deleteErr)
 	}
-
-	if err := uc.kafkaProducer.PublishEvent("user-deletion-saga", completedEvent); err != nil {
+	if err != nil {
+		uc.logger.Error("Failed to commit user deletion", "user_id", event.UserID, "saga_id", event.SagaID, "error", err)
 		return err
 	}
+	if !fresh {
+		uc.logger.Info("Skipping already processed event", "event_id", event.ID, "saga_id", event.SagaID)
+		return nil
+	}
 
 	uc.logger.Info("User deleted successfully", "user_id", event.UserID, "saga_id", event.SagaID)
 	return nil
 }
 
-func (uc *AuthConsumer) handleUserDeletionRollback(ctx context.Context, event Event) error {
-	tx, err := uc.userRepo.BeginTx(ctx)
+func (uc *AuthConsumer) handleUserDeletionRollback(ctx context.Context, event events.Event) error {
+	var (
+		fresh      bool
+		restoreErr error
+	)
+
+	err := uc.outbox.InTx(ctx, func(tx *gorm.DB) error {
+		var err error
+		fresh, err = uc.inbox.MarkProcessedTx(ctx, tx, event.ID)
+		if err != nil || !fresh {
+			return err
+		}
+
+		if restoreErr = uc.userRepo.RestoreUserTx(ctx, tx, event.UserID); restoreErr != nil {
+			return restoreErr
+		}
+
+		restoredEvent := events.New(events.AuthUserDeleteRollbackCompleted, event.UserID, event.SagaID, nil)
+
+		// Подтверждение отката фиксируется вместе с восстановлением пользователя
+		return uc.outbox.EnqueueTx(ctx, tx, events.AuthEventsTopic, restoredEvent)
+	})
+	if restoreErr != nil {
+		uc.logger.Error("Failed to restore user during rollback", "user_id", event.UserID, "error", restoreErr)
+		return uc.enqueueRollbackFailure(ctx, event, restoreErr)
+	}
 	if err != nil {
-		uc.logger.Error("Failed to begin transaction for rollback", "user_id", event.UserID, "error", err)
+		uc.logger.Error("Failed to commit transaction during rollback handling", "user_id", event.UserID, "error", err)
 		return err
 	}
+	if !fresh {
+		uc.logger.Info("Skipping already processed event", "event_id", event.ID, "saga_id", event.SagaID)
+		return nil
+	}
 
-	if err := uc.userRepo.RestoreUserTx(ctx, tx, event.UserID); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			uc.logger.Error("Failed to rollback transaction during rollback handling", "user_id", event.UserID, "error", rbErr)
-		}
-		uc.logger.Error("Failed to restore user during rollback", "user_id", event.UserID, "error", err)
+	uc.logger.Info("User restoration (rollback) completed successfully", "user_id", event.UserID, "saga_id", event.SagaID)
+	return nil
+}
+
+// enqueueFailure сообщает оркестратору, что шаг не выполнен: он сам решит,
+// повторить шаг или откатить сагу, поэтому сообщение считается обработанным.
+func (uc *AuthConsumer) enqueueFailure(ctx context.Context, event events.Event, cause error) error {
+	failedEvent := events.New(events.AuthUserDeleteFailed, event.UserID, event.SagaID, map[string]interface{}{
+		"error": cause.Error(),
+	})
+
+	if err := uc.enqueueReply(ctx, event, failedEvent); err != nil {
+		uc.logger.Error("Failed to enqueue failure event", "user_id", event.UserID, "saga_id", event.SagaID, "error", err)
 		return err
 	}
+	return nil
+}
 
-	if err := tx.Commit(); err != nil {
-		uc.logger.Error("Failed to commit transaction during rollback handling", "user_id", event.UserID, "error", err)
-		return err.Error
-	}
+// enqueueRollbackFailure сообщает оркестратору, что откат не удался. Повторять
+// его будет оркестратор, поэтому сообщение считается обработанным.
+func (uc *AuthConsumer) enqueueRollbackFailure(ctx context.Context, event events.Event, cause error) error {
+	failedEvent := events.New(events.AuthUserDeleteRollbackFailed, event.UserID, event.SagaID, map[string]interface{}{
+		"error": cause.Error(),
+	})
 
-	uc.logger.Info("User restoration (rollback) completed successfully", "user_id", event.UserID, "saga_id", event.SagaID)
+	if err := uc.enqueueReply(ctx, event, failedEvent); err != nil {
+		uc.logger.Error("Failed to enqueue rollback failure event", "user_id", event.UserID, "saga_id", event.SagaID, "error", err)
+		return err
+	}
 	return nil
 }
 
-func generateUUID() string {
-	return uuid.New().String()
+// enqueueReply пишет ответ об отказе в одной транзакции с отметкой inbox:
+// изменения откачены, поэтому повторная доставка иначе отправила бы
+// оркестратору второй ответ.
+func (uc *AuthConsumer) enqueueReply(ctx context.Context, event events.Event, reply events.Event) error {
+	return uc.outbox.InTx(ctx, func(tx *gorm.DB) error {
+		fresh, err := uc.inbox.MarkProcessedTx(ctx, tx, event.ID)
+		if err != nil || !fresh {
+			return err
+		}
+		return uc.outbox.EnqueueTx(ctx, tx, events.AuthEventsTopic, reply)
+	})
 }
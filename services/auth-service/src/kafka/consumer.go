@@ -4,17 +4,25 @@ import (
 	"authservice/src/config"
 	authRepo "authservice/src/repository"
 	"context"
+	"fmt"
 	"github.com/IBM/sarama"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"log/slog"
+	"shiroyama/events"
+	"shiroyama/events/bus"
+	"shiroyama/messaging/dlq"
 	"sync"
 	"time"
 )
 
 type Consumer struct {
 	consumerGroup sarama.ConsumerGroup
-	handler       *AuthConsumer
+	handler       bus.Handler
+	dlq           *dlq.Queue
+	inbox         *Inbox
+	outbox        *Outbox
+	cfg           config.KafkaConfig
 	logger        *slog.Logger
 }
 
@@ -29,7 +37,21 @@ func NewConsumer(ctx context.Context, cfg *config.Config, logger *slog.Logger, r
 		return nil, err
 	}
 
-	consumerHandler := NewAuthConsumer(authRepository, producer, logger)
+	inbox, err := NewInbox(db, "auth-service", cfg.Kafka.Inbox.Retention, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	outbox, err := NewOutbox(db, "auth-service", producer, cfg.Kafka.Outbox, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters := dlq.New(producer, "auth-service", dlq.Config{
+		MaxAttempts: cfg.Kafka.DeadLetter.MaxAttempts,
+		Backoff:     cfg.Kafka.DeadLetter.Backoff,
+	}, logger)
+	consumerHandler := NewAuthConsumer(authRepository, inbox, outbox, logger)
 
 	configSarama := sarama.NewConfig()
 	configSarama.Version = sarama.V2_8_0_0
@@ -44,18 +66,67 @@ func NewConsumer(ctx context.Context, cfg *config.Config, logger *slog.Logger, r
 	return &Consumer{
 		consumerGroup: consumerGroup,
 		handler:       consumerHandler,
+		dlq:           deadLetters,
+		inbox:         inbox,
+		outbox:        outbox,
+		cfg:           cfg.Kafka,
 		logger:        logger,
 	}, nil
 }
 
+func (c *Consumer) Setup(_ sarama.ConsumerGroupSession) error {
+	c.logger.Info("Consumer group session setup")
+	return nil
+}
+
+func (c *Consumer) Cleanup(_ sarama.ConsumerGroupSession) error {
+	c.logger.Info("Consumer group session cleanup")
+	return nil
+}
+
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		c.logger.Info("Consumed message", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+
+		if err := c.dlq.Process(session.Context(), msg, c.handleMessage); err != nil {
+			// Сообщение не обработано и не попало в DLQ — offset не коммитим
+			c.logger.Error("Failed to handle event", "offset", msg.Offset, "error", err)
+			return err
+		}
+
+		session.MarkMessage(msg, "")
+	}
+
+	return nil
+}
+
+func (c *Consumer) handleMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	event, err := events.Unmarshal(msg.Value)
+	if err != nil {
+		return dlq.Permanent(fmt.Errorf("failed to unmarshal event: %w", err))
+	}
+
+	return c.handler.HandleEvent(ctx, event)
+}
+
 func (c *Consumer) Start(ctx context.Context, topics []string) error {
 	wg := &sync.WaitGroup{}
-	wg.Add(1)
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		c.inbox.StartCleanup(ctx, c.cfg.Inbox.CleanupInterval)
+	}()
+
+	go func() {
+		defer wg.Done()
+		c.outbox.StartRelay(ctx)
+	}()
 
 	go func() {
 		defer wg.Done()
 		for {
-			if err := c.consumerGroup.Consume(ctx, topics, c.handler); err != nil {
+			if err := c.consumerGroup.Consume(ctx, topics, c); err != nil {
 				c.logger.Error("Error from consumer", "error", err)
 				time.Sleep(time.Second)
 			}
@@ -0,0 +1,21 @@
+package kafka
+
+import (
+	"gorm.io/gorm"
+	"log/slog"
+	"shiroyama/messaging/gormstore"
+	"shiroyama/messaging/inbox"
+	"time"
+)
+
+// Inbox запоминает ID обработанных событий, чтобы повторная доставка из
+// Kafka не выполняла удаление или восстановление пользователя второй раз.
+type Inbox = inbox.Inbox[*gorm.DB]
+
+func NewInbox(db *gorm.DB, consumer string, retention time.Duration, logger *slog.Logger) (*Inbox, error) {
+	if err := db.AutoMigrate(&gormstore.ProcessedEvent{}); err != nil {
+		return nil, err
+	}
+
+	return inbox.New(gormstore.NewInbox(db), consumer, retention, logger), nil
+}
@@ -0,0 +1,26 @@
+package kafka
+
+import (
+	"authservice/src/config"
+	"gorm.io/gorm"
+	"log/slog"
+	"shiroyama/messaging/gormstore"
+	"shiroyama/messaging/outbox"
+)
+
+// Outbox сохраняет исходящие события в таблицу outbox_events в той же транзакции,
+// что и изменения данных, а relay публикует их в Kafka.
+type Outbox = outbox.Outbox[*gorm.DB]
+
+func NewOutbox(db *gorm.DB, source string, producer *Producer, cfg config.OutboxConfig, logger *slog.Logger) (*Outbox, error) {
+	if err := db.AutoMigrate(&gormstore.OutboxEvent{}); err != nil {
+		return nil, err
+	}
+
+	return outbox.New(gormstore.NewOutbox(db), source, producer, outbox.Config{
+		PollInterval:    cfg.PollInterval,
+		BatchSize:       cfg.BatchSize,
+		Retention:       cfg.Retention,
+		CleanupInterval: cfg.CleanupInterval,
+	}, logger), nil
+}
@@ -0,0 +1,198 @@
+package keys
+
+import (
+	"authservice/src/config"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Ротация ключа: положить новый <kid>.pem в каталог и перезапустить сервис —
+// шлюз и сервисы получат его из JWKS заранее. Затем сделать его активным, а
+// старый удалить, когда истекут выданные им refresh token.
+
+// Значения claim typ. Refresh token подписан тем же ключом, что и access
+// token, поэтому без проверки типа его можно предъявить вместо access token.
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+var ErrWrongTokenType = errors.New("wrong token type")
+
+// TypedClaims — claims, в которых указан тип токена.
+type TypedClaims interface {
+	jwt.Claims
+	TokenType() string
+}
+
+type key struct {
+	id      string
+	private ed25519.PrivateKey
+}
+
+// KeySet подписывает токены активным ключом и проверяет их любым ключом
+// набора по kid из заголовка.
+type KeySet struct {
+	active key
+	keys   map[string]key
+}
+
+func MustLoad(cfg config.JWTConfig, log *slog.Logger) *KeySet {
+	set, err := Load(cfg, log)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+func Load(cfg config.JWTConfig, log *slog.Logger) (*KeySet, error) {
+	const op = "keys.Load"
+
+	if cfg.KeysDir == "" {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		generated := key{id: uuid.NewString(), private: private}
+		log.Warn("JWT keys dir is not set, using a generated signing key; tokens will not survive a restart", "kid", generated.id)
+
+		return &KeySet{active: generated, keys: map[string]key{generated.id: generated}}, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cfg.KeysDir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	set := &KeySet{keys: make(map[string]key, len(paths))}
+	for _, path := range paths {
+		private, err := readPrivateKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, path, err)
+		}
+
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		set.keys[id] = key{id: id, private: private}
+	}
+
+	active, ok := set.keys[cfg.ActiveKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%s: active key %q not found in %s", op, cfg.ActiveKeyID, cfg.KeysDir)
+	}
+	set.active = active
+
+	log.Info("JWT keys loaded", "active_kid", active.id, "keys", len(set.keys))
+
+	return set, nil
+}
+
+func readPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T, expected Ed25519", parsed)
+	}
+
+	return private, nil
+}
+
+// Sign подписывает claims активным ключом и указывает его kid в заголовке.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = s.active.id
+
+	return token.SignedString(s.active.private)
+}
+
+// Keyfunc выбирает ключ проверки по kid. Используется вместе с
+// jwt.WithValidMethods, чтобы нельзя было подменить алгоритм.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", id)
+	}
+
+	return k.private.Public(), nil
+}
+
+// Parse проверяет подпись токена и что он типа tokenType. Срок действия
+// проверяется, если опции не говорят иного.
+func (s *KeySet) Parse(token string, claims TypedClaims, tokenType string, opts ...jwt.ParserOption) error {
+	opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))
+
+	if _, err := jwt.ParseWithClaims(token, claims, s.Keyfunc, opts...); err != nil {
+		return err
+	}
+
+	if claims.TokenType() != tokenType {
+		return ErrWrongTokenType
+	}
+
+	return nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// JWKS возвращает открытые ключи набора в формате RFC 7517.
+func (s *KeySet) JWKS() ([]byte, error) {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{Keys: make([]jwk, 0, len(ids))}
+
+	for _, id := range ids {
+		public := s.keys[id].private.Public().(ed25519.PublicKey)
+		set.Keys = append(set.Keys, jwk{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+			Kid: id,
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Use: "sig",
+		})
+	}
+
+	return json.Marshal(set)
+}
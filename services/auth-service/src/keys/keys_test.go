@@ -0,0 +1,78 @@
+package keys
+
+import (
+	"authservice/src/config"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type testClaims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (c *testClaims) TokenType() string {
+	return c.Type
+}
+
+func newTestKeySet(t *testing.T) *KeySet {
+	t.Helper()
+
+	set, err := Load(config.JWTConfig{}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("load keys: %v", err)
+	}
+	return set
+}
+
+func signTestToken(t *testing.T, set *KeySet, tokenType string) string {
+	t.Helper()
+
+	token, err := set.Sign(&testClaims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestParseChecksTokenType(t *testing.T) {
+	set := newTestKeySet(t)
+
+	access := signTestToken(t, set, AccessToken)
+	refresh := signTestToken(t, set, RefreshToken)
+	untyped := signTestToken(t, set, "")
+
+	if err := set.Parse(access, &testClaims{}, AccessToken); err != nil {
+		t.Fatalf("access token as access: %v", err)
+	}
+	if err := set.Parse(refresh, &testClaims{}, RefreshToken); err != nil {
+		t.Fatalf("refresh token as refresh: %v", err)
+	}
+	if err := set.Parse(refresh, &testClaims{}, AccessToken); !errors.Is(err, ErrWrongTokenType) {
+		t.Fatalf("refresh token as access: expected ErrWrongTokenType, got %v", err)
+	}
+	if err := set.Parse(access, &testClaims{}, RefreshToken); !errors.Is(err, ErrWrongTokenType) {
+		t.Fatalf("access token as refresh: expected ErrWrongTokenType, got %v", err)
+	}
+	if err := set.Parse(untyped, &testClaims{}, AccessToken); !errors.Is(err, ErrWrongTokenType) {
+		t.Fatalf("token without type: expected ErrWrongTokenType, got %v", err)
+	}
+}
+
+func TestParseRejectsForeignKey(t *testing.T) {
+	set := newTestKeySet(t)
+	other := newTestKeySet(t)
+
+	if err := set.Parse(signTestToken(t, other, AccessToken), &testClaims{}, AccessToken); err == nil {
+		t.Fatal("token signed by another key set: expected error")
+	}
+}
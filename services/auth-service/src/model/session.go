@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Session — вход пользователя с одного устройства. Refresh token хранится
+// только хэшем и меняется при каждом обновлении; все токены сессии
+// образуют одно семейство, которое отзывается целиком.
+type Session struct {
+	ID        string `gorm:"primaryKey;size:36"`
+	UserID    uint   `gorm:"not null;index"`
+	TokenHash string `gorm:"size:64;not null"`
+	UserAgent string `gorm:"size:512;not null;default:''"`
+	IP        string `gorm:"size:64;not null;default:''"`
+	// Последний выданный access token: при отзыве сессии он попадает в denylist
+	AccessTokenID        string    `gorm:"size:36;not null;default:''"`
+	AccessTokenExpiresAt time.Time `gorm:"not null"`
+	CreatedAt            time.Time `gorm:"not null"`
+	LastUsedAt           time.Time `gorm:"not null"`
+	ExpiresAt            time.Time `gorm:"not null"`
+	RevokedAt            *time.Time
+}
@@ -37,7 +37,6 @@ type User struct {
 	gorm.Model
 	Email        string `gorm:"unique"`
 	Password     []byte
-	RefreshToken string
 	Role         role `gorm:"type:user_role;default:'Regular';not null"`
 	UserID       string `gorm:"unique"`
 	IsDeleted    bool
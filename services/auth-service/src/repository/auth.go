@@ -4,10 +4,18 @@ import (
 	"authservice/src/config"
 	"authservice/src/model"
 	"context"
+	"errors"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"log/slog"
-	"strconv"
+	"time"
+)
+
+// Ключи отозванных access token и сессий. Шлюз проверяет те же ключи,
+// поэтому префиксы менять только вместе с ним.
+const (
+	deniedTokenKeyPrefix   = "denylist:"
+	deniedSessionKeyPrefix = "denylist:session:"
 )
 
 type AuthRepository struct {
@@ -22,7 +30,7 @@ func NewAuthRepository(db *gorm.DB, rdb *redis.Client, config *config.Config, lo
 	if err != nil {
 		log.Warn("some error happened while creating role type", err)
 	}
-	err = db.AutoMigrate(&model.User{})
+	err = db.AutoMigrate(&model.User{}, &model.Session{})
 	if err != nil {
 		return nil, err
 	}
@@ -81,26 +89,97 @@ func (repo *AuthRepository) GetUserByID(ctx context.Context, id uint) (*model.Us
 	return &user, nil
 }
 
-func (repo *AuthRepository) GetRefreshToken(ctx context.Context, userId string) (string, error) {
-	if err := repo.rdb.Get(ctx, userId).Err(); err != nil {
-		var user model.User
-		if err := repo.db.WithContext(ctx).First(&user, "id = ?", userId).Error; err != nil {
-			return "", err
+func (repo *AuthRepository) CreateSession(ctx context.Context, session *model.Session) error {
+	return repo.db.WithContext(ctx).Create(session).Error
+}
+
+// GetSession возвращает сессию по ID или nil, если её нет.
+func (repo *AuthRepository) GetSession(ctx context.Context, id string) (*model.Session, error) {
+	var session model.Session
+
+	if err := repo.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
 		}
-		return user.RefreshToken, nil
+		return nil, err
 	}
 
-	return repo.rdb.Get(ctx, userId).Result()
+	return &session, nil
 }
 
-func (repo *AuthRepository) UpdateRefreshToken(ctx context.Context, user *model.User, token string) error {
-	user.RefreshToken = token
-	if err := repo.db.WithContext(ctx).Save(user).Error; err != nil {
-		return err
+// ListActiveSessions возвращает неотозванные и неистёкшие сессии пользователя.
+func (repo *AuthRepository) ListActiveSessions(ctx context.Context, userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+
+	err := repo.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
 	}
-	repo.rdb.Set(ctx, strconv.Itoa(int(user.ID)), token, repo.config.Redis.TTL)
 
-	return nil
+	return sessions, nil
+}
+
+// RotateSession заменяет refresh token сессии, только если в ней всё ещё
+// хранится oldHash. false значит, что токен уже обменяли: это повторное
+// использование.
+func (repo *AuthRepository) RotateSession(ctx context.Context, id, oldHash string, next *model.Session) (bool, error) {
+	result := repo.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]any{
+			"token_hash":              next.TokenHash,
+			"access_token_id":         next.AccessTokenID,
+			"access_token_expires_at": next.AccessTokenExpiresAt,
+			"last_used_at":            next.LastUsedAt,
+			"expires_at":              next.ExpiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (repo *AuthRepository) RevokeSession(ctx context.Context, id string) error {
+	return repo.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DenyToken вносит jti в denylist на ttl — оставшееся время жизни токена.
+func (repo *AuthRepository) DenyToken(ctx context.Context, jti string, ttl time.Duration) error {
+	return repo.rdb.Set(ctx, deniedTokenKeyPrefix+jti, 1, ttl).Err()
+}
+
+// DenySession отзывает все access token сессии, в том числе выданные
+// параллельным обменом refresh token, о которых база уже не знает. ttl — не
+// меньше времени жизни access token.
+func (repo *AuthRepository) DenySession(ctx context.Context, id string, ttl time.Duration) error {
+	return repo.rdb.Set(ctx, deniedSessionKeyPrefix+id, 1, ttl).Err()
+}
+
+// IsTokenDenied отвечает, отозван ли access token сам или вместе с его
+// сессией. Пустые jti и sessionID не проверяются.
+func (repo *AuthRepository) IsTokenDenied(ctx context.Context, jti, sessionID string) (bool, error) {
+	var keys []string
+	if jti != "" {
+		keys = append(keys, deniedTokenKeyPrefix+jti)
+	}
+	if sessionID != "" {
+		keys = append(keys, deniedSessionKeyPrefix+sessionID)
+	}
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	count, err := repo.rdb.Exists(ctx, keys...).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
 }
 
 func (repo *AuthRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
@@ -3,16 +3,17 @@ package service
 import (
 	"authservice/src/config"
 	"authservice/src/dto"
+	"authservice/src/keys"
 	"authservice/src/model"
 	"context"
 	"github.com/cms-crs/protos/gen/go/user_service"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log/slog"
-	"os"
 	"strconv"
 	"time"
 )
@@ -21,29 +22,52 @@ type AuthRepository interface {
 	CreateUser(ctx context.Context, user model.User) (uint, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	GetUserByID(ctx context.Context, id uint) (*model.User, error)
-	UpdateRefreshToken(ctx context.Context, user *model.User, token string) error
-	GetRefreshToken(ctx context.Context, userId string) (string, error)
+	CreateSession(ctx context.Context, session *model.Session) error
+	GetSession(ctx context.Context, id string) (*model.Session, error)
+	ListActiveSessions(ctx context.Context, userID uint) ([]model.Session, error)
+	RotateSession(ctx context.Context, id, oldHash string, next *model.Session) (bool, error)
+	RevokeSession(ctx context.Context, id string) error
+	DenyToken(ctx context.Context, jti string, ttl time.Duration) error
+	DenySession(ctx context.Context, id string, ttl time.Duration) error
+	IsTokenDenied(ctx context.Context, jti, sessionID string) (bool, error)
 }
 
 type AuthService struct {
 	logger         *slog.Logger
 	authRepository AuthRepository
 	config         *config.Config
+	keys           *keys.KeySet
 	userClient     userv1.UserServiceClient
 }
 
 type TokenClaims struct {
 	Email string `json:"email"`
 	Role  string `json:"role"`
+	// ID сессии, которой выдан токен
+	SessionID string `json:"sid,omitempty"`
+	// keys.AccessToken или keys.RefreshToken
+	Type string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
-func NewAuthService(authRepository AuthRepository, logger *slog.Logger, config *config.Config, userConn *grpc.ClientConn) *AuthService {
+func (c *TokenClaims) TokenType() string {
+	return c.Type
+}
+
+// issuedToken — подписанный токен вместе с его jti и сроком жизни.
+type issuedToken struct {
+	value     string
+	id        string
+	expiresAt time.Time
+}
+
+func NewAuthService(authRepository AuthRepository, logger *slog.Logger, config *config.Config, keys *keys.KeySet, userConn *grpc.ClientConn) *AuthService {
 
 	return &AuthService{
 		logger:         logger,
 		authRepository: authRepository,
 		config:         config,
+		keys:           keys,
 		userClient:     userv1.NewUserServiceClient(userConn),
 	}
 }
@@ -91,36 +115,52 @@ func (s *AuthService) Register(ctx context.Context, request dto.RegisterRequest)
 	}, nil
 }
 
-func (s *AuthService) generateAccessToken(user *model.User) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
+func (s *AuthService) generateAccessToken(user *model.User, sessionID string) (issuedToken, error) {
+	return s.signToken(user, sessionID, keys.AccessToken, s.config.JWT.AccessTokenTTL)
+}
 
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, TokenClaims{
-		Email: user.Email,
-		Role:  user.Role.String(),
+func (s *AuthService) generateRefreshToken(user *model.User, sessionID string) (issuedToken, error) {
+	return s.signToken(user, sessionID, keys.RefreshToken, time.Hour*24*time.Duration(s.config.JWT.RefreshTokenTTL))
+}
+
+func (s *AuthService) signToken(user *model.User, sessionID, tokenType string, ttl time.Duration) (issuedToken, error) {
+	now := time.Now()
+
+	claims := TokenClaims{
+		Email:     user.Email,
+		Role:      user.Role.String(),
+		SessionID: sessionID,
+		Type:      tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Subject:   strconv.Itoa(int(user.ID)),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.JWT.AccessTokenTTL)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
-	})
+	}
+
+	value, err := s.keys.Sign(claims)
+	if err != nil {
+		return issuedToken{}, err
+	}
 
-	return at.SignedString([]byte(secret))
+	return issuedToken{value: value, id: claims.ID, expiresAt: claims.ExpiresAt.Time}, nil
 }
 
-func (s *AuthService) generateRefreshToken(user *model.User) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
+// parseToken проверяет подпись и тип токена и, если не переданы другие
+// опции, срок его действия.
+func (s *AuthService) parseToken(token, tokenType string, opts ...jwt.ParserOption) (*TokenClaims, uint, error) {
+	claims := &TokenClaims{}
+	if err := s.keys.Parse(token, claims, tokenType, opts...); err != nil {
+		return nil, 0, err
+	}
 
-	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, TokenClaims{
-		Email: user.Email,
-		Role:  user.Role.String(),
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   strconv.Itoa(int(user.ID)),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * time.Duration(s.config.JWT.RefreshTokenTTL))),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	})
+	userID, err := strconv.ParseUint(claims.Subject, 10, 0)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	return rt.SignedString([]byte(secret))
+	return claims, uint(userID), nil
 }
 
 func (s *AuthService) Login(ctx context.Context, request dto.LoginRequest) (*dto.LoginResponse, error) {
@@ -144,93 +184,159 @@ func (s *AuthService) Login(ctx context.Context, request dto.LoginRequest) (*dto
 		return nil, err
 	}
 
-	// access token
-	accessToken, err := s.generateAccessToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	// refresh token
-	refreshToken, err := s.generateRefreshToken(user)
-
-	user.RefreshToken = refreshToken
-
-	err = s.authRepository.UpdateRefreshToken(ctx, user, refreshToken)
+	accessToken, refreshToken, err := s.startSession(ctx, user, request.Client)
 	if err != nil {
+		log.Error("Error starting session", slog.String("email", email), slog.String("error", err.Error()))
 		return nil, err
 	}
 
 	return &dto.LoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-	}, err
+	}, nil
 }
 
-func (s *AuthService) ValidateToken(_ context.Context, accessToken string) (bool, error) {
+func (s *AuthService) ValidateToken(ctx context.Context, accessToken string) (bool, error) {
 	const op = "auth.service.ValidateToken"
 	log := s.logger.With(slog.String("op", op))
 
-	token, err := jwt.ParseWithClaims(accessToken, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
-
+	claims, _, err := s.parseToken(accessToken, keys.AccessToken, jwt.WithExpirationRequired())
 	if err != nil {
 		log.Error("Error parsing token")
 		return false, err
 	}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-		if claims.ExpiresAt == nil || claims.ExpiresAt.Before(time.Now()) {
-			log.Error("Token is expired")
-			return false, nil
-		}
+	denied, err := s.authRepository.IsTokenDenied(ctx, claims.ID, claims.SessionID)
+	if err != nil {
+		log.Error("Error checking token denylist", "error", err)
+		return false, err
+	}
+	if denied {
+		log.Debug("Token is revoked", "jti", claims.ID, "session_id", claims.SessionID)
+		return false, nil
 	}
 
 	return true, nil
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
+// Logout завершает сессию, которой выдан access token, и вносит его jti в
+// denylist до истечения токена. Истёкший access token тоже принимается,
+// чтобы после его истечения можно было отозвать refresh token.
+func (s *AuthService) Logout(ctx context.Context, accessToken string) error {
+	const op = "auth.service.Logout"
+	log := s.logger.With(slog.String("op", op))
+
+	claims, userID, err := s.parseToken(accessToken, keys.AccessToken, jwt.WithoutClaimsValidation())
+	if err != nil {
+		log.Debug("parse access token failed", "error", err)
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	var sessions []model.Session
+	if claims.SessionID != "" {
+		session, err := s.authRepository.GetSession(ctx, claims.SessionID)
+		if err != nil {
+			log.Error("get session failed", "session_id", claims.SessionID, "error", err)
+			return status.Error(codes.Internal, "failed to logout")
+		}
+		if session != nil && session.UserID == userID && session.RevokedAt == nil {
+			sessions = append(sessions, *session)
+		}
+	} else {
+		// Токен выпущен до появления сессий: завершаем все входы пользователя
+		sessions, err = s.authRepository.ListActiveSessions(ctx, userID)
+		if err != nil {
+			log.Error("list sessions failed", "user_id", userID, "error", err)
+			return status.Error(codes.Internal, "failed to logout")
+		}
+	}
+
+	for i := range sessions {
+		if err := s.revokeSession(ctx, &sessions[i]); err != nil {
+			log.Error("revoke session failed", "session_id", sessions[i].ID, "error", err)
+			return status.Error(codes.Internal, "failed to logout")
+		}
+	}
+
+	if err := s.denyToken(ctx, claims.ID, claims.ExpiresAt); err != nil {
+		log.Error("deny access token failed", "user_id", userID, "error", err)
+		return status.Error(codes.Internal, "failed to logout")
+	}
+
+	log.Info("User logged out", "user_id", userID, "session_id", claims.SessionID)
+
+	return nil
+}
+
+// RefreshToken меняет refresh token на новую пару токенов той же сессии.
+// Повторное предъявление уже обменянного токена значит, что его украли:
+// сессия отзывается целиком, вместе с токенами легитимного клиента.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*dto.RefreshResponse, error) {
 	const op = "auth.service.RefreshToken"
 	log := s.logger.With(slog.String("op", op))
 
-	isValid, err := s.ValidateToken(ctx, refreshToken)
+	claims, userID, err := s.parseToken(refreshToken, keys.RefreshToken)
 	if err != nil {
-		log.Debug("validate token failed", "error", err)
-		return "", status.Error(codes.InvalidArgument, err.Error())
+		log.Debug("parse refresh token failed", "error", err)
+		return nil, status.Error(codes.PermissionDenied, "invalid refresh token")
 	}
 
-	if !isValid {
-		log.Debug("validate token failed", "error", err)
-		return "", status.Error(codes.PermissionDenied, "invalid refresh token")
+	if claims.SessionID == "" {
+		log.Debug("refresh token has no session", "user_id", userID)
+		return nil, status.Error(codes.PermissionDenied, "invalid refresh token")
 	}
 
-	token, err := jwt.ParseWithClaims(refreshToken, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
+	session, err := s.authRepository.GetSession(ctx, claims.SessionID)
 	if err != nil {
-		log.Error("Error parsing token")
-		return "", status.Error(codes.InvalidArgument, err.Error())
+		log.Error("get session failed", "session_id", claims.SessionID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to refresh token")
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok {
-		log.Debug("parse refresh token failed", "error", err)
-		return "", status.Error(codes.InvalidArgument, err.Error())
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		log.Debug("session is not active", "session_id", claims.SessionID)
+		return nil, status.Error(codes.PermissionDenied, "invalid refresh token")
+	}
+
+	tokenHash := hashToken(refreshToken)
+	if session.TokenHash != tokenHash {
+		return nil, s.revokeReusedSession(ctx, session)
+	}
+
+	user, err := s.authRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Error("get user failed", "user_id", userID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to refresh token")
+	}
+
+	access, err := s.generateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to refresh token")
 	}
 
-	dbRefreshToken, err := s.authRepository.GetRefreshToken(ctx, claims.Subject)
+	refresh, err := s.generateRefreshToken(user, session.ID)
 	if err != nil {
-		log.Debug("get refresh token failed", "error", err)
-		return "", status.Error(codes.InvalidArgument, err.Error())
+		return nil, status.Error(codes.Internal, "failed to refresh token")
 	}
 
-	if dbRefreshToken != refreshToken {
-		log.Debug("refresh token failed", "error", err)
-		return "", status.Error(codes.PermissionDenied, "invalid refresh token")
+	rotated, err := s.authRepository.RotateSession(ctx, session.ID, tokenHash, &model.Session{
+		TokenHash:            hashToken(refresh.value),
+		AccessTokenID:        access.id,
+		AccessTokenExpiresAt: access.expiresAt,
+		LastUsedAt:           time.Now(),
+		ExpiresAt:            refresh.expiresAt,
+	})
+	if err != nil {
+		log.Error("rotate session failed", "session_id", session.ID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to refresh token")
 	}
 
-	id, err := strconv.ParseUint(claims.Subject, 10, 0)
-	user, err := s.authRepository.GetUserByID(ctx, uint(id))
+	// Тот же токен одновременно обменяли в другом запросе
+	if !rotated {
+		return nil, s.revokeReusedSession(ctx, session)
+	}
 
-	return s.generateAccessToken(user)
+	return &dto.RefreshResponse{
+		AccessToken:  access.value,
+		RefreshToken: refresh.value,
+	}, nil
 }
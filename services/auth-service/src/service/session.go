@@ -0,0 +1,194 @@
+package service
+
+import (
+	"authservice/src/dto"
+	"authservice/src/keys"
+	"authservice/src/model"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"time"
+)
+
+// hashToken — в базе лежит только хэш refresh token: утечка таблицы не
+// даёт войти от имени пользователя.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startSession выдаёт пару токенов новой сессии. Прежние сессии с того же
+// устройства отзываются: на одно устройство приходится одна сессия.
+func (s *AuthService) startSession(ctx context.Context, user *model.User, client dto.ClientInfo) (string, string, error) {
+	if client.UserAgent != "" {
+		sessions, err := s.authRepository.ListActiveSessions(ctx, user.ID)
+		if err != nil {
+			return "", "", err
+		}
+		for i := range sessions {
+			if sessions[i].UserAgent != client.UserAgent {
+				continue
+			}
+			if err := s.revokeSession(ctx, &sessions[i]); err != nil {
+				return "", "", err
+			}
+		}
+	}
+
+	sessionID := uuid.NewString()
+
+	access, err := s.generateAccessToken(user, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	refresh, err := s.generateRefreshToken(user, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	err = s.authRepository.CreateSession(ctx, &model.Session{
+		ID:                   sessionID,
+		UserID:               user.ID,
+		TokenHash:            hashToken(refresh.value),
+		UserAgent:            client.UserAgent,
+		IP:                   client.IP,
+		AccessTokenID:        access.id,
+		AccessTokenExpiresAt: access.expiresAt,
+		CreatedAt:            now,
+		LastUsedAt:           now,
+		ExpiresAt:            refresh.expiresAt,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return access.value, refresh.value, nil
+}
+
+// revokeSession отзывает сессию и все выданные ей access token. Отзывается
+// сессия целиком, а не последний записанный в ней jti: параллельный обмен
+// refresh token мог выдать access token, которого в базе ещё нет.
+func (s *AuthService) revokeSession(ctx context.Context, session *model.Session) error {
+	if err := s.authRepository.RevokeSession(ctx, session.ID); err != nil {
+		return err
+	}
+
+	return s.authRepository.DenySession(ctx, session.ID, s.config.JWT.AccessTokenTTL)
+}
+
+func (s *AuthService) revokeReusedSession(ctx context.Context, session *model.Session) error {
+	const op = "auth.service.revokeReusedSession"
+	log := s.logger.With(slog.String("op", op))
+
+	log.Warn("Refresh token reuse detected, revoking session", "session_id", session.ID, "user_id", session.UserID)
+
+	if err := s.revokeSession(ctx, session); err != nil {
+		log.Error("revoke session failed", "session_id", session.ID, "error", err)
+		return status.Error(codes.Internal, "failed to refresh token")
+	}
+
+	return status.Error(codes.PermissionDenied, "invalid refresh token")
+}
+
+// denyToken вносит jti в denylist до истечения токена. Токены без jti или
+// уже истёкшие пропускаются.
+func (s *AuthService) denyToken(ctx context.Context, jti string, expiresAt *jwt.NumericDate) error {
+	if jti == "" || expiresAt == nil {
+		return nil
+	}
+
+	ttl := time.Until(expiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return s.authRepository.DenyToken(ctx, jti, ttl)
+}
+
+// authenticate проверяет access token, с которым пришёл вызов.
+func (s *AuthService) authenticate(ctx context.Context, accessToken string) (*TokenClaims, uint, error) {
+	claims, userID, err := s.parseToken(accessToken, keys.AccessToken, jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, 0, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	denied, err := s.authRepository.IsTokenDenied(ctx, claims.ID, claims.SessionID)
+	if err != nil {
+		return nil, 0, status.Error(codes.Unavailable, "failed to check token")
+	}
+	if denied {
+		return nil, 0, status.Error(codes.Unauthenticated, "token has been revoked")
+	}
+
+	return claims, userID, nil
+}
+
+// ListSessions возвращает активные сессии владельца access token.
+func (s *AuthService) ListSessions(ctx context.Context, accessToken string) ([]dto.Session, error) {
+	const op = "auth.service.ListSessions"
+	log := s.logger.With(slog.String("op", op))
+
+	claims, userID, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.authRepository.ListActiveSessions(ctx, userID)
+	if err != nil {
+		log.Error("list sessions failed", "user_id", userID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+
+	result := make([]dto.Session, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, dto.Session{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == claims.SessionID,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeSession завершает одну из сессий владельца access token. Чужая
+// сессия неотличима от несуществующей.
+func (s *AuthService) RevokeSession(ctx context.Context, accessToken, sessionID string) error {
+	const op = "auth.service.RevokeSession"
+	log := s.logger.With(slog.String("op", op))
+
+	_, userID, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	session, err := s.authRepository.GetSession(ctx, sessionID)
+	if err != nil {
+		log.Error("get session failed", "session_id", sessionID, "error", err)
+		return status.Error(codes.Internal, "failed to revoke session")
+	}
+
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return status.Error(codes.NotFound, "session not found")
+	}
+
+	if err := s.revokeSession(ctx, session); err != nil {
+		log.Error("revoke session failed", "session_id", sessionID, "error", err)
+		return status.Error(codes.Internal, "failed to revoke session")
+	}
+
+	log.Info("Session revoked", "session_id", sessionID, "user_id", userID)
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package authz
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Шлюз передаёт access token пользователя в этом ключе метаданных.
+const authorizationKey = "authorization"
+
+type callerKey struct{}
+
+// UnaryServerInterceptor проверяет access token из метаданных и кладёт ID
+// пользователя в контекст. Вызов без токена проходит дальше анонимно: его
+// отклоняют проверки прав в обработчиках.
+func UnaryServerInterceptor(keys *JWKS) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		token, ok := tokenFromMetadata(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		claims, err := ParseAccessToken(token, keys)
+		if err != nil || claims.Subject == "" {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		return handler(context.WithValue(ctx, callerKey{}, claims.Subject), req)
+	}
+}
+
+// CallerFromContext возвращает ID пользователя, от имени которого пришёл вызов.
+func CallerFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(callerKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// ForwardToken передаёт токен вызывающего в исходящие вызовы, чтобы
+// следующий сервис проверил права того же пользователя.
+func ForwardToken(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if token, ok := tokenFromMetadata(ctx); ok {
+		ctx = metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+token)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func tokenFromMetadata(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return "", false
+	}
+
+	token := strings.TrimPrefix(values[0], "Bearer ")
+	return token, token != ""
+}
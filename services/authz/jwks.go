@@ -0,0 +1,119 @@
+package authz
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Ключ с незнакомым kid запрашивается заново не чаще этого: поддельные
+// токены не должны заваливать auth-service запросами.
+const jwksMinRefreshInterval = 10 * time.Second
+
+// JWKS — открытые ключи auth-service, которыми шлюз и сервисы проверяют
+// access token сами, не обращаясь к auth-service на каждый запрос. Набор
+// обновляется раз в ttl и сразу, если пришёл токен с новым kid, так что
+// ротация ключей не требует перезапуска.
+type JWKS struct {
+	url        string
+	ttl        time.Duration
+	minRefresh time.Duration
+	client     *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]ed25519.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func NewJWKS(url string, ttl time.Duration) *JWKS {
+	return &JWKS{
+		url:        url,
+		ttl:        ttl,
+		minRefresh: jwksMinRefreshInterval,
+		client:     &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Keyfunc возвращает ключ для kid из заголовка токена.
+func (j *JWKS) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, ok := j.keys[kid]
+	stale := now.Sub(j.fetchedAt) >= j.ttl
+	if (!ok || stale) && now.Sub(j.attemptedAt) >= j.minRefresh {
+		j.attemptedAt = now
+		// Если auth-service недоступен, работаем на прежних ключах
+		if keys, err := j.fetch(); err == nil {
+			j.keys, j.fetchedAt = keys, now
+			key, ok = keys[kid]
+		} else if !ok {
+			return nil, err
+		}
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	return key, nil
+}
+
+func (j *JWKS) fetch() (map[string]ed25519.PublicKey, error) {
+	const op = "JWKS.fetch"
+
+	ctx, cancel := context.WithTimeout(context.Background(), j.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status %d", op, resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Kid string `json:"kid"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "OKP" || k.Crv != "Ed25519" {
+			continue
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			continue
+		}
+		keys[k.Kid] = ed25519.PublicKey(x)
+	}
+
+	return keys, nil
+}
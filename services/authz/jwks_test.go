@@ -0,0 +1,148 @@
+package authz
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyServer отдаёт JWKS, как auth-service, и подписывает токены его ключами.
+type keyServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]ed25519.PrivateKey
+	fetches int
+}
+
+func newKeyServer(t *testing.T, kids ...string) *keyServer {
+	t.Helper()
+
+	ks := &keyServer{keys: make(map[string]ed25519.PrivateKey)}
+	for _, kid := range kids {
+		ks.addKey(t, kid)
+	}
+
+	ks.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		ks.mu.Lock()
+		defer ks.mu.Unlock()
+		ks.fetches++
+
+		type jwk struct {
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Kid string `json:"kid"`
+		}
+		set := struct {
+			Keys []jwk `json:"keys"`
+		}{}
+		for kid, key := range ks.keys {
+			x := base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+			set.Keys = append(set.Keys, jwk{Kty: "OKP", Crv: "Ed25519", X: x, Kid: kid})
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(ks.Close)
+
+	return ks
+}
+
+func (ks *keyServer) addKey(t *testing.T, kid string) {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	ks.mu.Lock()
+	ks.keys[kid] = key
+	ks.mu.Unlock()
+}
+
+func (ks *keyServer) sign(t *testing.T, kid string, claims Claims) string {
+	t.Helper()
+
+	ks.mu.Lock()
+	key := ks.keys[kid]
+	ks.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func (ks *keyServer) jwks() *JWKS {
+	keys := NewJWKS(ks.URL, time.Minute)
+	keys.minRefresh = 0
+	return keys
+}
+
+func accessClaims() Claims {
+	return Claims{
+		Type: AccessToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
+
+func TestJWKSPicksUpRotatedKeys(t *testing.T) {
+	ks := newKeyServer(t, "key-1")
+	keys := ks.jwks()
+
+	for i := 0; i < 3; i++ {
+		if _, err := ParseAccessToken(ks.sign(t, "key-1", accessClaims()), keys); err != nil {
+			t.Fatalf("key-1: %v", err)
+		}
+	}
+	if ks.fetches != 1 {
+		t.Fatalf("expected JWKS to be fetched once, got %d", ks.fetches)
+	}
+
+	// Новый ключ виден сразу, без ожидания ttl
+	ks.addKey(t, "key-2")
+	if _, err := ParseAccessToken(ks.sign(t, "key-2", accessClaims()), keys); err != nil {
+		t.Fatalf("key-2: %v", err)
+	}
+	if ks.fetches != 2 {
+		t.Fatalf("expected JWKS to be fetched twice, got %d", ks.fetches)
+	}
+}
+
+func TestParseAccessTokenRejectsOtherTokens(t *testing.T) {
+	ks := newKeyServer(t, "key-1")
+	keys := ks.jwks()
+
+	refresh := accessClaims()
+	refresh.Type = RefreshToken
+	if _, err := ParseAccessToken(ks.sign(t, "key-1", refresh), keys); !errors.Is(err, ErrNotAccessToken) {
+		t.Fatalf("refresh token: expected ErrNotAccessToken, got %v", err)
+	}
+
+	endless := accessClaims()
+	endless.ExpiresAt = nil
+	if _, err := ParseAccessToken(ks.sign(t, "key-1", endless), keys); err == nil {
+		t.Fatal("token without exp: expected error")
+	}
+
+	other := newKeyServer(t, "key-1")
+	if _, err := ParseAccessToken(other.sign(t, "key-1", accessClaims()), keys); err == nil {
+		t.Fatal("token signed by another key: expected error")
+	}
+}
@@ -0,0 +1,46 @@
+// Package authz проверяет access token auth-service и права пользователя
+// в команде. Общий для шлюза и team-, board- и task-service.
+package authz
+
+// Role — роль пользователя в команде. Пустая роль значит, что пользователь
+// в команде не состоит.
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+type Permission string
+
+const (
+	PermissionRead          Permission = "read"
+	PermissionWrite         Permission = "write"
+	PermissionManageTeam    Permission = "manage_team"
+	PermissionManageMembers Permission = "manage_members"
+	PermissionDeleteBoards  Permission = "delete_boards"
+)
+
+// permissions — матрица прав. Шлюз проверяет её до вызова сервиса, а team-,
+// board- и task-service повторно, чтобы прямой вызов сервиса не обходил шлюз.
+var permissions = map[Role][]Permission{
+	RoleViewer: {PermissionRead},
+	RoleMember: {PermissionRead, PermissionWrite},
+	RoleAdmin: {
+		PermissionRead,
+		PermissionWrite,
+		PermissionManageTeam,
+		PermissionManageMembers,
+		PermissionDeleteBoards,
+	},
+}
+
+func (r Role) Can(permission Permission) bool {
+	for _, allowed := range permissions[r] {
+		if allowed == permission {
+			return true
+		}
+	}
+	return false
+}
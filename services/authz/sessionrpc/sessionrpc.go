@@ -0,0 +1,150 @@
+// Package sessionrpc описывает RPC сессий auth-service, которых пока нет в
+// cms-crs/protos: сообщения, дескриптор сервиса, клиент шлюза и кодек.
+// Сообщения передаются в JSON. Когда RPC появятся в protos, пакет заменит
+// сгенерированный код.
+package sessionrpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const ServiceName = "auth.SessionService"
+
+const (
+	listSessionsMethod  = "/" + ServiceName + "/ListSessions"
+	revokeSessionMethod = "/" + ServiceName + "/RevokeSession"
+)
+
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type ListSessionsRequest struct{}
+
+type ListSessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+}
+
+type RevokeSessionRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+type RevokeSessionResponse struct{}
+
+// Codec кодирует сообщения сессий в JSON. Он не регистрируется глобально:
+// клиент передаёт его в вызов, а сервер получает через ServerCodec.
+type Codec struct{}
+
+func (Codec) Marshal(v any) ([]byte, error) {
+	if message, ok := v.(proto.Message); ok {
+		return proto.Marshal(message)
+	}
+	return json.Marshal(v)
+}
+
+func (Codec) Unmarshal(data []byte, v any) error {
+	if message, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, message)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (Codec) Name() string { return "json" }
+
+// ServerCodec — опция сервера auth-service. Сервер выбирает кодек один на все
+// сервисы, поэтому сообщения protobuf Codec по-прежнему кодирует как protobuf.
+func ServerCodec() grpc.ServerOption {
+	return grpc.ForceServerCodec(Codec{})
+}
+
+type Server interface {
+	ListSessions(ctx context.Context, in *ListSessionsRequest) (*ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest) (*RevokeSessionResponse, error)
+}
+
+func RegisterServer(s grpc.ServiceRegistrar, srv Server) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*Server)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSessions",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(ListSessionsRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req any) (any, error) {
+					return srv.(Server).ListSessions(ctx, req.(*ListSessionsRequest))
+				}
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: listSessionsMethod}
+				return interceptor(ctx, in, info, handler)
+			},
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(RevokeSessionRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req any) (any, error) {
+					return srv.(Server).RevokeSession(ctx, req.(*RevokeSessionRequest))
+				}
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: revokeSessionMethod}
+				return interceptor(ctx, in, info, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// Client вызывает RPC сессий. Пользователя auth-service определяет по access
+// token в метаданных вызова.
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+func (c *Client) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	if err := c.conn.Invoke(ctx, listSessionsMethod, in, out, c.callOptions(opts)...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error) {
+	out := new(RevokeSessionResponse)
+	if err := c.conn.Invoke(ctx, revokeSessionMethod, in, out, c.callOptions(opts)...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) callOptions(opts []grpc.CallOption) []grpc.CallOption {
+	return append([]grpc.CallOption{grpc.ForceCodec(Codec{})}, opts...)
+}
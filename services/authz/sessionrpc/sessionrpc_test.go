@@ -0,0 +1,72 @@
+package sessionrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type sessionServer struct {
+	revoked string
+}
+
+func (s *sessionServer) ListSessions(ctx context.Context, in *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return &ListSessionsResponse{Sessions: []Session{{ID: "session-1", Current: true}}}, nil
+}
+
+func (s *sessionServer) RevokeSession(ctx context.Context, in *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	s.revoked = in.SessionID
+	return &RevokeSessionResponse{}, nil
+}
+
+// Сессии идут в JSON, а остальные сервисы того же сервера — в protobuf.
+func TestServerCodecKeepsProtobufServices(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(ServerCodec())
+	sessions := &sessionServer{}
+	RegisterServer(server, sessions)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	client := NewClient(conn)
+
+	list, err := client.ListSessions(ctx, &ListSessionsRequest{})
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(list.Sessions) != 1 || list.Sessions[0].ID != "session-1" || !list.Sessions[0].Current {
+		t.Errorf("sessions = %+v", list.Sessions)
+	}
+
+	if _, err := client.RevokeSession(ctx, &RevokeSessionRequest{SessionID: "session-1"}); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if sessions.revoked != "session-1" {
+		t.Errorf("revoked = %q, want session-1", sessions.revoked)
+	}
+
+	status, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("health check: %v", err)
+	}
+	if status.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health status = %v", status.Status)
+	}
+}
@@ -0,0 +1,65 @@
+package authz
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TeamRoleSource возвращает роли пользователя во всех его командах по ID
+// команды. Источник — team-service GetUserTeams.
+type TeamRoleSource interface {
+	UserTeamRoles(ctx context.Context, userID string) (map[string]string, error)
+}
+
+// TeamRoles запоминает роли пользователя на ttl, чтобы не ходить в
+// team-service на каждый вызов. Смена роли вступает в силу не позже чем
+// через ttl.
+type TeamRoles struct {
+	source  TeamRoleSource
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]teamRolesEntry
+}
+
+type teamRolesEntry struct {
+	roles     map[string]string
+	expiresAt time.Time
+}
+
+func NewTeamRoles(source TeamRoleSource, ttl time.Duration) *TeamRoles {
+	return &TeamRoles{
+		source:  source,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]teamRolesEntry),
+	}
+}
+
+func (r *TeamRoles) Role(ctx context.Context, userID, teamID string) (Role, error) {
+	now := r.now()
+
+	r.mu.Lock()
+	entry, ok := r.entries[userID]
+	r.mu.Unlock()
+
+	if !ok || !now.Before(entry.expiresAt) {
+		roles, err := r.source.UserTeamRoles(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		entry = teamRolesEntry{roles: roles, expiresAt: now.Add(r.ttl)}
+
+		r.mu.Lock()
+		for key, cached := range r.entries {
+			if !now.Before(cached.expiresAt) {
+				delete(r.entries, key)
+			}
+		}
+		r.entries[userID] = entry
+		r.mu.Unlock()
+	}
+
+	return Role(entry.roles[teamID]), nil
+}
@@ -0,0 +1,48 @@
+package authz
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// teamRoleSource отдаёт роли из карты и считает обращения к team-service.
+type teamRoleSource struct {
+	roles map[string]map[string]string
+	calls int
+}
+
+func (s *teamRoleSource) UserTeamRoles(_ context.Context, userID string) (map[string]string, error) {
+	s.calls++
+	return s.roles[userID], nil
+}
+
+func TestTeamRolesExpire(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	source := &teamRoleSource{roles: map[string]map[string]string{
+		"user-1": {"team-1": "member"},
+	}}
+
+	roles := NewTeamRoles(source, time.Minute)
+	roles.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if role, _ := roles.Role(context.Background(), "user-1", "team-1"); role != RoleMember {
+			t.Fatalf("expected %s, got %q", RoleMember, role)
+		}
+	}
+	if source.calls != 1 {
+		t.Fatalf("expected 1 call to team-service, got %d", source.calls)
+	}
+
+	// Понижение роли видно после истечения ttl
+	source.roles["user-1"]["team-1"] = "viewer"
+	now = now.Add(time.Minute)
+
+	if role, _ := roles.Role(context.Background(), "user-1", "team-1"); role != RoleViewer {
+		t.Fatalf("expected %s after ttl, got %q", RoleViewer, role)
+	}
+	if source.calls != 2 {
+		t.Fatalf("expected 2 calls to team-service, got %d", source.calls)
+	}
+}
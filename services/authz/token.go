@@ -0,0 +1,44 @@
+package authz
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Тип токена в claim typ. Refresh token подписан тем же ключом, и без
+// проверки типа его можно было бы предъявить вместо access token.
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+var ErrNotAccessToken = errors.New("not an access token")
+
+// Claims — claims токенов auth-service.
+type Claims struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	Type  string `json:"typ"`
+	// ID сессии, которой выдан токен
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ParseAccessToken проверяет подпись ключом auth-service из JWKS, срок
+// действия и что это access token. Алгоритм фиксирован, чтобы токен не мог
+// выбрать его сам.
+func ParseAccessToken(token string, keys *JWKS) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, keys.Keyfunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Type != AccessToken {
+		return nil, ErrNotAccessToken
+	}
+
+	return claims, nil
+}
@@ -30,6 +30,15 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		if err := application.StartKafkaConsumer(ctx); err != nil {
+			log.Error("Kafka consumer failed", "error", err)
+			cancel()
+		}
+	}()
+
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 	select {
@@ -39,8 +48,13 @@ func main() {
 	}
 
 	cancel()
+	<-consumerDone
 	application.GRPCServer.Stop()
 
+	if err := application.Close(); err != nil {
+		log.Error("Failed to close application", "error", err)
+	}
+
 	log.Info("Board service application stopped")
 }
 
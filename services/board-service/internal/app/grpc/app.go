@@ -13,17 +13,19 @@ import (
 	"google.golang.org/grpc"
 	"log/slog"
 	"net"
+	"shiroyama/authz"
 )
 
 type App struct {
-	log  *slog.Logger
-	gRPC *grpc.Server
-	port int
-	db   *sql.DB
+	log            *slog.Logger
+	gRPC           *grpc.Server
+	port           int
+	db             *sql.DB
+	serviceClients *clients.ServiceClients
 }
 
 func New(log *slog.Logger, port int, db *sql.DB, cfg *config.Config) *App {
-	gRPCServer := grpc.NewServer()
+	gRPCServer := grpc.NewServer(grpc.UnaryInterceptor(authz.UnaryServerInterceptor(authz.NewJWKS(cfg.JWT.JWKSURL, cfg.JWT.JWKSCacheTTL))))
 
 	boardRepository := boardRepo.NewRepository(db)
 	listRepository := listRepo.NewRepository(db)
@@ -35,19 +37,21 @@ func New(log *slog.Logger, port int, db *sql.DB, cfg *config.Config) *App {
 
 	if err != nil {
 		log.Error("failed to initialize service clients", "error", err)
+		panic(err)
 	}
-	defer serviceClients.Close()
 
 	boardSvc := boardService.NewBoardService(log, boardRepository, serviceClients.UserClient, serviceClients.TeamClient)
 	listSvc := listService.NewListService(log, listRepository, boardRepository)
+	teamRoles := authz.NewTeamRoles(serviceClients, cfg.Clients.TeamRoleCacheTTL)
 
-	handler.Register(gRPCServer, log, boardSvc, listSvc)
+	handler.Register(gRPCServer, log, boardSvc, listSvc, teamRoles)
 
 	return &App{
-		log:  log,
-		gRPC: gRPCServer,
-		port: port,
-		db:   db,
+		log:            log,
+		gRPC:           gRPCServer,
+		port:           port,
+		db:             db,
+		serviceClients: serviceClients,
 	}
 }
 
@@ -89,4 +93,9 @@ func (app *App) Stop() {
 	log.Info("Stopping gRPC server", slog.Int("port", app.port))
 
 	app.gRPC.GracefulStop()
+
+	// Клиенты нужны обработчикам до последнего запроса
+	if err := app.serviceClients.Close(); err != nil {
+		log.Error("Failed to close service clients", "error", err)
+	}
 }
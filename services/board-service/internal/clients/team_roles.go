@@ -0,0 +1,22 @@
+package clients
+
+import (
+	"context"
+
+	teamv1 "github.com/cms-crs/protos/gen/go/team_service"
+)
+
+// UserTeamRoles возвращает роли пользователя по ID команды.
+func (c *ServiceClients) UserTeamRoles(ctx context.Context, userID string) (map[string]string, error) {
+	response, err := c.TeamClient.GetUserTeams(ctx, &teamv1.GetUserTeamsRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]string, len(response.Teams))
+	for _, team := range response.Teams {
+		roles[team.Team.Id] = team.Role
+	}
+
+	return roles, nil
+}
@@ -6,4 +6,6 @@ type ClientsConfig struct {
 	UserServiceAddr string        `yaml:"userServiceAddr" envDefault:"user-service:44044"`
 	TeamServiceAddr string        `yaml:"teamServiceAddr" envDefault:"team-service:44045"`
 	ClientTimeout   time.Duration `yaml:"clientTimeout" envDefault:"30s"`
+	// Сколько помнить роли пользователя в командах
+	TeamRoleCacheTTL time.Duration `yaml:"teamRoleCacheTTL" env-default:"30s"`
 }
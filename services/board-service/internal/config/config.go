@@ -13,6 +13,7 @@ type Config struct {
 	DB      DatabaseConfig `yaml:"db"`
 	Kafka   KafkaConfig    `yaml:"kafka"`
 	Clients ClientsConfig  `yaml:"clients"`
+	JWT     JWTConfig      `yaml:"jwt"`
 }
 
 func MustLoad() *Config {
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// JWTConfig — откуда брать открытые ключи, которыми auth-service подписывает
+// access token.
+type JWTConfig struct {
+	JWKSURL      string        `yaml:"jwksURL" env:"JWKS_URL" env-default:"http://localhost:8081/.well-known/jwks.json"`
+	JWKSCacheTTL time.Duration `yaml:"jwksCacheTTL" env:"JWKS_CACHE_TTL" env-default:"5m"`
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"shiroyama/authz"
+)
+
+type TeamRoles interface {
+	Role(ctx context.Context, userID, teamID string) (authz.Role, error)
+}
+
+// authorizeTeam проверяет право вызывающего в команде. Шлюз проверяет то же
+// самое, но сервис не должен полагаться на то, что вызов пришёл через шлюз.
+func (h *Handler) authorizeTeam(ctx context.Context, teamID string, permission authz.Permission) error {
+	const op = "handler.authorizeTeam"
+
+	userID, ok := authz.CallerFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("team_id", teamID),
+		slog.String("user_id", userID),
+	)
+
+	role, err := h.teamRoles.Role(ctx, userID, teamID)
+	if err != nil {
+		log.Error("Failed to get team role", "error", err)
+		return status.Error(codes.Unavailable, "failed to check permissions")
+	}
+
+	if !role.Can(permission) {
+		log.Info("Permission denied", "role", role, "permission", permission)
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	return nil
+}
+
+// authorizeBoard проверяет право в команде доски. Доской без команды
+// распоряжается только её создатель. Личные доски удалённого пользователя
+// помечаются удалёнными вместе с ним, а доска без команды и без автора
+// недоступна никому.
+func (h *Handler) authorizeBoard(ctx context.Context, boardID string, permission authz.Permission) error {
+	board, err := h.boardService.GetBoard(ctx, boardID)
+	if err != nil {
+		return err
+	}
+
+	if board.TeamId == "" {
+		userID, ok := authz.CallerFromContext(ctx)
+		if !ok {
+			return status.Error(codes.Unauthenticated, "authentication required")
+		}
+		if board.CreatedBy == "" || userID != board.CreatedBy {
+			return status.Error(codes.PermissionDenied, "permission denied")
+		}
+		return nil
+	}
+
+	return h.authorizeTeam(ctx, board.TeamId, permission)
+}
+
+func (h *Handler) authorizeList(ctx context.Context, listID string, permission authz.Permission) error {
+	list, err := h.listService.GetList(ctx, listID)
+	if err != nil {
+		return err
+	}
+
+	return h.authorizeBoard(ctx, list.BoardId, permission)
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	boardv1 "github.com/cms-crs/protos/gen/go/board_service"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"log/slog"
+	"shiroyama/authz"
 )
 
 type BoardService interface {
@@ -31,13 +34,15 @@ type Handler struct {
 	log          *slog.Logger
 	boardService BoardService
 	listService  ListService
+	teamRoles    TeamRoles
 }
 
-func NewHandler(log *slog.Logger, boardService BoardService, listService ListService) *Handler {
+func NewHandler(log *slog.Logger, boardService BoardService, listService ListService, teamRoles TeamRoles) *Handler {
 	return &Handler{
 		log:          log,
 		boardService: boardService,
 		listService:  listService,
+		teamRoles:    teamRoles,
 	}
 }
 
@@ -53,6 +58,22 @@ func (h *Handler) CreateBoard(ctx context.Context, req *boardv1.CreateBoardReque
 
 	log.Info("Create board request received")
 
+	// Доску создают только от своего имени: создатель распоряжается доской
+	// без команды
+	userID, ok := authz.CallerFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if userID != req.CreatedBy {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	if req.TeamId != "" {
+		if err := h.authorizeTeam(ctx, req.TeamId, authz.PermissionWrite); err != nil {
+			return nil, err
+		}
+	}
+
 	board, err := h.boardService.CreateBoard(ctx, req)
 	if err != nil {
 		log.Error("Failed to create board", "error", err)
@@ -74,6 +95,10 @@ func (h *Handler) GetBoard(ctx context.Context, req *boardv1.GetBoardRequest) (*
 
 	log.Info("Get board request received")
 
+	if err := h.authorizeBoard(ctx, req.Id, authz.PermissionRead); err != nil {
+		return nil, err
+	}
+
 	board, err := h.boardService.GetBoardWithLists(ctx, req.Id)
 	if err != nil {
 		log.Error("Failed to get board", "error", err)
@@ -96,6 +121,10 @@ func (h *Handler) UpdateBoard(ctx context.Context, req *boardv1.UpdateBoardReque
 
 	log.Info("Update board request received")
 
+	if err := h.authorizeBoard(ctx, req.Id, authz.PermissionWrite); err != nil {
+		return nil, err
+	}
+
 	board, err := h.boardService.UpdateBoard(ctx, req)
 	if err != nil {
 		log.Error("Failed to update board", "error", err)
@@ -117,6 +146,10 @@ func (h *Handler) DeleteBoard(ctx context.Context, req *boardv1.DeleteBoardReque
 
 	log.Info("Delete board request received")
 
+	if err := h.authorizeBoard(ctx, req.Id, authz.PermissionDeleteBoards); err != nil {
+		return nil, err
+	}
+
 	err := h.boardService.DeleteBoard(ctx, req.Id)
 	if err != nil {
 		log.Error("Failed to delete board", "error", err)
@@ -138,6 +171,18 @@ func (h *Handler) GetUserBoards(ctx context.Context, req *boardv1.GetUserBoardsR
 
 	log.Info("Get user boards request received")
 
+	callerID, ok := authz.CallerFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	// Список досок раскрывает команды пользователя, поэтому его видит только
+	// сам пользователь
+	if callerID != req.UserId {
+		log.Info("Permission denied", "caller_id", callerID)
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
 	boards, err := h.boardService.GetUserBoards(ctx, req.UserId)
 	if err != nil {
 		log.Error("Failed to get user boards", "error", err)
@@ -161,6 +206,10 @@ func (h *Handler) GetTeamBoards(ctx context.Context, req *boardv1.GetTeamBoardsR
 
 	log.Info("Get team boards request received")
 
+	if err := h.authorizeTeam(ctx, req.TeamId, authz.PermissionRead); err != nil {
+		return nil, err
+	}
+
 	boards, err := h.boardService.GetTeamBoards(ctx, req.TeamId)
 	if err != nil {
 		log.Error("Failed to get team boards", "error", err)
@@ -185,6 +234,10 @@ func (h *Handler) CreateList(ctx context.Context, req *boardv1.CreateListRequest
 
 	log.Info("Create list request received")
 
+	if err := h.authorizeBoard(ctx, req.BoardId, authz.PermissionWrite); err != nil {
+		return nil, err
+	}
+
 	list, err := h.listService.CreateList(ctx, req)
 	if err != nil {
 		log.Error("Failed to create list", "error", err)
@@ -207,6 +260,10 @@ func (h *Handler) UpdateList(ctx context.Context, req *boardv1.UpdateListRequest
 
 	log.Info("Update list request received")
 
+	if err := h.authorizeList(ctx, req.Id, authz.PermissionWrite); err != nil {
+		return nil, err
+	}
+
 	list, err := h.listService.UpdateList(ctx, req)
 	if err != nil {
 		log.Error("Failed to update list", "error", err)
@@ -228,6 +285,10 @@ func (h *Handler) DeleteList(ctx context.Context, req *boardv1.DeleteListRequest
 
 	log.Info("Delete list request received")
 
+	if err := h.authorizeList(ctx, req.Id, authz.PermissionWrite); err != nil {
+		return nil, err
+	}
+
 	err := h.listService.DeleteList(ctx, req.Id)
 	if err != nil {
 		log.Error("Failed to delete list", "error", err)
@@ -250,6 +311,10 @@ func (h *Handler) ReorderLists(ctx context.Context, req *boardv1.ReorderListsReq
 
 	log.Info("Reorder lists request received")
 
+	if err := h.authorizeBoard(ctx, req.BoardId, authz.PermissionWrite); err != nil {
+		return nil, err
+	}
+
 	err := h.listService.ReorderLists(ctx, req)
 	if err != nil {
 		log.Error("Failed to reorder lists", "error", err)
@@ -261,7 +326,7 @@ func (h *Handler) ReorderLists(ctx context.Context, req *boardv1.ReorderListsReq
 	return &emptypb.Empty{}, nil
 }
 
-func Register(gRPCServer interface{}, log *slog.Logger, boardService BoardService, listService ListService) {
-	handler := NewHandler(log, boardService, listService)
+func Register(gRPCServer interface{}, log *slog.Logger, boardService BoardService, listService ListService, teamRoles TeamRoles) {
+	handler := NewHandler(log, boardService, listService, teamRoles)
 	boardv1.RegisterBoardServiceServer(gRPCServer.(*grpc.Server), handler)
 }
@@ -0,0 +1,81 @@
+package board
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var boardColumns = []string{"id", "name", "description", "team_id", "created_by", "created_at", "updated_at"}
+
+func TestReadBoardsAfterClearBoardsCreator(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SET deleted_at = NOW()")).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("board-personal"))
+	mock.ExpectQuery(regexp.QuoteMeta("SET created_by = NULL")).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("board-personal").AddRow("board-1"))
+	mock.ExpectCommit()
+
+	tx, err := repo.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	data, err := repo.ClearBoardsCreatorTx(ctx, tx, "user-1")
+	if err != nil {
+		t.Fatalf("clear boards creator: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if len(data.BoardIDs) != 2 || len(data.PersonalBoardIDs) != 1 || data.PersonalBoardIDs[0] != "board-personal" {
+		t.Fatalf("unexpected rollback data: %+v", data)
+	}
+
+	// У командной доски после удаления автора created_by равен NULL
+	mock.ExpectQuery(regexp.QuoteMeta("FROM boards")).
+		WithArgs("board-1").
+		WillReturnRows(sqlmock.NewRows(boardColumns).
+			AddRow("board-1", "Roadmap", "", "team-1", nil, now, now))
+
+	board, err := repo.GetBoard(ctx, "board-1")
+	if err != nil {
+		t.Fatalf("get board: %v", err)
+	}
+	if board.TeamId != "team-1" || board.CreatedBy != "" {
+		t.Fatalf("unexpected board: team %q, created by %q", board.TeamId, board.CreatedBy)
+	}
+
+	// Одна доска без автора не ломает список досок команды
+	mock.ExpectQuery(regexp.QuoteMeta("FROM boards")).
+		WithArgs("team-1").
+		WillReturnRows(sqlmock.NewRows(boardColumns).
+			AddRow("board-1", "Roadmap", "", "team-1", nil, now, now).
+			AddRow("board-2", "Backlog", "", "team-1", "user-2", now, now))
+
+	boards, err := repo.GetTeamBoards(ctx, "team-1")
+	if err != nil {
+		t.Fatalf("get team boards: %v", err)
+	}
+	if len(boards) != 2 || boards[1].CreatedBy != "user-2" {
+		t.Fatalf("unexpected team boards: %v", boards)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
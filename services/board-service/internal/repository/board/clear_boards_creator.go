@@ -0,0 +1,74 @@
+package board
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"shiroyama/events"
+
+	"github.com/lib/pq"
+)
+
+func (r *Repository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
+// ClearBoardsCreatorTx обнуляет created_by у досок пользователя и возвращает их ID,
+// чтобы при откате саги вернуть автора ровно этим доскам. Личные доски без
+// команды помечаются удалёнными: без автора ими некому распоряжаться.
+func (r *Repository) ClearBoardsCreatorTx(ctx context.Context, tx *sql.Tx, userID string) (*events.BoardDeletionData, error) {
+	personalBoardIDs, err := queryIDs(ctx, tx, `
+		UPDATE boards
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE created_by = $1 AND team_id IS NULL AND deleted_at IS NULL
+		RETURNING id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete personal boards: %w", err)
+	}
+
+	boardIDs, err := queryIDs(ctx, tx, `
+		UPDATE boards
+		SET created_by = NULL, updated_at = NOW()
+		WHERE created_by = $1
+		RETURNING id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clear boards creator: %w", err)
+	}
+
+	return &events.BoardDeletionData{BoardIDs: boardIDs, PersonalBoardIDs: personalBoardIDs}, nil
+}
+
+// RestoreBoardsCreator возвращает автора доскам из rollback_data и снимает
+// пометку удаления с его личных досок. Доски, которым за это время назначили
+// другого автора, не трогаем.
+func (r *Repository) RestoreBoardsCreator(ctx context.Context, userID string, data *events.BoardDeletionData) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE boards
+		SET created_by = $1, updated_at = NOW()
+		WHERE id = ANY($2) AND created_by IS NULL
+	`, userID, pq.Array(data.BoardIDs))
+	if err != nil {
+		return fmt.Errorf("failed to restore boards creator: %w", err)
+	}
+
+	if len(data.PersonalBoardIDs) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE boards
+			SET deleted_at = NULL, updated_at = NOW()
+			WHERE id = ANY($1) AND created_by = $2
+		`, pq.Array(data.PersonalBoardIDs), userID)
+		if err != nil {
+			return fmt.Errorf("failed to restore personal boards: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,64 @@
+package board
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"shiroyama/events"
+
+	"github.com/lib/pq"
+)
+
+// DeleteTeamBoardsTx помечает удалёнными доски команды и возвращает их ID для
+// отката вместе со списками этих досок, чьи задачи удалит task-service.
+func (r *Repository) DeleteTeamBoardsTx(ctx context.Context, tx *sql.Tx, teamID string) (*events.TeamBoardsDeletionData, []string, error) {
+	boardIDs, err := queryIDs(ctx, tx, `
+		UPDATE boards
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE team_id = $1 AND deleted_at IS NULL
+		RETURNING id
+	`, teamID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to delete team boards: %w", err)
+	}
+
+	listIDs, err := queryIDs(ctx, tx, `SELECT id FROM lists WHERE board_id = ANY($1)`, pq.Array(boardIDs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get team lists: %w", err)
+	}
+
+	return &events.TeamBoardsDeletionData{BoardIDs: boardIDs}, listIDs, nil
+}
+
+// RestoreTeamBoards снимает пометку удаления с досок из rollback_data.
+func (r *Repository) RestoreTeamBoards(ctx context.Context, data *events.TeamBoardsDeletionData) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE boards
+		SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = ANY($1)
+	`, pq.Array(data.BoardIDs))
+	if err != nil {
+		return fmt.Errorf("failed to restore team boards: %w", err)
+	}
+
+	return nil
+}
+
+func queryIDs(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
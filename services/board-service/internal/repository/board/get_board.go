@@ -14,29 +14,42 @@ func (r *Repository) GetBoard(ctx context.Context, boardID string) (*boardv1.Boa
 	query := `
 		SELECT id, name, description, team_id, created_by, created_at, updated_at
 		FROM boards
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
+	board, err := scanBoard(r.db.QueryRowContext(ctx, query, boardID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("board not found")
+		}
+		return nil, fmt.Errorf("failed to get board: %w", err)
+	}
+
+	return board, nil
+}
+
+// scanBoard читает строку boards. team_id и created_by могут быть NULL:
+// created_by обнуляется при удалении автора.
+func scanBoard(row interface{ Scan(dest ...any) error }) (*boardv1.Board, error) {
 	var board boardv1.Board
+	var teamID, createdBy sql.NullString
 	var createdAt, updatedAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, boardID).Scan(
+	err := row.Scan(
 		&board.Id,
 		&board.Name,
 		&board.Description,
-		&board.TeamId,
-		&board.CreatedBy,
+		&teamID,
+		&createdBy,
 		&createdAt,
 		&updatedAt,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("board not found")
-		}
-		return nil, fmt.Errorf("failed to get board: %w", err)
+		return nil, err
 	}
 
+	board.TeamId = teamID.String
+	board.CreatedBy = createdBy.String
 	board.CreatedAt = timestamppb.New(createdAt)
 	board.UpdatedAt = timestamppb.New(updatedAt)
 
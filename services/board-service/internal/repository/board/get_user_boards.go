@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	boardv1 "github.com/cms-crs/protos/gen/go/board_service"
-	"google.golang.org/protobuf/types/known/timestamppb"
-	"time"
 )
 
 func (r *Repository) GetUserBoards(ctx context.Context, userID string) ([]*boardv1.Board, error) {
@@ -13,7 +11,7 @@ func (r *Repository) GetUserBoards(ctx context.Context, userID string) ([]*board
 		SELECT DISTINCT b.id, b.name, b.description, b.team_id, b.created_by, b.created_at, b.updated_at
 		FROM boards b
 		JOIN team_members tm ON b.team_id = tm.team_id
-		WHERE tm.user_id = $1
+		WHERE tm.user_id = $1 AND b.deleted_at IS NULL
 		ORDER BY b.created_at DESC
 	`
 
@@ -25,25 +23,11 @@ func (r *Repository) GetUserBoards(ctx context.Context, userID string) ([]*board
 
 	var boards []*boardv1.Board
 	for rows.Next() {
-		var board boardv1.Board
-		var createdAt, updatedAt time.Time
-
-		err := rows.Scan(
-			&board.Id,
-			&board.Name,
-			&board.Description,
-			&board.TeamId,
-			&board.CreatedBy,
-			&createdAt,
-			&updatedAt,
-		)
+		board, err := scanBoard(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan board: %w", err)
 		}
-
-		board.CreatedAt = timestamppb.New(createdAt)
-		board.UpdatedAt = timestamppb.New(updatedAt)
-		boards = append(boards, &board)
+		boards = append(boards, board)
 	}
 
 	return boards, nil
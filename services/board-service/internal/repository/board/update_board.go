@@ -12,7 +12,7 @@ func (r *Repository) UpdateBoard(ctx context.Context, req *boardv1.UpdateBoardRe
 	query := `
 		UPDATE boards 
 		SET name = $2, description = $3, updated_at = $4
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	result, err := r.db.ExecContext(ctx, query, req.Id, req.Name, req.Description, now)
@@ -0,0 +1,274 @@
+package kafka
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"shiroyama/events"
+)
+
+type BoardRepository interface {
+	ClearBoardsCreatorTx(ctx context.Context, tx *sql.Tx, userID string) (*events.BoardDeletionData, error)
+	RestoreBoardsCreator(ctx context.Context, userID string, data *events.BoardDeletionData) error
+	DeleteTeamBoardsTx(ctx context.Context, tx *sql.Tx, teamID string) (*events.TeamBoardsDeletionData, []string, error)
+	RestoreTeamBoards(ctx context.Context, data *events.TeamBoardsDeletionData) error
+}
+
+type BoardConsumer struct {
+	boardRepo BoardRepository
+	inbox     *Inbox
+	outbox    *Outbox
+	logger    *slog.Logger
+}
+
+func NewBoardConsumer(repo BoardRepository, inbox *Inbox, outbox *Outbox, logger *slog.Logger) *BoardConsumer {
+	return &BoardConsumer{
+		boardRepo: repo,
+		inbox:     inbox,
+		outbox:    outbox,
+		logger:    logger,
+	}
+}
+
+func (bc *BoardConsumer) HandleEvent(ctx context.Context, event events.Event) error {
+	ctx = events.ContextWithTrace(ctx, event.Trace())
+
+	bc.logger.Info("Processing event",
+		"event_id", event.ID,
+		"event_type", event.Type,
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"trace", event.Trace())
+
+	processed, err := bc.inbox.IsProcessed(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		bc.logger.Info("Skipping already processed event",
+			"event_id", event.ID,
+			"saga_id", event.SagaID)
+		return nil
+	}
+
+	switch event.Type {
+	case events.BoardUserDeleteRequested:
+		return bc.handleBoardUserDeleteRequested(ctx, event)
+	case events.BoardUserDeleteRollback:
+		return bc.handleBoardUserDeleteRollback(ctx, event)
+	case events.BoardTeamDeleteRequested:
+		return bc.handleBoardTeamDeleteRequested(ctx, event)
+	case events.BoardTeamDeleteRollback:
+		return bc.handleBoardTeamDeleteRollback(ctx, event)
+	default:
+		bc.logger.Warn("Unknown event type", "event_type", event.Type)
+		return nil
+	}
+}
+
+func (bc *BoardConsumer) handleBoardUserDeleteRequested(ctx context.Context, event events.Event) error {
+	var (
+		deletionData *events.BoardDeletionData
+		clearErr     error
+	)
+
+	// Изменения, отметка inbox и ответ саге коммитятся вместе
+	err := bc.outbox.InTx(ctx, func(tx *sql.Tx) error {
+		deletionData, clearErr = bc.boardRepo.ClearBoardsCreatorTx(ctx, tx, event.UserID)
+		if clearErr != nil {
+			return clearErr
+		}
+
+		if _, err := bc.inbox.MarkProcessedTx(ctx, tx, event.ID); err != nil {
+			return err
+		}
+
+		return bc.outbox.EnqueueTx(ctx, tx, events.BoardEventsTopic, bc.reply(event, events.BoardUserDeleted, map[string]interface{}{
+			"boards_updated_count": len(deletionData.BoardIDs),
+			"rollback_data":        deletionData,
+		}))
+	})
+	if clearErr != nil {
+		bc.logger.Error("Failed to clear boards creator",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID,
+			"error", clearErr)
+
+		// Ответ о неудаче — это результат обработки: оркестратор сам решит,
+		// повторить шаг или откатить сагу
+		return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardUserDeleteFailed, map[string]interface{}{
+			"error": clearErr.Error(),
+		}))
+	}
+	if err != nil {
+		return err
+	}
+
+	bc.logger.Info("Cleared boards creator",
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"boards_count", len(deletionData.BoardIDs))
+
+	return nil
+}
+
+func (bc *BoardConsumer) handleBoardUserDeleteRollback(ctx context.Context, event events.Event) error {
+	var deletionData events.BoardDeletionData
+	if rollbackData, exists := event.Data["rollback_data"]; exists {
+		rollbackBytes, err := json.Marshal(rollbackData)
+		if err == nil {
+			err = json.Unmarshal(rollbackBytes, &deletionData)
+		}
+		if err != nil {
+			return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardUserDeleteRollbackFailed, map[string]interface{}{
+				"error": fmt.Sprintf("invalid rollback_data: %v", err),
+			}))
+		}
+	}
+
+	// Без rollback_data не знаем, какие доски вернуть: выбирать их по
+	// created_by IS NULL нельзя, это заденет доски других удалённых пользователей
+	if len(deletionData.BoardIDs) == 0 {
+		bc.logger.Warn("Rollback event has no boards to restore",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID)
+		return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardUserDeleteRollbackCompleted, nil))
+	}
+
+	if err := bc.boardRepo.RestoreBoardsCreator(ctx, event.UserID, &deletionData); err != nil {
+		bc.logger.Error("Failed to restore boards creator",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID,
+			"error", err)
+		return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardUserDeleteRollbackFailed, map[string]interface{}{
+			"error": err.Error(),
+		}))
+	}
+
+	bc.logger.Info("Restored boards creator",
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"boards_count", len(deletionData.BoardIDs))
+
+	// Восстановление идемпотентно: если подтверждение не запишется, откат
+	// повторится при повторной доставке
+	return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardUserDeleteRollbackCompleted, nil))
+}
+
+func (bc *BoardConsumer) handleBoardTeamDeleteRequested(ctx context.Context, event events.Event) error {
+	teamID, _ := event.Data["team_id"].(string)
+
+	var (
+		deletionData *events.TeamBoardsDeletionData
+		listIDs      []string
+		deleteErr    error
+	)
+
+	err := bc.outbox.InTx(ctx, func(tx *sql.Tx) error {
+		deletionData, listIDs, deleteErr = bc.boardRepo.DeleteTeamBoardsTx(ctx, tx, teamID)
+		if deleteErr != nil {
+			return deleteErr
+		}
+
+		if _, err := bc.inbox.MarkProcessedTx(ctx, tx, event.ID); err != nil {
+			return err
+		}
+
+		// list_ids оркестратор передаст в шаг task-service
+		return bc.outbox.EnqueueTx(ctx, tx, events.BoardEventsTopic, bc.reply(event, events.BoardTeamDeleted, map[string]interface{}{
+			"team_id":       teamID,
+			"list_ids":      listIDs,
+			"rollback_data": deletionData,
+		}))
+	})
+	if deleteErr != nil {
+		bc.logger.Error("Failed to delete team boards",
+			"team_id", teamID,
+			"saga_id", event.SagaID,
+			"error", deleteErr)
+
+		return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardTeamDeleteFailed, map[string]interface{}{
+			"team_id": teamID,
+			"error":   deleteErr.Error(),
+		}))
+	}
+	if err != nil {
+		return err
+	}
+
+	bc.logger.Info("Deleted team boards",
+		"team_id", teamID,
+		"saga_id", event.SagaID,
+		"boards_count", len(deletionData.BoardIDs),
+		"lists_count", len(listIDs))
+
+	return nil
+}
+
+func (bc *BoardConsumer) handleBoardTeamDeleteRollback(ctx context.Context, event events.Event) error {
+	teamID, _ := event.Data["team_id"].(string)
+
+	var deletionData events.TeamBoardsDeletionData
+	if rollbackData, exists := event.Data["rollback_data"]; exists {
+		rollbackBytes, err := json.Marshal(rollbackData)
+		if err == nil {
+			err = json.Unmarshal(rollbackBytes, &deletionData)
+		}
+		if err != nil {
+			return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardTeamDeleteRollbackFailed, map[string]interface{}{
+				"team_id": teamID,
+				"error":   fmt.Sprintf("invalid rollback_data: %v", err),
+			}))
+		}
+	}
+
+	// Восстанавливаем только доски из rollback_data: удалённые раньше саги
+	// доски команды должны остаться удалёнными
+	if len(deletionData.BoardIDs) == 0 {
+		bc.logger.Warn("Rollback event has no team boards to restore",
+			"team_id", teamID,
+			"saga_id", event.SagaID)
+		return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardTeamDeleteRollbackCompleted, map[string]interface{}{
+			"team_id": teamID,
+		}))
+	}
+
+	if err := bc.boardRepo.RestoreTeamBoards(ctx, &deletionData); err != nil {
+		bc.logger.Error("Failed to restore team boards",
+			"team_id", teamID,
+			"saga_id", event.SagaID,
+			"error", err)
+		return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardTeamDeleteRollbackFailed, map[string]interface{}{
+			"team_id": teamID,
+			"error":   err.Error(),
+		}))
+	}
+
+	bc.logger.Info("Restored team boards",
+		"team_id", teamID,
+		"saga_id", event.SagaID,
+		"boards_count", len(deletionData.BoardIDs))
+
+	return bc.enqueueReply(ctx, event, bc.reply(event, events.BoardTeamDeleteRollbackCompleted, map[string]interface{}{
+		"team_id": teamID,
+	}))
+}
+
+// enqueueReply пишет ответ, не сопровождающий изменения данных, в одной
+// транзакции с отметкой inbox: повторная доставка команды не отправит
+// оркестратору второй ответ.
+func (bc *BoardConsumer) enqueueReply(ctx context.Context, event events.Event, reply events.Event) error {
+	return bc.outbox.InTx(ctx, func(tx *sql.Tx) error {
+		fresh, err := bc.inbox.MarkProcessedTx(ctx, tx, event.ID)
+		if err != nil || !fresh {
+			return err
+		}
+		return bc.outbox.EnqueueTx(ctx, tx, events.BoardEventsTopic, reply)
+	})
+}
+
+func (bc *BoardConsumer) reply(originalEvent events.Event, eventType events.EventType, data map[string]interface{}) events.Event {
+	return events.New(eventType, originalEvent.UserID, originalEvent.SagaID, data)
+}
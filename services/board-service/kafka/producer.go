@@ -1,8 +1,8 @@
 package kafka
 
 import (
-	"encoding/json"
 	"github.com/IBM/sarama"
+	"shiroyama/events"
 )
 
 type Producer struct {
@@ -25,15 +25,15 @@ func NewKafkaProducer(brokers []string) (*Producer, error) {
 	return &Producer{producer: producer}, nil
 }
 
-func (p *Producer) PublishEvent(topic string, event Event) error {
-	eventBytes, err := json.Marshal(event)
+func (p *Producer) PublishEvent(topic string, event events.Event) error {
+	eventBytes, err := events.Marshal(event)
 	if err != nil {
 		return err
 	}
 
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
-		Key:   sarama.StringEncoder(event.UserID),
+		Key:   sarama.StringEncoder(event.Key()),
 		Value: sarama.ByteEncoder(eventBytes),
 		Headers: []sarama.RecordHeader{
 			{
@@ -47,10 +47,24 @@ func (p *Producer) PublishEvent(topic string, event Event) error {
 		},
 	}
 
+	for key, value := range event.Headers {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{
+			Key:   []byte(key),
+			Value: []byte(value),
+		})
+	}
+
 	_, _, err = p.producer.SendMessage(msg)
 	return err
 }
 
+// SendMessage отправляет готовое сообщение как есть — нужно для DLQ,
+// где значение и заголовки не должны меняться.
+func (p *Producer) SendMessage(msg *sarama.ProducerMessage) error {
+	_, _, err := p.producer.SendMessage(msg)
+	return err
+}
+
 func (p *Producer) Close() error {
 	return p.producer.Close()
 }
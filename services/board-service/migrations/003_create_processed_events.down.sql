@@ -0,0 +1 @@
+DELETE FROM processed_events WHERE consumer = 'board-service-group';
@@ -0,0 +1,9 @@
+-- Таблица может уже существовать: board-service живёт в одной базе с user-service
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_id     VARCHAR(64) NOT NULL,
+    consumer     VARCHAR(64) NOT NULL,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (event_id, consumer)
+);
+
+CREATE INDEX IF NOT EXISTS idx_processed_events_processed_at ON processed_events(processed_at);
@@ -0,0 +1 @@
+DELETE FROM outbox_events WHERE source = 'board-service';
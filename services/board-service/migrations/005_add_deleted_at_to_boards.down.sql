@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_boards_team_id;
+
+ALTER TABLE boards DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_boards_team_id ON boards(team_id) WHERE deleted_at IS NULL;
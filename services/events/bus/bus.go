@@ -0,0 +1,35 @@
+// Package bus описывает, как сервисы публикуют события и получают их, не
+// завися от Kafka. Продюсеры и консьюмеры сервисов реализуют эти интерфейсы
+// поверх sarama, а Broker — в памяти процесса для тестов.
+package bus
+
+import (
+	"context"
+
+	"shiroyama/events"
+)
+
+// Publisher отправляет событие в топик.
+type Publisher interface {
+	PublishEvent(topic string, event events.Event) error
+}
+
+// Handler обрабатывает событие, уже прошедшее проверку по контракту.
+// Ошибка означает, что событие нужно доставить ещё раз.
+type Handler interface {
+	HandleEvent(ctx context.Context, event events.Event) error
+}
+
+// HandlerFunc позволяет использовать функцию как Handler.
+type HandlerFunc func(ctx context.Context, event events.Event) error
+
+func (f HandlerFunc) HandleEvent(ctx context.Context, event events.Event) error {
+	return f(ctx, event)
+}
+
+// Subscriber подписывает обработчик на топики от имени группы. Как и в
+// Kafka, каждая группа получает каждое событие один раз, а события после
+// подписки приходят в порядке публикации.
+type Subscriber interface {
+	Subscribe(group string, handler Handler, topics ...string) error
+}
@@ -0,0 +1,195 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"shiroyama/events"
+)
+
+// DefaultMaxAttempts — сколько раз Broker доставляет событие группе, прежде
+// чем отложить его в мёртвые письма.
+const DefaultMaxAttempts = 3
+
+// Защита от обработчиков, которые бесконечно публикуют друг другу события.
+const maxDeliveries = 10000
+
+// Message — событие вместе с топиком, в который оно опубликовано.
+type Message struct {
+	Topic string
+	Event events.Event
+}
+
+// DeadLetter — событие, которое группа так и не смогла обработать.
+type DeadLetter struct {
+	Message
+	Group string
+	Err   error
+}
+
+type subscription struct {
+	group   string
+	topics  []string
+	handler Handler
+	offset  int
+}
+
+// Broker заменяет Kafka в тестах. Публикация только складывает событие в
+// журнал, а доставляет события Drain — в порядке публикации и в одной
+// горутине, поэтому прогоны воспроизводимы. Событие проходит через
+// events.Marshal и events.Unmarshal, как и через настоящий топик.
+type Broker struct {
+	mu            sync.Mutex
+	log           []Message
+	subscriptions []*subscription
+	deadLetters   []DeadLetter
+	maxAttempts   int
+}
+
+func NewBroker(maxAttempts int) *Broker {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	return &Broker{maxAttempts: maxAttempts}
+}
+
+func (b *Broker) PublishEvent(topic string, event events.Event) error {
+	const op = "Broker.PublishEvent"
+
+	payload, err := events.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	delivered, err := events.Unmarshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.log = append(b.log, Message{Topic: topic, Event: delivered})
+	return nil
+}
+
+// Subscribe подписывает группу на топики. Группа получает только события,
+// опубликованные после подписки, как консьюмер с OffsetNewest.
+func (b *Broker) Subscribe(group string, handler Handler, topics ...string) error {
+	const op = "Broker.Subscribe"
+
+	if len(topics) == 0 {
+		return fmt.Errorf("%s: group %s has no topics", op, group)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subscriptions {
+		if sub.group == group {
+			return fmt.Errorf("%s: group %s is already subscribed", op, group)
+		}
+	}
+
+	b.subscriptions = append(b.subscriptions, &subscription{
+		group:   group,
+		topics:  topics,
+		handler: handler,
+		offset:  len(b.log),
+	})
+	return nil
+}
+
+// Drain доставляет события, пока они не кончатся, включая те, что
+// обработчики публикуют по ходу. Если группа не справилась с событием
+// maxAttempts раз, оно уходит в мёртвые письма и доставка идёт дальше.
+func (b *Broker) Drain(ctx context.Context) error {
+	const op = "Broker.Drain"
+
+	for deliveries := 0; ; deliveries++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if deliveries >= maxDeliveries {
+			return fmt.Errorf("%s: gave up after %d deliveries", op, maxDeliveries)
+		}
+
+		sub, message, ok := b.next()
+		if !ok {
+			return nil
+		}
+
+		var err error
+		for attempt := 0; attempt < b.maxAttempts; attempt++ {
+			if err = sub.handler.HandleEvent(ctx, message.Event); err == nil {
+				break
+			}
+		}
+
+		b.mu.Lock()
+		if err != nil {
+			b.deadLetters = append(b.deadLetters, DeadLetter{Message: message, Group: sub.group, Err: err})
+		}
+		sub.offset++
+		b.mu.Unlock()
+	}
+}
+
+// next выбирает самое раннее недоставленное событие среди всех групп.
+func (b *Broker) next() (*subscription, Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var (
+		earliest *subscription
+		position int
+	)
+	for _, sub := range b.subscriptions {
+		for sub.offset < len(b.log) && !slices.Contains(sub.topics, b.log[sub.offset].Topic) {
+			sub.offset++
+		}
+		if sub.offset == len(b.log) {
+			continue
+		}
+		if earliest == nil || sub.offset < position {
+			earliest, position = sub, sub.offset
+		}
+	}
+
+	if earliest == nil {
+		return nil, Message{}, false
+	}
+	return earliest, b.log[position], true
+}
+
+// Published возвращает все опубликованные события по порядку.
+func (b *Broker) Published() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return slices.Clone(b.log)
+}
+
+// PublishedTo возвращает события, опубликованные в topic.
+func (b *Broker) PublishedTo(topic string) []events.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var published []events.Event
+	for _, message := range b.log {
+		if message.Topic == topic {
+			published = append(published, message.Event)
+		}
+	}
+	return published
+}
+
+func (b *Broker) DeadLetters() []DeadLetter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return slices.Clone(b.deadLetters)
+}
@@ -0,0 +1,151 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"shiroyama/events"
+)
+
+func TestBrokerDeliversToEveryGroupInOrder(t *testing.T) {
+	broker := NewBroker(0)
+
+	var received []string
+	record := func(group string) Handler {
+		return HandlerFunc(func(ctx context.Context, event events.Event) error {
+			received = append(received, group+":"+string(event.Type))
+			return nil
+		})
+	}
+
+	if err := broker.Subscribe("auth", record("auth"), events.AuthCommandsTopic); err != nil {
+		t.Fatal(err)
+	}
+	if err := broker.Subscribe("audit", record("audit"), events.AuthCommandsTopic, events.AuthEventsTopic); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, broker, events.AuthCommandsTopic, events.New(events.AuthUserDeleteRequested, "user-1", "saga-1", nil))
+	publish(t, broker, events.AuthEventsTopic, events.New(events.AuthUserDeleted, "user-1", "saga-1", nil))
+
+	if err := broker.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"auth:AuthUserDeleteRequested",
+		"audit:AuthUserDeleteRequested",
+		"audit:AuthUserDeleted",
+	}
+	if len(received) != len(want) {
+		t.Fatalf("received %v, want %v", received, want)
+	}
+	for i := range want {
+		if received[i] != want[i] {
+			t.Fatalf("received %v, want %v", received, want)
+		}
+	}
+}
+
+func TestBrokerDeliversEventsPublishedByHandlers(t *testing.T) {
+	broker := NewBroker(0)
+
+	err := broker.Subscribe("auth", HandlerFunc(func(ctx context.Context, event events.Event) error {
+		return broker.PublishEvent(events.AuthEventsTopic, events.New(events.AuthUserDeleted, event.UserID, event.SagaID, nil))
+	}), events.AuthCommandsTopic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var replies int
+	err = broker.Subscribe("orchestrator", HandlerFunc(func(ctx context.Context, event events.Event) error {
+		replies++
+		return nil
+	}), events.AuthEventsTopic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, broker, events.AuthCommandsTopic, events.New(events.AuthUserDeleteRequested, "user-1", "saga-1", nil))
+
+	if err := broker.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if replies != 1 {
+		t.Fatalf("orchestrator received %d replies, want 1", replies)
+	}
+}
+
+func TestBrokerMovesFailingEventsToDeadLetters(t *testing.T) {
+	broker := NewBroker(2)
+
+	var attempts int
+	handlerErr := errors.New("database is down")
+	err := broker.Subscribe("auth", HandlerFunc(func(ctx context.Context, event events.Event) error {
+		attempts++
+		return handlerErr
+	}), events.AuthCommandsTopic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, broker, events.AuthCommandsTopic, events.New(events.AuthUserDeleteRequested, "user-1", "saga-1", nil))
+
+	if err := broker.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Fatalf("handler called %d times, want 2", attempts)
+	}
+
+	deadLetters := broker.DeadLetters()
+	if len(deadLetters) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(deadLetters))
+	}
+	if deadLetters[0].Group != "auth" || !errors.Is(deadLetters[0].Err, handlerErr) {
+		t.Fatalf("unexpected dead letter: %+v", deadLetters[0])
+	}
+}
+
+func TestBrokerRejectsEventsOutsideContract(t *testing.T) {
+	broker := NewBroker(0)
+
+	event := events.New(events.AuthUserDeleteFailed, "user-1", "saga-1", nil)
+	if err := broker.PublishEvent(events.AuthEventsTopic, event); !errors.Is(err, events.ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent, got %v", err)
+	}
+	if len(broker.Published()) != 0 {
+		t.Fatal("invalid event must not be published")
+	}
+}
+
+func TestBrokerSkipsEventsPublishedBeforeSubscription(t *testing.T) {
+	broker := NewBroker(0)
+
+	publish(t, broker, events.AuthCommandsTopic, events.New(events.AuthUserDeleteRequested, "user-1", "saga-1", nil))
+
+	var received int
+	err := broker.Subscribe("auth", HandlerFunc(func(ctx context.Context, event events.Event) error {
+		received++
+		return nil
+	}), events.AuthCommandsTopic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := broker.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if received != 0 {
+		t.Fatalf("received %d events published before subscription", received)
+	}
+}
+
+func publish(t *testing.T, broker *Broker, topic string, event events.Event) {
+	t.Helper()
+
+	if err := broker.PublishEvent(topic, event); err != nil {
+		t.Fatal(err)
+	}
+}
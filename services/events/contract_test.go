@@ -0,0 +1,173 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSchemaListsEveryEventType(t *testing.T) {
+	raw, err := schemaFS.ReadFile("schema/event.v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Properties struct {
+			Type struct {
+				Enum []string `json:"enum"`
+			} `json:"type"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for _, eventType := range Types() {
+		types = append(types, string(eventType))
+	}
+
+	enum := schema.Properties.Type.Enum
+	sort.Strings(types)
+	sort.Strings(enum)
+
+	if len(types) != len(enum) {
+		t.Fatalf("schema lists %d event types, Go contract has %d", len(enum), len(types))
+	}
+	for i := range types {
+		if types[i] != enum[i] {
+			t.Fatalf("event type mismatch: schema %q, Go contract %q", enum[i], types[i])
+		}
+	}
+}
+
+func TestMarshalValidEvents(t *testing.T) {
+	// Ответы участников в том виде, в каком их собирают сервисы
+	cases := []Event{
+		New(UserDeletionRequested, "user-1", "saga-1", map[string]interface{}{"initiated_by": "grpc_request"}),
+		New(AuthUserDeleted, "user-1", "saga-1", nil),
+		New(AuthUserDeleteFailed, "user-1", "saga-1", map[string]interface{}{"error": "boom"}),
+		New(TeamUserDeleted, "user-1", "saga-1", map[string]interface{}{
+			"rollback_data": &TeamDeletionData{Teams: []TeamMembershipData{
+				{TeamID: "team-1", Role: "owner", JoinedAt: time.Now()},
+			}},
+		}),
+		New(TeamUserDeleted, "user-1", "saga-1", map[string]interface{}{
+			"rollback_data": &TeamDeletionData{},
+		}),
+		New(BoardUserDeleted, "user-1", "saga-1", map[string]interface{}{
+			"rollback_data": &BoardDeletionData{BoardIDs: []string{"board-1"}},
+		}),
+		New(TaskUserDeleted, "user-1", "saga-1", map[string]interface{}{
+			"rollback_data": &TaskDeletionData{AssignmentIDs: []uint{1}, TaskIDs: []string{"task-1"}},
+		}),
+		New(TaskUserDeleteRollback, "user-1", "saga-1", map[string]interface{}{
+			"rollback_data": map[string]interface{}{"assignment_ids": []interface{}{1.0}, "task_ids": []interface{}{}},
+		}),
+		New(TeamDeletionRequested, "", "", map[string]interface{}{"team_id": "team-1"}),
+		New(TeamDeleted, "", "saga-1", map[string]interface{}{
+			"team_id":       "team-1",
+			"rollback_data": &DeletedTeamData{WasDeletedBySaga: true},
+		}),
+		New(TeamDeleteRollback, "", "saga-1", map[string]interface{}{
+			"team_id":       "team-1",
+			"rollback_data": map[string]interface{}{"was_deleted_by_saga": false},
+		}),
+		New(BoardTeamDeleted, "", "saga-1", map[string]interface{}{
+			"team_id":       "team-1",
+			"list_ids":      []string{"list-1"},
+			"rollback_data": &TeamBoardsDeletionData{BoardIDs: []string{"board-1"}},
+		}),
+		New(TaskTeamDeleteRequested, "", "saga-1", map[string]interface{}{
+			"team_id":  "team-1",
+			"list_ids": []interface{}{"list-1"},
+		}),
+		New(TaskTeamDeleted, "", "saga-1", map[string]interface{}{
+			"team_id":       "team-1",
+			"rollback_data": &TeamTasksDeletionData{TaskIDs: []string{"task-1"}},
+		}),
+		New(AuthUserDeleteRollbackCompleted, "user-1", "saga-1", nil),
+		New(BoardUserDeleteRollbackFailed, "user-1", "saga-1", map[string]interface{}{"error": "boom"}),
+		New(BoardTeamDeleteRollbackCompleted, "", "saga-1", map[string]interface{}{"team_id": "team-1"}),
+	}
+
+	for _, event := range cases {
+		payload, err := Marshal(event)
+		if err != nil {
+			t.Errorf("%s: %v", event.Type, err)
+			continue
+		}
+
+		decoded, err := Unmarshal(payload)
+		if err != nil {
+			t.Errorf("%s: %v", event.Type, err)
+			continue
+		}
+		if decoded.ID != event.ID || decoded.Type != event.Type || decoded.Version != SchemaVersion {
+			t.Errorf("%s: round trip changed the event: %+v", event.Type, decoded)
+		}
+	}
+}
+
+func TestMarshalRejectsContractViolations(t *testing.T) {
+	unversioned := New(AuthUserDeleted, "user-1", "saga-1", nil)
+	unversioned.Version = 0
+
+	cases := map[string]Event{
+		"unknown type":          New("user.deletion.requested", "user-1", "saga-1", nil),
+		"missing version":       unversioned,
+		"missing user":          New(AuthUserDeleted, "", "saga-1", nil),
+		"failure without error": New(TeamUserDeleteFailed, "user-1", "saga-1", nil),
+		"reply without rollback data": New(BoardUserDeleted, "user-1", "saga-1", map[string]interface{}{
+			"boards_updated_count": 1,
+		}),
+		"malformed rollback data": New(TaskUserDeleted, "user-1", "saga-1", map[string]interface{}{
+			"rollback_data": map[string]interface{}{"task_ids": "task-1"},
+		}),
+		"team event without team":          New(TeamDeleteRequested, "", "saga-1", nil),
+		"rollback failure without error":   New(TaskUserDeleteRollbackFailed, "user-1", "saga-1", nil),
+		"team rollback reply without team": New(TaskTeamDeleteRollbackCompleted, "", "saga-1", nil),
+		"team reply without rollback data": New(TeamDeleted, "", "saga-1", map[string]interface{}{
+			"team_id": "team-1",
+		}),
+		"board reply without lists": New(BoardTeamDeleted, "", "saga-1", map[string]interface{}{
+			"team_id":       "team-1",
+			"rollback_data": &TeamBoardsDeletionData{},
+		}),
+	}
+
+	for name, event := range cases {
+		if _, err := Marshal(event); !errors.Is(err, ErrInvalidEvent) {
+			t.Errorf("%s: expected ErrInvalidEvent, got %v", name, err)
+		}
+	}
+}
+
+func TestUnmarshalRejectsUnknownFields(t *testing.T) {
+	payload := []byte(`{"id":"1","type":"AuthUserDeleted","version":1,"user_id":"user-1","saga_id":"saga-1",` +
+		`"timestamp":"2025-01-01T00:00:00Z","correlation":"x"}`)
+
+	if _, err := Unmarshal(payload); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent, got %v", err)
+	}
+}
+
+func TestKeyFallsBackToTeam(t *testing.T) {
+	cases := map[string]struct {
+		event Event
+		want  string
+	}{
+		"user":    {New(AuthUserDeleted, "user-1", "saga-1", nil), "user-1"},
+		"team":    {New(TeamDeleted, "", "saga-1", map[string]interface{}{"team_id": "team-1"}), "team-1"},
+		"neither": {New(TeamDeletionCompleted, "", "saga-1", nil), "saga-1"},
+	}
+
+	for name, tc := range cases {
+		if got := tc.event.Key(); got != tc.want {
+			t.Errorf("%s: key %q, want %q", name, got, tc.want)
+		}
+	}
+}
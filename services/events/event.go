@@ -0,0 +1,45 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion — версия контракта, с которой публикуются новые события.
+const SchemaVersion = 1
+
+type Event struct {
+	ID        string                 `json:"id"`
+	Type      EventType              `json:"type"`
+	Version   int                    `json:"version"`
+	UserID    string                 `json:"user_id"`
+	SagaID    string                 `json:"saga_id"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Headers   map[string]string      `json:"headers,omitempty"`
+}
+
+func New(eventType EventType, userID, sagaID string, data map[string]interface{}) Event {
+	return Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		Version:   SchemaVersion,
+		UserID:    userID,
+		SagaID:    sagaID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+}
+
+// Key — ключ партиционирования Kafka. События одного пользователя или одной
+// команды попадают в одну партицию и сохраняют порядок.
+func (e Event) Key() string {
+	if e.UserID != "" {
+		return e.UserID
+	}
+	if teamID, ok := e.Data["team_id"].(string); ok && teamID != "" {
+		return teamID
+	}
+	return e.SagaID
+}
@@ -0,0 +1,10 @@
+module shiroyama/events
+
+go 1.24.2
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
+)
+
+require golang.org/x/text v0.14.0 // indirect
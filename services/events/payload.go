@@ -0,0 +1,47 @@
+package events
+
+import "time"
+
+// Данные для отката шагов саги. Участник возвращает их в rollback_data ответа,
+// оркестратор сохраняет и отправляет обратно в событии компенсации.
+
+type TeamDeletionData struct {
+	Teams []TeamMembershipData `json:"teams"`
+}
+
+type TeamMembershipData struct {
+	TeamID   string    `json:"team_id"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// BoardDeletionData — доски, у которых при удалении пользователя обнулили
+// created_by. Личные доски без команды из них помечаются удалёнными: кроме
+// автора ими никто не распоряжается.
+type BoardDeletionData struct {
+	BoardIDs         []string `json:"board_ids"`
+	PersonalBoardIDs []string `json:"personal_board_ids,omitempty"`
+}
+
+// TaskDeletionData — назначения и задачи, изменённые при удалении пользователя.
+type TaskDeletionData struct {
+	AssignmentIDs []uint   `json:"assignment_ids"`
+	TaskIDs       []string `json:"task_ids"`
+}
+
+// DeletedTeamData — сведения о пометке команды удалённой в саге удаления
+// команды. Откат снимает пометку, только если её поставила эта сага.
+type DeletedTeamData struct {
+	WasDeletedBySaga bool `json:"was_deleted_by_saga"`
+}
+
+// TeamBoardsDeletionData — доски команды, помеченные удалёнными в саге
+// удаления команды.
+type TeamBoardsDeletionData struct {
+	BoardIDs []string `json:"board_ids"`
+}
+
+// TeamTasksDeletionData — задачи досок команды, помеченные удалёнными.
+type TeamTasksDeletionData struct {
+	TaskIDs []string `json:"task_ids"`
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:embed schema/*.json
+var schemaFS embed.FS
+
+// ErrInvalidEvent — событие не соответствует контракту своей версии.
+var ErrInvalidEvent = errors.New("invalid event")
+
+// schemas — JSON Schema по версиям контракта. Старые версии остаются здесь,
+// пока в топиках могут лежать такие события.
+var schemas = map[int]*jsonschema.Schema{
+	1: mustCompile("schema/event.v1.json"),
+}
+
+func mustCompile(path string) *jsonschema.Schema {
+	raw, err := schemaFS.ReadFile(path)
+	if err != nil {
+		panic(fmt.Sprintf("events: read schema %s: %v", path, err))
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		panic(fmt.Sprintf("events: parse schema %s: %v", path, err))
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+	if err := compiler.AddResource(path, doc); err != nil {
+		panic(fmt.Sprintf("events: add schema %s: %v", path, err))
+	}
+
+	return compiler.MustCompile(path)
+}
+
+// Validate проверяет сериализованное событие по схеме его версии.
+func Validate(payload []byte) error {
+	var envelope struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	schema, ok := schemas[envelope.Version]
+	if !ok {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEvent, envelope.Version)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	return nil
+}
+
+// Marshal сериализует событие и отказывает, если оно нарушает контракт, —
+// невалидное событие не должно попасть в топик.
+func Marshal(event Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Validate(payload); err != nil {
+		return nil, fmt.Errorf("%s: %w", event.Type, err)
+	}
+
+	return payload, nil
+}
+
+// Unmarshal проверяет событие по схеме и только потом декодирует его.
+func Unmarshal(payload []byte) (Event, error) {
+	var event Event
+
+	if err := Validate(payload); err != nil {
+		return event, err
+	}
+
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return event, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	return event, nil
+}
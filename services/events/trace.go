@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+)
+
+// Заголовки событий для сквозной трассировки. Имена совпадают с HTTP и gRPC
+// заголовками, через которые запрос приходит в шлюз и в user-service.
+const (
+	HeaderRequestID   = "x-request-id"
+	HeaderTraceparent = "traceparent"
+)
+
+// Trace связывает событие с HTTP-запросом, который его породил: request ID
+// шлюза и W3C traceparent (https://www.w3.org/TR/trace-context/).
+type Trace struct {
+	RequestID   string
+	Traceparent string
+}
+
+type traceKey struct{}
+
+func ContextWithTrace(ctx context.Context, trace Trace) context.Context {
+	if trace.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+func TraceFromContext(ctx context.Context) Trace {
+	trace, _ := ctx.Value(traceKey{}).(Trace)
+	return trace
+}
+
+func TraceFromHeaders(headers map[string]string) Trace {
+	return Trace{
+		RequestID:   headers[HeaderRequestID],
+		Traceparent: headers[HeaderTraceparent],
+	}
+}
+
+func (t Trace) IsZero() bool {
+	return t.RequestID == "" && t.Traceparent == ""
+}
+
+// TraceID — идентификатор трассы из traceparent, общий для всех событий
+// одного запроса.
+func (t Trace) TraceID() string {
+	traceID, _, ok := parseTraceparent(t.Traceparent)
+	if !ok {
+		return ""
+	}
+	return traceID
+}
+
+// Headers возвращает заголовки для следующего события в цепочке: request ID
+// сохраняется, а traceparent получает новый parent-id с тем же trace-id.
+func (t Trace) Headers() map[string]string {
+	if t.IsZero() {
+		return nil
+	}
+
+	headers := make(map[string]string, 2)
+	if t.RequestID != "" {
+		headers[HeaderRequestID] = t.RequestID
+	}
+	if t.Traceparent != "" {
+		headers[HeaderTraceparent] = ChildTraceparent(t.Traceparent)
+	}
+	return headers
+}
+
+// LogValue выводит trace в логах группой request_id и trace_id: по ним
+// склеиваются логи одного удаления во всех сервисах.
+func (t Trace) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("request_id", t.RequestID),
+		slog.String("trace_id", t.TraceID()),
+	)
+}
+
+func (e Event) Trace() Trace {
+	return TraceFromHeaders(e.Headers)
+}
+
+// WithTrace возвращает событие с заголовками трассировки trace. Остальные
+// заголовки события не меняются.
+func (e Event) WithTrace(trace Trace) Event {
+	traceHeaders := trace.Headers()
+	if len(traceHeaders) == 0 {
+		return e
+	}
+
+	headers := make(map[string]string, len(e.Headers)+len(traceHeaders))
+	for key, value := range e.Headers {
+		headers[key] = value
+	}
+	for key, value := range traceHeaders {
+		headers[key] = value
+	}
+
+	e.Headers = headers
+	return e
+}
+
+// NewTraceparent начинает новую трассу — для запросов, пришедших без traceparent.
+func NewTraceparent() string {
+	return "00-" + randomHex(16) + "-" + randomHex(8) + "-01"
+}
+
+// ChildTraceparent продолжает трассу parent с новым parent-id. Некорректный
+// parent заменяется новой трассой, как требует спецификация.
+func ChildTraceparent(parent string) string {
+	traceID, flags, ok := parseTraceparent(parent)
+	if !ok {
+		return NewTraceparent()
+	}
+	return "00-" + traceID + "-" + randomHex(8) + "-" + flags
+}
+
+// parseTraceparent разбирает traceparent версии 00: version-traceid-parentid-flags.
+func parseTraceparent(value string) (traceID, flags string, ok bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return "", "", false
+	}
+	if !isHex(parts[1], 32) || !isHex(parts[2], 16) || !isHex(parts[3], 2) {
+		return "", "", false
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false
+	}
+	return parts[1], parts[3], true
+}
+
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(size int) string {
+	buf := make([]byte, size)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
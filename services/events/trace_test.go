@@ -0,0 +1,80 @@
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+const parentTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestChildTraceparentKeepsTrace(t *testing.T) {
+	child := ChildTraceparent(parentTraceparent)
+
+	traceID, flags, ok := parseTraceparent(child)
+	if !ok {
+		t.Fatalf("child %q is not a valid traceparent", child)
+	}
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || flags != "01" {
+		t.Fatalf("child %q does not continue the parent trace", child)
+	}
+	if child == parentTraceparent {
+		t.Fatal("child must get a new parent-id")
+	}
+}
+
+func TestChildTraceparentReplacesInvalidParent(t *testing.T) {
+	cases := []string{
+		"",
+		"garbage",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+	}
+
+	for _, parent := range cases {
+		if _, _, ok := parseTraceparent(ChildTraceparent(parent)); !ok {
+			t.Errorf("%q: child is not a valid traceparent", parent)
+		}
+	}
+}
+
+func TestTracePassesThroughEvents(t *testing.T) {
+	ctx := ContextWithTrace(context.Background(), Trace{RequestID: "req-1", Traceparent: parentTraceparent})
+
+	command := New(AuthUserDeleteRequested, "user-1", "saga-1", nil).WithTrace(TraceFromContext(ctx))
+	payload, err := Marshal(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received, err := Unmarshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reply := New(AuthUserDeleted, "user-1", "saga-1", nil).WithTrace(received.Trace())
+	if reply.Trace().RequestID != "req-1" {
+		t.Fatalf("request ID was lost: %+v", reply.Headers)
+	}
+	if reply.Trace().TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace ID was lost: %+v", reply.Headers)
+	}
+}
+
+func TestWithTraceKeepsOtherHeaders(t *testing.T) {
+	event := New(AuthUserDeleted, "user-1", "saga-1", nil)
+	event.Headers = map[string]string{"source": "auth-service"}
+
+	traced := event.WithTrace(Trace{RequestID: "req-1"})
+	if traced.Headers["source"] != "auth-service" || traced.Headers[HeaderRequestID] != "req-1" {
+		t.Fatalf("unexpected headers: %+v", traced.Headers)
+	}
+	if len(event.Headers) != 1 {
+		t.Fatal("WithTrace must not modify the original event")
+	}
+
+	if untraced := event.WithTrace(Trace{}); len(untraced.Headers) != 1 {
+		t.Fatalf("empty trace must not add headers: %+v", untraced.Headers)
+	}
+}
@@ -0,0 +1,153 @@
+package events
+
+type EventType string
+
+const (
+	UserDeletionRequested EventType = "UserDeletionRequested"
+	UserDeletionCompleted EventType = "UserDeletionCompleted"
+	UserDeletionRollback  EventType = "UserDeletionRollback"
+
+	AuthUserDeleteRequested EventType = "AuthUserDeleteRequested"
+	AuthUserDeleted         EventType = "AuthUserDeleted"
+	AuthUserDeleteFailed    EventType = "AuthUserDeleteFailed"
+	AuthUserDeleteRollback  EventType = "AuthUserDeleteRollback"
+	// Ответы на компенсацию: участник подтверждает откат или сообщает, что он не удался
+	AuthUserDeleteRollbackCompleted EventType = "AuthUserDeleteRollbackCompleted"
+	AuthUserDeleteRollbackFailed    EventType = "AuthUserDeleteRollbackFailed"
+
+	TeamUserDeleteRequested         EventType = "TeamUserDeleteRequested"
+	TeamUserDeleted                 EventType = "TeamUserDeleted"
+	TeamUserDeleteFailed            EventType = "TeamUserDeleteFailed"
+	TeamUserDeleteRollback          EventType = "TeamUserDeleteRollback"
+	TeamUserDeleteRollbackCompleted EventType = "TeamUserDeleteRollbackCompleted"
+	TeamUserDeleteRollbackFailed    EventType = "TeamUserDeleteRollbackFailed"
+
+	BoardUserDeleteRequested         EventType = "BoardUserDeleteRequested"
+	BoardUserDeleted                 EventType = "BoardUserDeleted"
+	BoardUserDeleteFailed            EventType = "BoardUserDeleteFailed"
+	BoardUserDeleteRollback          EventType = "BoardUserDeleteRollback"
+	BoardUserDeleteRollbackCompleted EventType = "BoardUserDeleteRollbackCompleted"
+	BoardUserDeleteRollbackFailed    EventType = "BoardUserDeleteRollbackFailed"
+
+	TaskUserDeleteRequested         EventType = "TaskUserDeleteRequested"
+	TaskUserDeleted                 EventType = "TaskUserDeleted"
+	TaskUserDeleteFailed            EventType = "TaskUserDeleteFailed"
+	TaskUserDeleteRollback          EventType = "TaskUserDeleteRollback"
+	TaskUserDeleteRollbackCompleted EventType = "TaskUserDeleteRollbackCompleted"
+	TaskUserDeleteRollbackFailed    EventType = "TaskUserDeleteRollbackFailed"
+
+	// Удаление команды: событие несёт team_id в data, user_id может быть пустым
+	TeamDeletionRequested EventType = "TeamDeletionRequested"
+	TeamDeletionCompleted EventType = "TeamDeletionCompleted"
+	TeamDeletionRollback  EventType = "TeamDeletionRollback"
+
+	TeamDeleteRequested         EventType = "TeamDeleteRequested"
+	TeamDeleted                 EventType = "TeamDeleted"
+	TeamDeleteFailed            EventType = "TeamDeleteFailed"
+	TeamDeleteRollback          EventType = "TeamDeleteRollback"
+	TeamDeleteRollbackCompleted EventType = "TeamDeleteRollbackCompleted"
+	TeamDeleteRollbackFailed    EventType = "TeamDeleteRollbackFailed"
+
+	BoardTeamDeleteRequested         EventType = "BoardTeamDeleteRequested"
+	BoardTeamDeleted                 EventType = "BoardTeamDeleted"
+	BoardTeamDeleteFailed            EventType = "BoardTeamDeleteFailed"
+	BoardTeamDeleteRollback          EventType = "BoardTeamDeleteRollback"
+	BoardTeamDeleteRollbackCompleted EventType = "BoardTeamDeleteRollbackCompleted"
+	BoardTeamDeleteRollbackFailed    EventType = "BoardTeamDeleteRollbackFailed"
+
+	TaskTeamDeleteRequested         EventType = "TaskTeamDeleteRequested"
+	TaskTeamDeleted                 EventType = "TaskTeamDeleted"
+	TaskTeamDeleteFailed            EventType = "TaskTeamDeleteFailed"
+	TaskTeamDeleteRollback          EventType = "TaskTeamDeleteRollback"
+	TaskTeamDeleteRollbackCompleted EventType = "TaskTeamDeleteRollbackCompleted"
+	TaskTeamDeleteRollbackFailed    EventType = "TaskTeamDeleteRollbackFailed"
+)
+
+const (
+	UserDeletionSagaTopic = "user-deletion-saga"
+	TeamDeletionSagaTopic = "team-deletion-saga"
+
+	AuthCommandsTopic = "auth-service-commands"
+	AuthEventsTopic   = "auth-service-events"
+
+	TeamCommandsTopic = "team-service-commands"
+	TeamEventsTopic   = "team-service-events"
+
+	BoardCommandsTopic = "board-service-commands"
+	BoardEventsTopic   = "board-service-events"
+
+	TaskCommandsTopic = "task-service-commands"
+	TaskEventsTopic   = "task-service-events"
+)
+
+// Types возвращает все типы событий контракта. Список должен совпадать с
+// перечислением type в JSON Schema — это проверяет тест модуля.
+func Types() []EventType {
+	return []EventType{
+		UserDeletionRequested,
+		UserDeletionCompleted,
+		UserDeletionRollback,
+
+		AuthUserDeleteRequested,
+		AuthUserDeleted,
+		AuthUserDeleteFailed,
+		AuthUserDeleteRollback,
+		AuthUserDeleteRollbackCompleted,
+		AuthUserDeleteRollbackFailed,
+
+		TeamUserDeleteRequested,
+		TeamUserDeleted,
+		TeamUserDeleteFailed,
+		TeamUserDeleteRollback,
+		TeamUserDeleteRollbackCompleted,
+		TeamUserDeleteRollbackFailed,
+
+		BoardUserDeleteRequested,
+		BoardUserDeleted,
+		BoardUserDeleteFailed,
+		BoardUserDeleteRollback,
+		BoardUserDeleteRollbackCompleted,
+		BoardUserDeleteRollbackFailed,
+
+		TaskUserDeleteRequested,
+		TaskUserDeleted,
+		TaskUserDeleteFailed,
+		TaskUserDeleteRollback,
+		TaskUserDeleteRollbackCompleted,
+		TaskUserDeleteRollbackFailed,
+
+		TeamDeletionRequested,
+		TeamDeletionCompleted,
+		TeamDeletionRollback,
+
+		TeamDeleteRequested,
+		TeamDeleted,
+		TeamDeleteFailed,
+		TeamDeleteRollback,
+		TeamDeleteRollbackCompleted,
+		TeamDeleteRollbackFailed,
+
+		BoardTeamDeleteRequested,
+		BoardTeamDeleted,
+		BoardTeamDeleteFailed,
+		BoardTeamDeleteRollback,
+		BoardTeamDeleteRollbackCompleted,
+		BoardTeamDeleteRollbackFailed,
+
+		TaskTeamDeleteRequested,
+		TaskTeamDeleted,
+		TaskTeamDeleteFailed,
+		TaskTeamDeleteRollback,
+		TaskTeamDeleteRollbackCompleted,
+		TaskTeamDeleteRollbackFailed,
+	}
+}
+
+func (t EventType) IsKnown() bool {
+	for _, known := range Types() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+// Package dlq — повтор обработки сообщений Kafka и перекладывание
+// необработанных в <topic>.dlq, общие для консьюмеров всех сервисов.
+package dlq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+const TopicSuffix = ".dlq"
+
+// Заголовки, которые DLQ добавляет к сообщению
+const (
+	HeaderError             = "dlq-error"
+	HeaderOriginalTopic     = "dlq-original-topic"
+	HeaderOriginalPartition = "dlq-original-partition"
+	HeaderOriginalOffset    = "dlq-original-offset"
+	HeaderAttempts          = "dlq-attempts"
+	HeaderConsumer          = "dlq-consumer"
+	HeaderFailedAt          = "dlq-failed-at"
+)
+
+// permanentError — ошибка, которую бессмысленно повторять (например, битый JSON).
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку как неповторяемую: сообщение сразу уходит в DLQ.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// Producer синхронно пишет сообщение в Kafka.
+type Producer interface {
+	SendMessage(message *sarama.ProducerMessage) error
+}
+
+type Config struct {
+	// Сколько раз обработать сообщение, прежде чем отправить его в <topic>.dlq
+	MaxAttempts int
+	Backoff     time.Duration
+	// Вызывается после записи сообщения в DLQ, например для метрик
+	OnDeadLetter func(message *sarama.ConsumerMessage)
+}
+
+// Queue повторяет обработку сообщения на месте ограниченное число раз,
+// а затем перекладывает его в <topic>.dlq, чтобы не блокировать партицию.
+type Queue struct {
+	producer Producer
+	consumer string
+	cfg      Config
+	log      *slog.Logger
+}
+
+// New создаёт очередь для консьюмера consumer: его имя попадает в заголовок
+// dlq-consumer.
+func New(producer Producer, consumer string, cfg Config, log *slog.Logger) *Queue {
+	return &Queue{
+		producer: producer,
+		consumer: consumer,
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// Process вызывает handle до MaxAttempts раз. Ошибка возвращается, только
+// если сообщение не удалось ни обработать, ни записать в DLQ — в этом случае
+// offset коммитить нельзя.
+func (q *Queue) Process(ctx context.Context, message *sarama.ConsumerMessage, handle func(context.Context, *sarama.ConsumerMessage) error) error {
+	maxAttempts := max(q.cfg.MaxAttempts, 1)
+	backoff := q.cfg.Backoff
+
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		err = handle(ctx, message)
+		if err == nil {
+			return nil
+		}
+		if isPermanent(err) || attempt >= maxAttempts {
+			break
+		}
+
+		q.log.Warn("Message processing failed, retrying",
+			"topic", message.Topic,
+			"partition", message.Partition,
+			"offset", message.Offset,
+			"attempt", attempt,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return q.publish(message, attempt, err)
+}
+
+func (q *Queue) publish(message *sarama.ConsumerMessage, attempts int, cause error) error {
+	dlqTopic := message.Topic + TopicSuffix
+
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+7)
+	for _, header := range message.Headers {
+		if header == nil || strings.HasPrefix(string(header.Key), "dlq-") {
+			continue
+		}
+		headers = append(headers, *header)
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(HeaderError), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(HeaderOriginalTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderOriginalPartition), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(HeaderOriginalOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(HeaderAttempts), Value: []byte(strconv.Itoa(attempts))},
+		sarama.RecordHeader{Key: []byte(HeaderConsumer), Value: []byte(q.consumer)},
+		sarama.RecordHeader{Key: []byte(HeaderFailedAt), Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	err := q.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   dlqTopic,
+		Key:     sarama.ByteEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to dead letter topic %s: %w", dlqTopic, err)
+	}
+
+	if q.cfg.OnDeadLetter != nil {
+		q.cfg.OnDeadLetter(message)
+	}
+	q.log.Error("Message moved to dead letter topic",
+		"topic", message.Topic,
+		"dlq_topic", dlqTopic,
+		"partition", message.Partition,
+		"offset", message.Offset,
+		"attempts", attempts,
+		"error", cause,
+	)
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+type producer struct {
+	sent []*sarama.ProducerMessage
+}
+
+func (p *producer) SendMessage(message *sarama.ProducerMessage) error {
+	p.sent = append(p.sent, message)
+	return nil
+}
+
+func headerValues(message *sarama.ProducerMessage) map[string][]string {
+	values := make(map[string][]string)
+	for _, header := range message.Headers {
+		values[string(header.Key)] = append(values[string(header.Key)], string(header.Value))
+	}
+	return values
+}
+
+func TestProcessMovesMessageToDeadLetterTopic(t *testing.T) {
+	p := &producer{}
+	var deadLetters int
+	queue := New(p, "board-service", Config{
+		MaxAttempts:  3,
+		OnDeadLetter: func(*sarama.ConsumerMessage) { deadLetters++ },
+	}, slog.New(slog.DiscardHandler))
+
+	message := &sarama.ConsumerMessage{
+		Topic:     "board.commands",
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("user-1"),
+		Value:     []byte(`{"id":"event-1"}`),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("traceparent"), Value: []byte("00-trace-span-01")},
+			// Сообщение уже побывало в DLQ и было переиграно
+			{Key: []byte(HeaderError), Value: []byte("old error")},
+			{Key: []byte(HeaderAttempts), Value: []byte("7")},
+		},
+	}
+
+	var attempts int
+	err := queue.Process(context.Background(), message, func(context.Context, *sarama.ConsumerMessage) error {
+		attempts++
+		return errors.New("database is down")
+	})
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if deadLetters != 1 {
+		t.Errorf("expected OnDeadLetter to be called once, got %d", deadLetters)
+	}
+	if len(p.sent) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(p.sent))
+	}
+
+	sent := p.sent[0]
+	if sent.Topic != "board.commands"+TopicSuffix {
+		t.Errorf("dead letter topic = %s", sent.Topic)
+	}
+	if key, _ := sent.Key.Encode(); string(key) != "user-1" {
+		t.Errorf("dead letter key = %s", key)
+	}
+	if value, _ := sent.Value.Encode(); string(value) != `{"id":"event-1"}` {
+		t.Errorf("dead letter value = %s", value)
+	}
+
+	headers := headerValues(sent)
+	want := map[string]string{
+		"traceparent":           "00-trace-span-01",
+		HeaderError:             "database is down",
+		HeaderOriginalTopic:     "board.commands",
+		HeaderOriginalPartition: "2",
+		HeaderOriginalOffset:    "42",
+		HeaderAttempts:          "3",
+		HeaderConsumer:          "board-service",
+	}
+	for key, value := range want {
+		if got := headers[key]; len(got) != 1 || got[0] != value {
+			t.Errorf("header %s = %v, want [%s]", key, got, value)
+		}
+	}
+
+	failedAt := headers[HeaderFailedAt]
+	if len(failedAt) != 1 {
+		t.Fatalf("header %s = %v", HeaderFailedAt, failedAt)
+	}
+	if _, err := time.Parse(time.RFC3339, failedAt[0]); err != nil {
+		t.Errorf("header %s is not RFC 3339: %v", HeaderFailedAt, err)
+	}
+}
+
+func TestProcessDoesNotRetryPermanentErrors(t *testing.T) {
+	p := &producer{}
+	queue := New(p, "task-service", Config{MaxAttempts: 5}, slog.New(slog.DiscardHandler))
+
+	var attempts int
+	err := queue.Process(context.Background(), &sarama.ConsumerMessage{Topic: "task.commands"}, func(context.Context, *sarama.ConsumerMessage) error {
+		attempts++
+		return Permanent(errors.New("invalid payload"))
+	})
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+
+	if attempts != 1 {
+		t.Errorf("permanent error was retried: %d attempts", attempts)
+	}
+	if len(p.sent) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(p.sent))
+	}
+	if got := headerValues(p.sent[0])[HeaderAttempts]; len(got) != 1 || got[0] != "1" {
+		t.Errorf("header %s = %v, want [1]", HeaderAttempts, got)
+	}
+}
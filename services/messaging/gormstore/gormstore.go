@@ -0,0 +1,157 @@
+// Package gormstore хранит outbox и inbox через GORM. Сервисы на GORM
+// создают таблицы миграцией моделей OutboxEvent и ProcessedEvent.
+package gormstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"shiroyama/messaging/outbox"
+)
+
+type OutboxEvent struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement;index:idx_outbox_events_unsent,priority:2,where:sent_at IS NULL"`
+	Source    string     `gorm:"size:64;not null;index:idx_outbox_events_unsent,priority:1,where:sent_at IS NULL"`
+	Topic     string     `gorm:"size:255;not null"`
+	EventKey  string     `gorm:"size:255;not null;default:''"`
+	Payload   []byte     `gorm:"type:jsonb;not null"`
+	Headers   []byte     `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time  `gorm:"not null"`
+	SentAt    *time.Time `gorm:"index"`
+}
+
+type ProcessedEvent struct {
+	EventID     string    `gorm:"primaryKey;size:64"`
+	Consumer    string    `gorm:"primaryKey;size:64"`
+	ProcessedAt time.Time `gorm:"not null;index"`
+}
+
+type txRunner struct {
+	db *gorm.DB
+}
+
+func (r txRunner) InTx(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
+// Outbox — таблица outbox_events.
+type Outbox struct {
+	txRunner
+}
+
+func NewOutbox(db *gorm.DB) *Outbox {
+	return &Outbox{txRunner{db: db}}
+}
+
+func (s *Outbox) Insert(ctx context.Context, tx *gorm.DB, message outbox.Message) error {
+	return tx.WithContext(ctx).Create(&OutboxEvent{
+		Source:    message.Source,
+		Topic:     message.Topic,
+		EventKey:  message.Key,
+		Payload:   message.Payload,
+		Headers:   message.Headers,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+// Relay блокирует строки через SKIP LOCKED, поэтому несколько реплик
+// сервиса не отправят одно событие одновременно.
+func (s *Outbox) Relay(ctx context.Context, source string, limit int, send func([]outbox.Message) int) (int, error) {
+	var sent int
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var batch []OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("source = ? AND sent_at IS NULL", source).
+			Order("id").
+			Limit(limit).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+
+		messages := make([]outbox.Message, 0, len(batch))
+		for _, event := range batch {
+			messages = append(messages, outbox.Message{
+				ID:      int64(event.ID),
+				Source:  event.Source,
+				Topic:   event.Topic,
+				Key:     event.EventKey,
+				Payload: event.Payload,
+				Headers: event.Headers,
+			})
+		}
+
+		sent = send(messages)
+		if sent == 0 {
+			return nil
+		}
+
+		sentIDs := make([]uint64, 0, sent)
+		for _, event := range batch[:sent] {
+			sentIDs = append(sentIDs, event.ID)
+		}
+
+		return tx.Model(&OutboxEvent{}).
+			Where("id IN ?", sentIDs).
+			Update("sent_at", time.Now()).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return sent, nil
+}
+
+func (s *Outbox) Purge(ctx context.Context, source string, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("source = ? AND sent_at < ?", source, before).
+		Delete(&OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// Inbox — таблица processed_events.
+type Inbox struct {
+	txRunner
+}
+
+func NewInbox(db *gorm.DB) *Inbox {
+	return &Inbox{txRunner{db: db}}
+}
+
+func (s *Inbox) Exists(ctx context.Context, consumer, eventID string) (bool, error) {
+	err := s.db.WithContext(ctx).
+		Where("event_id = ? AND consumer = ?", eventID, consumer).
+		Take(&ProcessedEvent{}).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Inbox) Insert(ctx context.Context, tx *gorm.DB, consumer, eventID string) (bool, error) {
+	result := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ProcessedEvent{
+			EventID:     eventID,
+			Consumer:    consumer,
+			ProcessedAt: time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (s *Inbox) Purge(ctx context.Context, consumer string, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("consumer = ? AND processed_at < ?", consumer, before).
+		Delete(&ProcessedEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,62 @@
+package gormstore
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"shiroyama/messaging/inbox"
+)
+
+func TestInboxDetectsDuplicate(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("open sqlmock: %v", err)
+	}
+	defer conn.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	store := NewInbox(db)
+	in := inbox.New(store, "task-service", time.Hour, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	// ON CONFLICT DO NOTHING не вставляет строку второй раз
+	for _, rowsAffected := range []int64{1, 0} {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "processed_events"`) + ".*" + regexp.QuoteMeta("ON CONFLICT DO NOTHING")).
+			WithArgs("event-1", "task-service", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		mock.ExpectCommit()
+	}
+
+	var fresh []bool
+	for range 2 {
+		err := store.InTx(ctx, func(tx *gorm.DB) error {
+			inserted, err := in.MarkProcessedTx(ctx, tx, "event-1")
+			fresh = append(fresh, inserted)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("mark processed: %v", err)
+		}
+	}
+
+	if !fresh[0] || fresh[1] {
+		t.Fatalf("expected only the first delivery to be fresh, got %v", fresh)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,111 @@
+// Package inbox запоминает ID обработанных событий в таблице
+// processed_events, чтобы повторная доставка из Kafka не выполняла
+// обработку второй раз. Хранилище подключается адаптером под database/sql
+// или GORM.
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Store — таблица processed_events в базе сервиса; Tx — тип его транзакции.
+type Store[Tx any] interface {
+	Exists(ctx context.Context, consumer, eventID string) (bool, error)
+	// Insert записывает событие в транзакции tx. Возвращает false, если
+	// запись уже была.
+	Insert(ctx context.Context, tx Tx, consumer, eventID string) (bool, error)
+	// InTx выполняет fn в новой транзакции.
+	InTx(ctx context.Context, fn func(tx Tx) error) error
+	// Purge удаляет события consumer, обработанные раньше before.
+	Purge(ctx context.Context, consumer string, before time.Time) (int64, error)
+}
+
+type Inbox[Tx any] struct {
+	store     Store[Tx]
+	consumer  string
+	retention time.Duration
+	logger    *slog.Logger
+}
+
+func New[Tx any](store Store[Tx], consumer string, retention time.Duration, logger *slog.Logger) *Inbox[Tx] {
+	return &Inbox[Tx]{
+		store:     store,
+		consumer:  consumer,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+func (i *Inbox[Tx]) IsProcessed(ctx context.Context, eventID string) (bool, error) {
+	const op = "Inbox.IsProcessed"
+
+	if eventID == "" {
+		return false, nil
+	}
+
+	exists, err := i.store.Exists(ctx, i.consumer, eventID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists, nil
+}
+
+func (i *Inbox[Tx]) MarkProcessed(ctx context.Context, eventID string) error {
+	return i.store.InTx(ctx, func(tx Tx) error {
+		_, err := i.MarkProcessedTx(ctx, tx, eventID)
+		return err
+	})
+}
+
+// MarkProcessedTx записывает событие в той же транзакции, что и изменения
+// данных. Возвращает false, если событие уже было обработано.
+func (i *Inbox[Tx]) MarkProcessedTx(ctx context.Context, tx Tx, eventID string) (bool, error) {
+	const op = "Inbox.MarkProcessedTx"
+
+	// События без ID дедуплицировать не по чему
+	if eventID == "" {
+		return true, nil
+	}
+
+	inserted, err := i.store.Insert(ctx, tx, i.consumer, eventID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return inserted, nil
+}
+
+func (i *Inbox[Tx]) Purge(ctx context.Context) error {
+	const op = "Inbox.Purge"
+
+	purged, err := i.store.Purge(ctx, i.consumer, time.Now().Add(-i.retention))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if purged > 0 {
+		i.logger.Info("Purged processed events", "count", purged, "op", op)
+	}
+
+	return nil
+}
+
+func (i *Inbox[Tx]) StartCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := i.Purge(ctx); err != nil {
+				i.logger.Error("Failed to purge processed events", "error", err)
+			}
+		}
+	}
+}
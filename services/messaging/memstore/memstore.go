@@ -0,0 +1,162 @@
+// Package memstore хранит outbox и inbox в памяти процесса — для тестов,
+// которые гоняют обработчики сервисов без базы. Транзакция откатывает обе
+// таблицы, если fn вернула ошибку, но транзакции не изолированы друг от
+// друга: события должны обрабатываться по одному, как это делает bus.Broker.
+package memstore
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"shiroyama/messaging/outbox"
+)
+
+type outboxRow struct {
+	message outbox.Message
+	sentAt  time.Time
+}
+
+type inboxKey struct {
+	consumer string
+	eventID  string
+}
+
+// DB — общая база outbox и inbox. Tx — тип транзакции сервиса: в fn
+// передаётся его нулевое значение, хранилищу он не нужен.
+type DB[Tx any] struct {
+	mu        sync.Mutex
+	outbox    []outboxRow
+	lastID    int64
+	processed map[inboxKey]time.Time
+}
+
+func NewDB[Tx any]() *DB[Tx] {
+	return &DB[Tx]{processed: make(map[inboxKey]time.Time)}
+}
+
+// InTx выполняет fn и откатывает всё, что она записала, если fn вернула ошибку.
+func (db *DB[Tx]) InTx(ctx context.Context, fn func(tx Tx) error) error {
+	db.mu.Lock()
+	outboxRows := slices.Clone(db.outbox)
+	processed := maps.Clone(db.processed)
+	db.mu.Unlock()
+
+	var tx Tx
+	if err := fn(tx); err != nil {
+		db.mu.Lock()
+		db.outbox = outboxRows
+		db.processed = processed
+		db.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Outbox — таблица outbox_events.
+type Outbox[Tx any] struct {
+	*DB[Tx]
+}
+
+func NewOutbox[Tx any](db *DB[Tx]) *Outbox[Tx] {
+	return &Outbox[Tx]{db}
+}
+
+func (s *Outbox[Tx]) Insert(ctx context.Context, tx Tx, message outbox.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	message.ID = s.lastID
+	s.outbox = append(s.outbox, outboxRow{message: message})
+	return nil
+}
+
+func (s *Outbox[Tx]) Relay(ctx context.Context, source string, limit int, send func([]outbox.Message) int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		pending  []int
+		messages []outbox.Message
+	)
+	for i, row := range s.outbox {
+		if len(messages) == limit {
+			break
+		}
+		if row.message.Source == source && row.sentAt.IsZero() {
+			pending = append(pending, i)
+			messages = append(messages, row.message)
+		}
+	}
+
+	sent := send(messages)
+
+	now := time.Now()
+	for _, i := range pending[:sent] {
+		s.outbox[i].sentAt = now
+	}
+	return sent, nil
+}
+
+func (s *Outbox[Tx]) Purge(ctx context.Context, source string, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	s.outbox = slices.DeleteFunc(s.outbox, func(row outboxRow) bool {
+		stale := row.message.Source == source && !row.sentAt.IsZero() && row.sentAt.Before(before)
+		if stale {
+			purged++
+		}
+		return stale
+	})
+	return purged, nil
+}
+
+// Inbox — таблица processed_events.
+type Inbox[Tx any] struct {
+	*DB[Tx]
+}
+
+func NewInbox[Tx any](db *DB[Tx]) *Inbox[Tx] {
+	return &Inbox[Tx]{db}
+}
+
+func (s *Inbox[Tx]) Exists(ctx context.Context, consumer, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.processed[inboxKey{consumer: consumer, eventID: eventID}]
+	return exists, nil
+}
+
+func (s *Inbox[Tx]) Insert(ctx context.Context, tx Tx, consumer, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := inboxKey{consumer: consumer, eventID: eventID}
+	if _, exists := s.processed[key]; exists {
+		return false, nil
+	}
+
+	s.processed[key] = time.Now()
+	return true, nil
+}
+
+func (s *Inbox[Tx]) Purge(ctx context.Context, consumer string, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for key, processedAt := range s.processed {
+		if key.consumer == consumer && processedAt.Before(before) {
+			delete(s.processed, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
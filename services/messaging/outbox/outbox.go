@@ -0,0 +1,209 @@
+// Package outbox — transactional outbox сервисов: событие пишется в таблицу
+// outbox_events в той же транзакции, что и изменения данных, а relay
+// публикует его в Kafka. Так событие не теряется, если сервис упадёт между
+// коммитом и отправкой. Хранилище подключается адаптером под database/sql,
+// GORM или memstore в тестах.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"shiroyama/events"
+	"shiroyama/events/bus"
+)
+
+// Message — строка outbox_events. Key и Headers дублируют то, что издатель
+// выводит из события, чтобы запись можно было разобрать без декодирования.
+type Message struct {
+	ID      int64
+	Source  string
+	Topic   string
+	Key     string
+	Payload []byte
+	Headers []byte
+}
+
+// Store — таблица outbox_events в базе сервиса; Tx — тип его транзакции.
+type Store[Tx any] interface {
+	// Insert записывает сообщение в транзакции tx.
+	Insert(ctx context.Context, tx Tx, message Message) error
+	// InTx выполняет fn в новой транзакции.
+	InTx(ctx context.Context, fn func(tx Tx) error) error
+	// Relay блокирует до limit неотправленных сообщений source так, чтобы
+	// другие реплики их пропустили, передаёт их в send и помечает
+	// отправленными первые n, где n — результат send.
+	Relay(ctx context.Context, source string, limit int, send func([]Message) int) (int, error)
+	// Purge удаляет сообщения source, отправленные раньше before.
+	Purge(ctx context.Context, source string, before time.Time) (int64, error)
+}
+
+type Config struct {
+	PollInterval    time.Duration
+	BatchSize       int
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+type Outbox[Tx any] struct {
+	store     Store[Tx]
+	source    string
+	publisher bus.Publisher
+	cfg       Config
+	logger    *slog.Logger
+}
+
+// New создаёт outbox сервиса source: relay отправляет только его события,
+// даже если таблица общая.
+func New[Tx any](store Store[Tx], source string, publisher bus.Publisher, cfg Config, logger *slog.Logger) *Outbox[Tx] {
+	return &Outbox[Tx]{
+		store:     store,
+		source:    source,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// EnqueueTx записывает событие в outbox в транзакции tx. Событие уйдёт
+// в Kafka только после коммита.
+func (o *Outbox[Tx]) EnqueueTx(ctx context.Context, tx Tx, topic string, event events.Event) error {
+	const op = "Outbox.EnqueueTx"
+
+	// Событие, порождённое обработкой запроса или другого события, наследует
+	// его трассировку, поэтому её не нужно передавать в каждый events.New
+	if event.Trace().IsZero() {
+		event = event.WithTrace(events.TraceFromContext(ctx))
+	}
+
+	payload, err := events.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	kafkaHeaders := map[string]string{
+		"event_type": string(event.Type),
+		"saga_id":    event.SagaID,
+	}
+	for key, value := range event.Headers {
+		kafkaHeaders[key] = value
+	}
+
+	headers, err := json.Marshal(kafkaHeaders)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = o.store.Insert(ctx, tx, Message{
+		Source:  o.source,
+		Topic:   topic,
+		Key:     event.Key(),
+		Payload: payload,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Enqueue — для событий, которые не сопровождают изменения данных.
+func (o *Outbox[Tx]) Enqueue(ctx context.Context, topic string, event events.Event) error {
+	return o.store.InTx(ctx, func(tx Tx) error {
+		return o.EnqueueTx(ctx, tx, topic, event)
+	})
+}
+
+// InTx выполняет fn в транзакции хранилища outbox, чтобы записать событие
+// вместе с другими строками той же базы, например отметкой inbox.
+func (o *Outbox[Tx]) InTx(ctx context.Context, fn func(tx Tx) error) error {
+	return o.store.InTx(ctx, fn)
+}
+
+// StartRelay публикует неотправленные события, пока не отменён ctx, и
+// периодически удаляет отправленные старше Retention.
+func (o *Outbox[Tx]) StartRelay(ctx context.Context) {
+	ticker := time.NewTicker(o.cfg.PollInterval)
+	defer ticker.Stop()
+
+	cleanupTicker := time.NewTicker(o.cfg.CleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := o.Flush(ctx); err != nil {
+				o.logger.Error("Failed to relay outbox events", "error", err)
+			}
+		case <-cleanupTicker.C:
+			if err := o.Purge(ctx); err != nil {
+				o.logger.Error("Failed to purge outbox events", "error", err)
+			}
+		}
+	}
+}
+
+// Flush отправляет неотправленные события пачками, пока они не кончатся.
+func (o *Outbox[Tx]) Flush(ctx context.Context) error {
+	for {
+		sent, err := o.relay(ctx)
+		if err != nil {
+			return err
+		}
+		if sent < o.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// relay отправляет одну пачку. Отправленные до ошибки события помечаются,
+// остальные уйдут в следующий раз.
+func (o *Outbox[Tx]) relay(ctx context.Context) (int, error) {
+	const op = "Outbox.relay"
+
+	var sendErr error
+	sent, err := o.store.Relay(ctx, o.source, o.cfg.BatchSize, func(messages []Message) int {
+		// Останавливаемся на первой ошибке, чтобы не нарушить порядок событий
+		for i, message := range messages {
+			var event events.Event
+			event, sendErr = events.Unmarshal(message.Payload)
+			if sendErr == nil {
+				sendErr = o.publisher.PublishEvent(message.Topic, event)
+			}
+			if sendErr != nil {
+				return i
+			}
+		}
+		return len(messages)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if sendErr != nil {
+		return sent, fmt.Errorf("%s: %w", op, sendErr)
+	}
+
+	return sent, nil
+}
+
+func (o *Outbox[Tx]) Purge(ctx context.Context) error {
+	const op = "Outbox.Purge"
+
+	purged, err := o.store.Purge(ctx, o.source, time.Now().Add(-o.cfg.Retention))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if purged > 0 {
+		o.logger.Info("Purged outbox events", "count", purged, "op", op)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+// Package sqlstore хранит outbox и inbox через database/sql в PostgreSQL.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"shiroyama/messaging/outbox"
+)
+
+type txRunner struct {
+	db *sql.DB
+}
+
+func (r txRunner) InTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Outbox — таблица outbox_events.
+type Outbox struct {
+	txRunner
+}
+
+func NewOutbox(db *sql.DB) *Outbox {
+	return &Outbox{txRunner{db: db}}
+}
+
+func (s *Outbox) Insert(ctx context.Context, tx *sql.Tx, message outbox.Message) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO outbox_events (source, topic, event_key, payload, headers, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())`,
+		message.Source, message.Topic, message.Key, message.Payload, message.Headers,
+	)
+	return err
+}
+
+// Relay блокирует строки через SKIP LOCKED, поэтому несколько реплик
+// сервиса не отправят одно событие одновременно.
+func (s *Outbox) Relay(ctx context.Context, source string, limit int, send func([]outbox.Message) int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, source, topic, event_key, payload, headers
+		FROM outbox_events
+		WHERE source = $1 AND sent_at IS NULL
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`,
+		source, limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var messages []outbox.Message
+	for rows.Next() {
+		var message outbox.Message
+		if err := rows.Scan(&message.ID, &message.Source, &message.Topic, &message.Key, &message.Payload, &message.Headers); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		messages = append(messages, message)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := send(messages)
+	if sent == 0 {
+		return 0, nil
+	}
+
+	sentIDs := make([]int64, 0, sent)
+	for _, message := range messages[:sent] {
+		sentIDs = append(sentIDs, message.ID)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE outbox_events SET sent_at = NOW() WHERE id = ANY($1)`, pq.Array(sentIDs),
+	); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return sent, nil
+}
+
+func (s *Outbox) Purge(ctx context.Context, source string, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM outbox_events WHERE source = $1 AND sent_at < $2`,
+		source, before,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Inbox — таблица processed_events.
+type Inbox struct {
+	txRunner
+}
+
+func NewInbox(db *sql.DB) *Inbox {
+	return &Inbox{txRunner{db: db}}
+}
+
+func (s *Inbox) Exists(ctx context.Context, consumer, eventID string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM processed_events WHERE event_id = $1 AND consumer = $2)`,
+		eventID, consumer,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (s *Inbox) Insert(ctx context.Context, tx *sql.Tx, consumer, eventID string) (bool, error) {
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO processed_events (event_id, consumer, processed_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (event_id, consumer) DO NOTHING`,
+		eventID, consumer,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (s *Inbox) Purge(ctx context.Context, consumer string, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM processed_events WHERE consumer = $1 AND processed_at < $2`,
+		consumer, before,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
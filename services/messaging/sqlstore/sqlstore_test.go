@@ -0,0 +1,203 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"shiroyama/events"
+	"shiroyama/messaging/inbox"
+	"shiroyama/messaging/outbox"
+)
+
+var outboxColumns = []string{"id", "source", "topic", "event_key", "payload", "headers"}
+
+// publisher запоминает отправленные события и отказывает на событии с
+// номером failAt (с единицы).
+type publisher struct {
+	failAt    int
+	calls     int
+	published []events.Event
+}
+
+func (p *publisher) PublishEvent(topic string, event events.Event) error {
+	p.calls++
+	if p.calls == p.failAt {
+		return errors.New("kafka is down")
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+// captured запоминает значение аргумента запроса.
+type captured struct {
+	value driver.Value
+}
+
+func (c *captured) Match(v driver.Value) bool {
+	c.value = v
+	return true
+}
+
+func newOutbox(t *testing.T, p *publisher) (*outbox.Outbox[*sql.Tx], sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("open sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return outbox.New(NewOutbox(db), "auth-service", p, outbox.Config{BatchSize: 10}, slog.New(slog.DiscardHandler)), mock
+}
+
+func marshal(t *testing.T, event events.Event) []byte {
+	t.Helper()
+
+	payload, err := events.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestOutboxRelaysEnqueuedEvent(t *testing.T) {
+	p := &publisher{}
+	o, mock := newOutbox(t, p)
+	ctx := context.Background()
+	event := events.New(events.AuthUserDeleted, "user-1", "saga-1", nil)
+
+	payload, headers := &captured{}, &captured{}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox_events")).
+		WithArgs("auth-service", events.AuthEventsTopic, event.Key(), payload, headers).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := o.Enqueue(ctx, events.AuthEventsTopic, event); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	// Relay забирает строку под блокировкой, публикует и помечает её в той же
+	// транзакции
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs("auth-service", 10).
+		WillReturnRows(sqlmock.NewRows(outboxColumns).
+			AddRow(1, "auth-service", events.AuthEventsTopic, event.Key(), payload.value, headers.value))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events SET sent_at = NOW()")).
+		WithArgs("{1}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := o.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if len(p.published) != 1 || p.published[0].ID != event.ID || p.published[0].Type != events.AuthUserDeleted {
+		t.Fatalf("published %+v, want %s", p.published, event.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxMarksOnlyPublishedEvents(t *testing.T) {
+	p := &publisher{failAt: 3}
+	o, mock := newOutbox(t, p)
+	ctx := context.Background()
+
+	batch := make([]events.Event, 3)
+	rows := sqlmock.NewRows(outboxColumns)
+	for i := range batch {
+		batch[i] = events.New(events.AuthUserDeleted, "user-1", "saga-1", nil)
+		rows.AddRow(i+1, "auth-service", events.AuthEventsTopic, "user-1", marshal(t, batch[i]), []byte("{}"))
+	}
+
+	// Kafka отказала на третьем событии: помечаются первые два, третье
+	// остаётся в таблице
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs("auth-service", 10).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events SET sent_at = NOW()")).
+		WithArgs("{1,2}").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	if err := o.Flush(ctx); err == nil {
+		t.Fatal("expected the publish error")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs("auth-service", 10).
+		WillReturnRows(sqlmock.NewRows(outboxColumns).
+			AddRow(3, "auth-service", events.AuthEventsTopic, "user-1", marshal(t, batch[2]), []byte("{}")))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events SET sent_at = NOW()")).
+		WithArgs("{3}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := o.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if len(p.published) != 3 {
+		t.Fatalf("expected 3 published events, got %d", len(p.published))
+	}
+	for i, event := range p.published {
+		if event.ID != batch[i].ID {
+			t.Fatalf("event %d published out of order", i)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInboxDetectsDuplicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("open sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	store := NewInbox(db)
+	in := inbox.New(store, "auth-service", time.Hour, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	// ON CONFLICT DO NOTHING не вставляет строку второй раз
+	for _, rowsAffected := range []int64{1, 0} {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (event_id, consumer) DO NOTHING")).
+			WithArgs("event-1", "auth-service").
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		mock.ExpectCommit()
+	}
+
+	var fresh []bool
+	for range 2 {
+		err := store.InTx(ctx, func(tx *sql.Tx) error {
+			inserted, err := in.MarkProcessedTx(ctx, tx, "event-1")
+			fresh = append(fresh, inserted)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("mark processed: %v", err)
+		}
+	}
+
+	if !fresh[0] || fresh[1] {
+		t.Fatalf("expected only the first delivery to be fresh, got %v", fresh)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
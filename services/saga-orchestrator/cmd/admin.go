@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"saga-orchestrator/internal/events"
+	"saga-orchestrator/internal/saga"
+	"saga-orchestrator/internal/storage"
+)
+
+type sagaResponse struct {
+	ID                   string                            `json:"id"`
+	Type                 string                            `json:"type"`
+	UserID               string                            `json:"user_id"`
+	Status               events.SagaStatus                 `json:"status"`
+	CurrentStep          string                            `json:"current_step"`
+	FailedStep           string                            `json:"failed_step,omitempty"`
+	CompletedSteps       []string                          `json:"completed_steps"`
+	StepResults          map[string]map[string]interface{} `json:"step_results,omitempty"`
+	RetryCount           int                               `json:"retry_count"`
+	StepRetries          map[string]int                    `json:"step_retries,omitempty"`
+	ScheduledStep        string                            `json:"scheduled_step,omitempty"`
+	NextRetryAt          *time.Time                        `json:"next_retry_at,omitempty"`
+	StepDeadline         *time.Time                        `json:"step_deadline,omitempty"`
+	CreatedAt            time.Time                         `json:"created_at"`
+	UpdatedAt            time.Time                         `json:"updated_at"`
+	ExpiresAt            time.Time                         `json:"expires_at"`
+	Metadata             map[string]string                 `json:"metadata,omitempty"`
+	PendingCompensations []string                          `json:"pending_compensations,omitempty"`
+	CompensationRetries  map[string]int                    `json:"compensation_retries,omitempty"`
+}
+
+type stepRecordResponse struct {
+	Step       string            `json:"step"`
+	Status     events.SagaStatus `json:"status"`
+	FailedStep string            `json:"failed_step,omitempty"`
+	RetryCount int               `json:"retry_count"`
+	RecordedAt time.Time         `json:"recorded_at"`
+}
+
+type retryRequest struct {
+	Step string `json:"step"`
+}
+
+type resolveRequest struct {
+	Reason string `json:"reason"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// SagaHistory — хранилище, которое ведёт историю переходов саг. Из
+// хранилищ её ведёт только PostgresStorage.
+type SagaHistory interface {
+	GetSagaHistory(ctx context.Context, sagaID string) ([]storage.StepRecord, error)
+}
+
+type AdminHandler struct {
+	storage      saga.Storage
+	history      SagaHistory
+	orchestrator *saga.Orchestrator
+	token        string
+	log          *slog.Logger
+}
+
+func NewAdminHandler(sagaStorage saga.Storage, orchestrator *saga.Orchestrator, token string, log *slog.Logger) *AdminHandler {
+	history, _ := sagaStorage.(SagaHistory)
+
+	return &AdminHandler{
+		storage:      sagaStorage,
+		history:      history,
+		orchestrator: orchestrator,
+		token:        token,
+		log:          log,
+	}
+}
+
+func (ah *AdminHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /admin/sagas", ah.authorize(ah.listSagas))
+	mux.Handle("GET /admin/sagas/{id}", ah.authorize(ah.getSaga))
+	mux.Handle("GET /admin/sagas/{id}/history", ah.authorize(ah.getSagaHistory))
+	mux.Handle("POST /admin/sagas/{id}/retry", ah.authorize(ah.retrySaga))
+	mux.Handle("POST /admin/sagas/{id}/compensate", ah.authorize(ah.compensateSaga))
+	mux.Handle("POST /admin/sagas/{id}/resolve", ah.authorize(ah.resolveSaga))
+}
+
+func (ah *AdminHandler) authorize(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(ah.token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+func (ah *AdminHandler) listSagas(w http.ResponseWriter, r *http.Request) {
+	status := events.SagaStatus(r.URL.Query().Get("status"))
+	if !isKnownStatus(status) {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "unknown or missing status"})
+		return
+	}
+
+	states, err := ah.storage.GetSagasByStatus(r.Context(), status)
+	if err != nil {
+		ah.writeError(w, err)
+		return
+	}
+
+	sagas := make([]sagaResponse, 0, len(states))
+	for _, state := range states {
+		sagas = append(sagas, toSagaResponse(state))
+	}
+
+	writeJSON(w, http.StatusOK, sagas)
+}
+
+func (ah *AdminHandler) getSaga(w http.ResponseWriter, r *http.Request) {
+	sagaID, ok := sagaIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := ah.storage.GetSagaState(r.Context(), sagaID)
+	if err != nil {
+		ah.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSagaResponse(state))
+}
+
+// getSagaHistory отдаёт переходы саги. История переживает удаление саги,
+// поэтому сама сага для ответа не нужна.
+func (ah *AdminHandler) getSagaHistory(w http.ResponseWriter, r *http.Request) {
+	sagaID, ok := sagaIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if ah.history == nil {
+		writeJSON(w, http.StatusNotImplemented, errorResponse{Error: "saga history is kept only in postgres storage"})
+		return
+	}
+
+	records, err := ah.history.GetSagaHistory(r.Context(), sagaID)
+	if err != nil {
+		ah.writeError(w, err)
+		return
+	}
+	if len(records) == 0 {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "saga history not found"})
+		return
+	}
+
+	history := make([]stepRecordResponse, 0, len(records))
+	for _, record := range records {
+		history = append(history, stepRecordResponse{
+			Step:       record.Step,
+			Status:     record.Status,
+			FailedStep: record.FailedStep,
+			RetryCount: record.RetryCount,
+			RecordedAt: record.RecordedAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (ah *AdminHandler) retrySaga(w http.ResponseWriter, r *http.Request) {
+	sagaID, ok := sagaIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req retryRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if err := ah.orchestrator.RetryStep(r.Context(), sagaID, req.Step); err != nil {
+		ah.writeError(w, err)
+		return
+	}
+
+	ah.log.Info("Admin retried saga step", slog.String("saga_id", sagaID), slog.String("step", req.Step))
+	ah.respondWithSaga(w, r, sagaID)
+}
+
+func (ah *AdminHandler) compensateSaga(w http.ResponseWriter, r *http.Request) {
+	sagaID, ok := sagaIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := ah.orchestrator.ForceCompensation(r.Context(), sagaID); err != nil {
+		ah.writeError(w, err)
+		return
+	}
+
+	ah.log.Info("Admin forced saga compensation", slog.String("saga_id", sagaID))
+	ah.respondWithSaga(w, r, sagaID)
+}
+
+func (ah *AdminHandler) resolveSaga(w http.ResponseWriter, r *http.Request) {
+	sagaID, ok := sagaIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req resolveRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if err := ah.orchestrator.MarkResolved(r.Context(), sagaID, req.Reason); err != nil {
+		ah.writeError(w, err)
+		return
+	}
+
+	ah.log.Info("Admin resolved saga", slog.String("saga_id", sagaID))
+	ah.respondWithSaga(w, r, sagaID)
+}
+
+func (ah *AdminHandler) respondWithSaga(w http.ResponseWriter, r *http.Request, sagaID string) {
+	state, err := ah.storage.GetSagaState(r.Context(), sagaID)
+	if err != nil {
+		ah.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSagaResponse(state))
+}
+
+func (ah *AdminHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, events.ErrSagaNotFound):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+	case errors.Is(err, saga.ErrInvalidSagaState), errors.Is(err, events.ErrSagaConflict):
+		writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+	default:
+		ah.log.Error("Admin request failed", "error", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+	}
+}
+
+func sagaIDFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	sagaID := r.PathValue("id")
+	if _, err := uuid.Parse(sagaID); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid saga id"})
+		return "", false
+	}
+	return sagaID, true
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body"})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func isKnownStatus(status events.SagaStatus) bool {
+	switch status {
+	case events.SagaStatusPending,
+		events.SagaStatusInProgress,
+		events.SagaStatusCompleted,
+		events.SagaStatusRollingBack,
+		events.SagaStatusRolledBack,
+		events.SagaStatusResolved,
+		events.SagaStatusCompensationFailed:
+		return true
+	}
+	return false
+}
+
+func toSagaResponse(state *events.SagaState) sagaResponse {
+	completedSteps := state.CompletedSteps
+	if completedSteps == nil {
+		completedSteps = []string{}
+	}
+
+	return sagaResponse{
+		ID:                   state.ID,
+		Type:                 state.Type,
+		UserID:               state.UserID,
+		Status:               state.Status,
+		CurrentStep:          state.CurrentStep,
+		FailedStep:           state.FailedStep,
+		CompletedSteps:       completedSteps,
+		StepResults:          state.StepResults,
+		RetryCount:           state.RetryCount,
+		StepRetries:          state.StepRetries,
+		ScheduledStep:        state.ScheduledStep,
+		NextRetryAt:          optionalTime(state.NextRetryAt),
+		StepDeadline:         optionalTime(state.StepDeadline),
+		CreatedAt:            state.CreatedAt,
+		UpdatedAt:            state.UpdatedAt,
+		ExpiresAt:            state.ExpiresAt,
+		Metadata:             state.Metadata,
+		PendingCompensations: state.PendingCompensations,
+		CompensationRetries:  state.CompensationRetries,
+	}
+}
+
+// optionalTime скрывает незаданное время из ответа вместо 0001-01-01.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
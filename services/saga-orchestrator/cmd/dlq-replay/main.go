@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"saga-orchestrator/internal/config"
+	"saga-orchestrator/internal/kafka"
+	"shiroyama/messaging/dlq"
+)
+
+const (
+	envLocal = "local"
+	envDev   = "dev"
+	envProd  = "prod"
+)
+
+// dlq-replay перекладывает сообщения из <topic>.dlq обратно в исходный топик,
+// когда ошибка в обработчике исправлена. Прогресс хранится в consumer group,
+// поэтому повторный запуск не отправит одно и то же сообщение дважды.
+//
+//	./dlq-replay -config config/config.yaml -topic user-deletion-saga.dlq
+func main() {
+	topic := flag.String("topic", "", "dead letter topic to replay (suffix .dlq is optional)")
+	group := flag.String("group", "dlq-replay", "consumer group that stores replay progress")
+	limit := flag.Int("limit", 0, "maximum number of messages to replay, 0 for all")
+	dryRun := flag.Bool("dry-run", false, "print messages without replaying them")
+
+	cfg := config.MustLoad()
+	log := setupLogger(cfg.LogLevel)
+
+	if *topic == "" {
+		log.Error("Topic is required")
+		os.Exit(2)
+	}
+
+	dlqTopic := *topic
+	if !strings.HasSuffix(dlqTopic, dlq.TopicSuffix) {
+		dlqTopic += dlq.TopicSuffix
+	}
+
+	producer, err := kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Producer)
+	if err != nil {
+		log.Error("Failed to create Kafka producer", "error", err)
+		os.Exit(1)
+	}
+	defer producer.Close()
+
+	replayer, err := newReplayer(cfg.Kafka.Brokers, *group, producer, log)
+	if err != nil {
+		log.Error("Failed to create replayer", "error", err)
+		os.Exit(1)
+	}
+	defer replayer.Close()
+
+	replayed, err := replayer.Replay(dlqTopic, *limit, *dryRun)
+	if err != nil {
+		log.Error("Replay failed", "topic", dlqTopic, "replayed", replayed, "error", err)
+		os.Exit(1)
+	}
+
+	log.Info("Replay finished", "topic", dlqTopic, "replayed", replayed, "dry_run", *dryRun)
+}
+
+type replayer struct {
+	client   sarama.Client
+	consumer sarama.Consumer
+	offsets  sarama.OffsetManager
+	producer *kafka.Producer
+	log      *slog.Logger
+}
+
+func newReplayer(brokers []string, group string, producer *kafka.Producer, log *slog.Logger) (*replayer, error) {
+	cfg := sarama.NewConfig()
+	cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	client, err := sarama.NewClient(brokers, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	offsets, err := sarama.NewOffsetManagerFromClient(group, client)
+	if err != nil {
+		consumer.Close()
+		client.Close()
+		return nil, fmt.Errorf("failed to create offset manager: %w", err)
+	}
+
+	return &replayer{
+		client:   client,
+		consumer: consumer,
+		offsets:  offsets,
+		producer: producer,
+		log:      log,
+	}, nil
+}
+
+// Replay читает каждую партицию до high watermark на момент запуска, чтобы
+// сообщения, снова упавшие в DLQ во время replay, не зациклили команду.
+func (r *replayer) Replay(dlqTopic string, limit int, dryRun bool) (int, error) {
+	partitions, err := r.client.Partitions(dlqTopic)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get partitions: %w", err)
+	}
+
+	replayed := 0
+	for _, partition := range partitions {
+		if limit > 0 && replayed >= limit {
+			break
+		}
+
+		n, err := r.replayPartition(dlqTopic, partition, limit-replayed, limit > 0, dryRun)
+		replayed += n
+		if err != nil {
+			return replayed, err
+		}
+	}
+
+	return replayed, nil
+}
+
+func (r *replayer) replayPartition(dlqTopic string, partition int32, remaining int, limited, dryRun bool) (int, error) {
+	highWatermark, err := r.client.GetOffset(dlqTopic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get high watermark: %w", err)
+	}
+
+	partitionOffsets, err := r.offsets.ManagePartition(dlqTopic, partition)
+	if err != nil {
+		return 0, fmt.Errorf("failed to manage partition offsets: %w", err)
+	}
+	defer partitionOffsets.Close()
+
+	oldest, err := r.client.GetOffset(dlqTopic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get oldest offset: %w", err)
+	}
+
+	// Часть сообщений могла быть удалена по retention
+	next, _ := partitionOffsets.NextOffset()
+	next = max(next, oldest)
+	if next >= highWatermark {
+		return 0, nil
+	}
+
+	partitionConsumer, err := r.consumer.ConsumePartition(dlqTopic, partition, next)
+	if err != nil {
+		return 0, fmt.Errorf("failed to consume partition %d: %w", partition, err)
+	}
+	defer partitionConsumer.Close()
+
+	replayed := 0
+	for message := range partitionConsumer.Messages() {
+		if !dryRun {
+			if err := r.producer.SendMessage(replayMessage(message)); err != nil {
+				return replayed, err
+			}
+			partitionOffsets.MarkOffset(message.Offset+1, "")
+		}
+
+		replayed++
+		r.log.Info("Replayed message",
+			"partition", message.Partition,
+			"offset", message.Offset,
+			"target", originalTopic(message),
+			"error", headerValue(message, dlq.HeaderError),
+		)
+
+		if message.Offset+1 >= highWatermark || (limited && replayed >= remaining) {
+			break
+		}
+	}
+
+	r.offsets.Commit()
+	return replayed, nil
+}
+
+func (r *replayer) Close() error {
+	return errors.Join(r.offsets.Close(), r.consumer.Close(), r.client.Close())
+}
+
+// replayMessage возвращает сообщение в исходный топик без dlq-* заголовков:
+// для обработчика это обычная повторная доставка.
+func replayMessage(message *sarama.ConsumerMessage) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers))
+	for _, header := range message.Headers {
+		if header == nil || strings.HasPrefix(string(header.Key), "dlq-") {
+			continue
+		}
+		headers = append(headers, *header)
+	}
+
+	return &sarama.ProducerMessage{
+		Topic:   originalTopic(message),
+		Key:     sarama.ByteEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+}
+
+func originalTopic(message *sarama.ConsumerMessage) string {
+	if topic := headerValue(message, dlq.HeaderOriginalTopic); topic != "" {
+		return topic
+	}
+	return strings.TrimSuffix(message.Topic, dlq.TopicSuffix)
+}
+
+func headerValue(message *sarama.ConsumerMessage, key string) string {
+	for _, header := range message.Headers {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func setupLogger(env string) *slog.Logger {
+	var log *slog.Logger
+
+	switch env {
+	case envLocal:
+		log = slog.New(
+			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		)
+	case envDev:
+		log = slog.New(
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		)
+	case envProd:
+		log = slog.New(
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		)
+	}
+
+	return log
+}
@@ -14,6 +14,8 @@ import (
 
 	"saga-orchestrator/internal/config"
 	"saga-orchestrator/internal/kafka"
+	"saga-orchestrator/internal/leader"
+	"saga-orchestrator/internal/metrics"
 	"saga-orchestrator/internal/saga"
 )
 
@@ -23,33 +25,47 @@ const (
 	envProd  = "prod"
 )
 
+const (
+	storageRedis    = "redis"
+	storagePostgres = "postgres"
+)
+
 func main() {
 	cfg := config.MustLoad()
 
 	log := setupLogger(cfg.LogLevel)
 
-	//metricsServer := metrics.NewServer(cfg.MetricsPort)
-	//go func() {
-	//	if err := metricsServer.Start(); err != nil {
-	//		log.Error("Failed to start metrics server: %v", err)
-	//	}
-	//}()
+	metricsServer := metrics.NewServer(cfg.MetricsPort)
+	go func() {
+		if err := metricsServer.Start(); err != nil {
+			log.Error("Failed to start metrics server", "error", err)
+		}
+	}()
 
-	sagaStorage, err := storage.NewRedisStorage(cfg.Redis)
+	sagaStorage, err := newStorage(cfg)
 	if err != nil {
-		log.Error("Saga Orchestrator started successfully", "port", cfg.HTTPPort)
+		log.Error("Failed to create saga storage", "error", err)
+		os.Exit(1)
 	}
 	defer sagaStorage.Close()
 
 	producer, err := kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Producer)
 	if err != nil {
-		log.Error("Failed to create Kafka producer", err)
+		log.Error("Failed to create Kafka producer", "error", err)
 	}
 	defer producer.Close()
 
+	elector, err := newLeader(cfg, log)
+	if err != nil {
+		log.Error("Failed to set up leader election", "error", err)
+		os.Exit(1)
+	}
+
 	orchestrator := saga.NewOrchestrator(
 		producer,
 		sagaStorage,
+		sagaStorage,
+		elector,
 		log,
 		cfg.Saga,
 	)
@@ -59,10 +75,11 @@ func main() {
 		cfg.Kafka.Consumer.GroupID,
 		cfg.Kafka.Consumer,
 		orchestrator,
+		producer,
 		log,
 	)
 	if err != nil {
-		log.Error("Failed to create Kafka consumer", err)
+		log.Error("Failed to create Kafka consumer", "error", err)
 	}
 	defer consumer.Close()
 
@@ -71,11 +88,21 @@ func main() {
 
 	var wg sync.WaitGroup
 
+	if lease, ok := elector.(*leader.RedisLease); ok {
+		defer lease.Close()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease.Run(ctx)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		if err := consumer.Start(ctx); err != nil {
-			log.Error("Consumer error", err)
+			log.Error("Consumer error", "error", err)
 		}
 	}()
 
@@ -85,17 +112,30 @@ func main() {
 		orchestrator.StartTimeoutMonitor(ctx)
 	}()
 
-	healthServer := NewHealthServer(cfg.HTTPPort, log)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		orchestrator.StartRetryScheduler(ctx)
+	}()
+
+	var adminHandler *AdminHandler
+	if cfg.Admin.Token != "" {
+		adminHandler = NewAdminHandler(sagaStorage, orchestrator, cfg.Admin.Token, log)
+	} else {
+		log.Warn("Admin API is disabled: admin token is not configured")
+	}
+
+	healthServer := NewHealthServer(cfg.HTTPPort, log, adminHandler)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		if err := healthServer.Start(); err != nil {
-			log.Error("Health server error", err)
+			log.Error("Health server error", "error", err)
 		}
 	}()
 
-	log.Error("Saga Orchestrator started successfully on port", cfg.HTTPPort)
-	log.Error("Metrics server started on port", cfg.MetricsPort)
+	log.Info("Saga Orchestrator started successfully", "port", cfg.HTTPPort)
+	log.Info("Metrics server started", "port", cfg.MetricsPort)
 
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
@@ -119,10 +159,40 @@ func main() {
 	}
 
 	healthServer.Stop()
+	if err := metricsServer.Stop(); err != nil {
+		log.Error("Error stopping metrics server", "error", err)
+	}
 
 	log.Info("Saga Orchestrator stopped")
 }
 
+type sagaStore interface {
+	saga.Storage
+	saga.Inbox
+}
+
+func newStorage(cfg *config.Config) (sagaStore, error) {
+	switch cfg.Storage {
+	case storageRedis:
+		return storage.NewRedisStorage(cfg.Redis, cfg.Inbox.Retention)
+	case storagePostgres:
+		return storage.NewPostgresStorage(cfg.DB, cfg.Inbox.Retention)
+	default:
+		return nil, fmt.Errorf("unknown saga storage: %s", cfg.Storage)
+	}
+}
+
+// newLeader без выборов считает реплику единственной: так можно запускать
+// только один экземпляр оркестратора.
+func newLeader(cfg *config.Config, log *slog.Logger) (saga.Leader, error) {
+	if !cfg.Leader.Enabled {
+		log.Warn("Leader election is disabled: run a single orchestrator replica")
+		return leader.Standalone{}, nil
+	}
+
+	return leader.NewRedisLease(cfg.Redis, cfg.Leader, log)
+}
+
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 
@@ -150,7 +220,7 @@ type HealthServer struct {
 	server *http.Server
 }
 
-func NewHealthServer(port int, log *slog.Logger) *HealthServer {
+func NewHealthServer(port int, log *slog.Logger, admin *AdminHandler) *HealthServer {
 	mux := http.NewServeMux()
 
 	server := &http.Server{
@@ -167,6 +237,10 @@ func NewHealthServer(port int, log *slog.Logger) *HealthServer {
 	mux.HandleFunc("/health", hs.healthHandler)
 	mux.HandleFunc("/ready", hs.readyHandler)
 
+	if admin != nil {
+		admin.Register(mux)
+	}
+
 	return hs
 }
 
@@ -181,7 +255,7 @@ func (hs *HealthServer) readyHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (hs *HealthServer) Start() error {
-	hs.log.Error("Starting health server on port", hs.port)
+	hs.log.Info("Starting health server", "port", hs.port)
 	return hs.server.ListenAndServe()
 }
 
@@ -190,6 +264,6 @@ func (hs *HealthServer) Stop() {
 	defer cancel()
 
 	if err := hs.server.Shutdown(ctx); err != nil {
-		hs.log.Error("Error stopping health server", err)
+		hs.log.Error("Error stopping health server", "error", err)
 	}
 }
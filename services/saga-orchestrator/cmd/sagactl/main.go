@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// sagactl прогоняет саги по сценариям без Kafka, Redis и участников:
+// оркестратор работает как обычно, а ответы участников и таймауты берутся
+// из YAML-файла. Код выхода ненулевой, если хотя бы один сценарий не
+// дошёл до ожидаемого результата, поэтому утилиту можно запускать в CI.
+//
+//	go run ./cmd/sagactl cmd/sagactl/scenarios/*.yaml
+func main() {
+	verbose := flag.Bool("v", false, "print orchestrator logs to stderr")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: sagactl [-v] scenario.yaml...")
+		os.Exit(2)
+	}
+
+	handler := slog.DiscardHandler
+	if *verbose {
+		handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+	}
+	logger := slog.New(handler)
+
+	failed := 0
+	for _, path := range flag.Args() {
+		if err := run(path, logger); err != nil {
+			fmt.Printf("FAIL %s: %v\n\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("PASS %s\n\n", path)
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d of %d scenarios failed\n", failed, flag.NArg())
+		os.Exit(1)
+	}
+}
+
+func run(path string, logger *slog.Logger) error {
+	scenario, err := loadScenario(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== %s (%s)\n", scenario.Name, scenario.Saga)
+
+	sim, err := newSimulator(scenario, os.Stdout, logger)
+	if err != nil {
+		return err
+	}
+
+	state, err := sim.Run(context.Background())
+	if err != nil {
+		return err
+	}
+
+	return sim.check(state)
+}
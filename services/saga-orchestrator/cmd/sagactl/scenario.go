@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"saga-orchestrator/internal/config"
+	"saga-orchestrator/internal/events"
+)
+
+const (
+	replySuccess = "success"
+	replyFailure = "failure"
+	replyTimeout = "timeout"
+)
+
+// Scenario описывает прогон одной саги: чем она запускается и как отвечает
+// каждый участник на команды и компенсации.
+type Scenario struct {
+	Name   string                 `yaml:"name"`
+	Saga   string                 `yaml:"saga"`
+	UserID string                 `yaml:"userId"`
+	Data   map[string]interface{} `yaml:"data"`
+	Config config.SagaConfig      `yaml:"config"`
+	Steps  map[string]Participant `yaml:"steps"`
+	Expect Expectation            `yaml:"expect"`
+}
+
+// Participant задаёт ответы на попытки по порядку: первая попытка получает
+// первый ответ, а когда список кончился, повторяется последний. Без ответов
+// участник всегда отвечает успехом.
+type Participant struct {
+	Replies       []string `yaml:"replies"`
+	Compensations []string `yaml:"compensations"`
+	// Data добавляется к data ответа об успехе, например rollback_data
+	Data map[string]interface{} `yaml:"data"`
+}
+
+type Expectation struct {
+	Status events.SagaStatus  `yaml:"status"`
+	Events []events.EventType `yaml:"events"`
+}
+
+func loadScenario(path string) (*Scenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	// Значения по умолчанию совпадают с config.example.yaml
+	scenario := &Scenario{
+		Name:   path,
+		UserID: "user-1",
+		Config: config.SagaConfig{
+			Timeout:                time.Minute,
+			RetryInterval:          10 * time.Second,
+			MaxRetryBackoff:        5 * time.Minute,
+			MaxRetries:             3,
+			MaxCompensationRetries: 5,
+		},
+	}
+
+	if err := yaml.Unmarshal(raw, scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+
+	if scenario.Saga == "" {
+		return nil, fmt.Errorf("scenario %s: saga type is required", path)
+	}
+
+	for stepName, participant := range scenario.Steps {
+		for _, reply := range append(append([]string(nil), participant.Replies...), participant.Compensations...) {
+			switch reply {
+			case replySuccess, replyFailure, replyTimeout:
+			default:
+				return nil, fmt.Errorf("scenario %s: step %s: unknown reply %q", path, stepName, reply)
+			}
+		}
+	}
+
+	return scenario, nil
+}
+
+// reply возвращает ответ участника на попытку с номером attempt (с единицы).
+func (p Participant) reply(attempt int, compensation bool) string {
+	replies := p.Replies
+	if compensation {
+		replies = p.Compensations
+	}
+
+	if len(replies) == 0 {
+		return replySuccess
+	}
+	if attempt > len(replies) {
+		return replies[len(replies)-1]
+	}
+	return replies[attempt-1]
+}
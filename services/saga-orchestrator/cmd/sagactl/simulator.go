@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"saga-orchestrator/internal/events"
+	"saga-orchestrator/internal/leader"
+	"saga-orchestrator/internal/saga"
+	"saga-orchestrator/internal/storage"
+)
+
+// Защита от сценариев, где сага никогда не приходит в конечный статус.
+const maxClockAdvances = 1000
+
+type published struct {
+	topic string
+	event events.Event
+}
+
+// simulator прогоняет сагу через настоящий Orchestrator, подменяя Kafka
+// очередью в памяти, а участников — ответами из сценария. Время виртуальное:
+// когда событий больше нет, часы переводятся к ближайшему сроку саги.
+type simulator struct {
+	scenario     *Scenario
+	def          *saga.Definition
+	orchestrator *saga.Orchestrator
+	storage      *storage.MemoryStorage
+	out          io.Writer
+
+	start    time.Time
+	now      time.Time
+	queue    []published
+	emitted  []events.EventType
+	attempts map[string]int
+	sagaID   string
+	lastSeen string
+}
+
+func newSimulator(scenario *Scenario, out io.Writer, logger *slog.Logger) (*simulator, error) {
+	registry, err := saga.NewRegistry(saga.DefaultDefinitions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	def, ok := registry.Get(scenario.Saga)
+	if !ok {
+		return nil, fmt.Errorf("unknown saga type %q", scenario.Saga)
+	}
+
+	for stepName := range scenario.Steps {
+		if _, ok := def.Step(stepName); !ok {
+			return nil, fmt.Errorf("saga %s has no step %q", def.Type, stepName)
+		}
+	}
+
+	s := &simulator{
+		scenario: scenario,
+		def:      def,
+		out:      out,
+		start:    time.Now(),
+		attempts: make(map[string]int),
+	}
+	s.now = s.start
+	s.storage = storage.NewMemoryStorage(s.clock)
+	s.orchestrator = saga.NewOrchestrator(s, s.storage, s.storage, leader.Standalone{}, logger, scenario.Config).
+		WithClock(s.clock)
+
+	return s, nil
+}
+
+func (s *simulator) clock() time.Time {
+	return s.now
+}
+
+// PublishEvent проверяет событие по контракту, как настоящий продюсер,
+// и кладёт его в очередь вместо Kafka.
+func (s *simulator) PublishEvent(topic string, event events.Event) error {
+	if _, err := events.Marshal(event); err != nil {
+		return err
+	}
+
+	if s.sagaID == "" {
+		s.sagaID = event.SagaID
+	}
+
+	s.queue = append(s.queue, published{topic: topic, event: event})
+	s.emitted = append(s.emitted, event.Type)
+	s.printf("emit", "%s -> %s%s", event.Type, topic, formatData(event.Data))
+	return nil
+}
+
+func (s *simulator) Run(ctx context.Context) (*events.SagaState, error) {
+	trigger := events.NewEvent(s.def.TriggerType, s.scenario.UserID, "", s.scenario.Data)
+	if err := s.deliver(ctx, "trigger", trigger); err != nil {
+		return nil, err
+	}
+
+	for advances := 0; ; advances++ {
+		for len(s.queue) > 0 {
+			next := s.queue[0]
+			s.queue = s.queue[1:]
+
+			if err := s.respond(ctx, next); err != nil {
+				return nil, err
+			}
+		}
+
+		if s.sagaID == "" {
+			return nil, fmt.Errorf("saga did not start")
+		}
+
+		state, err := s.storage.GetSagaState(ctx, s.sagaID)
+		if err != nil {
+			return nil, err
+		}
+		if isFinal(state.Status) {
+			return state, nil
+		}
+
+		if advances >= maxClockAdvances {
+			return state, fmt.Errorf("saga did not finish after %d clock advances", maxClockAdvances)
+		}
+		if !s.advance(state) {
+			return state, fmt.Errorf("saga is stuck in %s with nothing scheduled", state.Status)
+		}
+
+		if err := s.orchestrator.FireDueRetries(ctx); err != nil {
+			return nil, err
+		}
+		if err := s.orchestrator.CheckStepTimeouts(ctx); err != nil {
+			return nil, err
+		}
+		if err := s.orchestrator.CheckExpiredSagas(ctx); err != nil {
+			return nil, err
+		}
+		s.printState(ctx)
+	}
+}
+
+// respond отвечает на команду или компенсацию так, как задано в сценарии.
+// Итоговые события саги только печатаются.
+func (s *simulator) respond(ctx context.Context, command published) error {
+	for _, step := range s.def.Steps {
+		switch command.event.Type {
+		case step.EventType:
+			return s.reply(ctx, step, command.event, false)
+		case step.CompensateType:
+			return s.reply(ctx, step, command.event, true)
+		}
+	}
+
+	return nil
+}
+
+func (s *simulator) reply(ctx context.Context, step saga.Step, command events.Event, compensation bool) error {
+	attemptKey := step.Name
+	if compensation {
+		attemptKey = "compensate:" + step.Name
+	}
+	s.attempts[attemptKey]++
+	attempt := s.attempts[attemptKey]
+
+	participant := s.scenario.Steps[step.Name]
+	outcome := participant.reply(attempt, compensation)
+
+	// Участник отвечает теми же data, что получил: так в ответ попадают
+	// team_id и другие ключи, которых требует контракт
+	data := make(map[string]interface{}, len(command.Data)+len(participant.Data)+1)
+	for key, value := range command.Data {
+		data[key] = value
+	}
+
+	var replyType events.EventType
+	switch {
+	case outcome == replyTimeout:
+		s.printf("timeout", "%s does not reply to %s (attempt %d)", step.Name, command.Type, attempt)
+		return nil
+	case outcome == replyFailure:
+		data["error"] = "simulated failure"
+		replyType = step.FailureType
+		if compensation {
+			replyType = step.CompensationFailedType
+		}
+	default:
+		if !compensation {
+			for key, value := range participant.Data {
+				data[key] = value
+			}
+		}
+		replyType = step.SuccessType
+		if compensation {
+			replyType = step.CompensatedType
+		}
+	}
+
+	return s.deliver(ctx, "reply", events.NewEvent(replyType, command.UserID, command.SagaID, data))
+}
+
+// deliver передаёт событие оркестратору так же, как консьюмер Kafka: после
+// проверки по контракту.
+func (s *simulator) deliver(ctx context.Context, kind string, event events.Event) error {
+	if _, err := events.Marshal(event); err != nil {
+		return fmt.Errorf("%s %s violates the event contract: %w", kind, event.Type, err)
+	}
+
+	s.printf(kind, "%s%s", event.Type, formatData(event.Data))
+
+	if err := s.orchestrator.HandleEvent(ctx, event); err != nil {
+		return fmt.Errorf("orchestrator failed to handle %s: %w", event.Type, err)
+	}
+
+	s.printState(ctx)
+	return nil
+}
+
+// advance переводит часы к ближайшему сроку саги: повтору, дедлайну шага
+// или общему таймауту.
+func (s *simulator) advance(state *events.SagaState) bool {
+	var next time.Time
+	deadlines := []time.Time{state.NextRetryAt, state.StepDeadline}
+	if state.Status == events.SagaStatusPending || state.Status == events.SagaStatusInProgress {
+		deadlines = append(deadlines, state.ExpiresAt)
+	}
+
+	for _, deadline := range deadlines {
+		if deadline.IsZero() {
+			continue
+		}
+		if next.IsZero() || deadline.Before(next) {
+			next = deadline
+		}
+	}
+
+	if next.IsZero() {
+		return false
+	}
+
+	// Сроки сравниваются строго, поэтому шагаем чуть дальше
+	previous := s.now
+	if next.After(s.now) {
+		s.now = next.Add(time.Millisecond)
+	}
+	s.printf("clock", "skipped %s to the next deadline", s.now.Sub(previous).Round(time.Millisecond))
+	return true
+}
+
+// printState печатает состояние саги, только если оно изменилось.
+func (s *simulator) printState(ctx context.Context) {
+	if s.sagaID == "" {
+		return
+	}
+
+	state, err := s.storage.GetSagaState(ctx, s.sagaID)
+	if err != nil {
+		return
+	}
+
+	line := fmt.Sprintf("%s step=%s completed=[%s]", state.Status, state.CurrentStep, strings.Join(state.CompletedSteps, ","))
+	if len(state.PendingCompensations) > 0 {
+		line += fmt.Sprintf(" pending_compensations=[%s]", strings.Join(state.PendingCompensations, ","))
+	}
+	if state.ScheduledStep != "" {
+		line += fmt.Sprintf(" retry=%s@+%s", state.ScheduledStep, state.NextRetryAt.Sub(s.start).Round(time.Millisecond))
+	}
+
+	if line != s.lastSeen {
+		s.lastSeen = line
+		s.printf("state", "%s", line)
+	}
+}
+
+// check сравнивает итог прогона с ожиданиями сценария.
+func (s *simulator) check(state *events.SagaState) error {
+	expect := s.scenario.Expect
+
+	if expect.Status != "" && state.Status != expect.Status {
+		return fmt.Errorf("expected status %s, got %s", expect.Status, state.Status)
+	}
+
+	for _, eventType := range expect.Events {
+		if !slices.Contains(s.emitted, eventType) {
+			return fmt.Errorf("expected event %s was not emitted", eventType)
+		}
+	}
+
+	return nil
+}
+
+func (s *simulator) elapsed() time.Duration {
+	return s.now.Sub(s.start).Round(time.Millisecond)
+}
+
+func (s *simulator) printf(kind, format string, args ...any) {
+	fmt.Fprintf(s.out, "%10s  %-8s %s\n", "+"+s.elapsed().String(), kind, fmt.Sprintf(format, args...))
+}
+
+func isFinal(status events.SagaStatus) bool {
+	switch status {
+	case events.SagaStatusCompleted,
+		events.SagaStatusRolledBack,
+		events.SagaStatusResolved,
+		events.SagaStatusCompensationFailed:
+		return true
+	}
+	return false
+}
+
+func formatData(data map[string]interface{}) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	return " " + string(raw)
+}
@@ -0,0 +1,8 @@
+// Package e2e прогоняет саги оркестратора вместе с настоящими обработчиками
+// сервисов-участников через bus.Broker. Базы участников заменены фейковыми
+// репозиториями, а outbox и inbox — memstore, поэтому тестам не нужны ни
+// Kafka, ни PostgreSQL.
+//
+// Это отдельный модуль: он подключает модули сервисов через replace, а
+// оркестратору они не нужны.
+package e2e
@@ -0,0 +1,271 @@
+package e2e
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	authkafka "authservice/src/kafka"
+	boardkafka "boardservice/kafka"
+	contract "shiroyama/events"
+	"shiroyama/events/bus"
+	"shiroyama/messaging/inbox"
+	"shiroyama/messaging/memstore"
+	"shiroyama/messaging/outbox"
+	taskkafka "taskservice/kafka"
+	teamkafka "teamservice/kafka"
+	userkafka "userservice/kafka"
+
+	"gorm.io/gorm"
+)
+
+var errDatabaseDown = errors.New("database is down")
+
+// ledger — данные участника о пользователях: кого он удалил и сколько раз
+// выполнял команду и компенсацию.
+type ledger struct {
+	fail          bool
+	deleted       map[string]bool
+	commands      int
+	compensations int
+}
+
+func newLedger() *ledger {
+	return &ledger{deleted: make(map[string]bool)}
+}
+
+func (l *ledger) remove(userID string) error {
+	l.commands++
+	if l.fail {
+		return errDatabaseDown
+	}
+
+	l.deleted[userID] = true
+	return nil
+}
+
+func (l *ledger) restore(userID string) {
+	l.compensations++
+	delete(l.deleted, userID)
+}
+
+// service — база участника в памяти: его outbox relay публикует в брокер.
+type service[Tx any] struct {
+	db     *memstore.DB[Tx]
+	inbox  *inbox.Inbox[Tx]
+	outbox *outbox.Outbox[Tx]
+}
+
+func newService[Tx any](name string, broker *bus.Broker, logger *slog.Logger) *service[Tx] {
+	db := memstore.NewDB[Tx]()
+
+	return &service[Tx]{
+		db:    db,
+		inbox: inbox.New(memstore.NewInbox(db), name, time.Hour, logger),
+		outbox: outbox.New(memstore.NewOutbox(db), name, broker, outbox.Config{
+			BatchSize: 100,
+			Retention: time.Hour,
+		}, logger),
+	}
+}
+
+// users — репозиторий пользователей auth-service и user-service.
+type users[Tx any] struct {
+	*ledger
+}
+
+func (r users[Tx]) SoftDeleteUserTx(ctx context.Context, tx Tx, userID string) error {
+	return r.remove(userID)
+}
+
+func (r users[Tx]) RestoreUserTx(ctx context.Context, tx Tx, userID string) error {
+	r.restore(userID)
+	return nil
+}
+
+// teams — сервис команд team-service. Как и настоящий, он сам открывает
+// транзакцию и вызывает в ней beforeCommit.
+type teams struct {
+	*ledger
+	db *memstore.DB[*sql.Tx]
+}
+
+func (s teams) DeleteUserFromAllTeams(ctx context.Context, userID string, beforeCommit teamkafka.BeforeCommit) (*contract.TeamDeletionData, error) {
+	data := &contract.TeamDeletionData{Teams: []contract.TeamMembershipData{{
+		TeamID:   "team-1",
+		Role:     "owner",
+		JoinedAt: time.Now().UTC(),
+	}}}
+
+	err := s.db.InTx(ctx, func(tx *sql.Tx) error {
+		if err := s.remove(userID); err != nil {
+			return err
+		}
+		return beforeCommit(ctx, tx, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s teams) RestoreUserTeams(ctx context.Context, userID string, data *contract.TeamDeletionData) error {
+	s.restore(userID)
+	return nil
+}
+
+func (s teams) GetUserTeamMemberships(ctx context.Context, userID string) (*contract.TeamDeletionData, error) {
+	return &contract.TeamDeletionData{}, nil
+}
+
+func (s teams) DeleteTeam(ctx context.Context, ID, sagaID string, beforeCommit teamkafka.TeamDeleteHook) error {
+	return errors.New("team deletion is not part of this saga")
+}
+
+func (s teams) RestoreTeam(ctx context.Context, ID string) error {
+	return errors.New("team deletion is not part of this saga")
+}
+
+// boards — репозиторий досок board-service.
+type boards struct {
+	*ledger
+	restored []string
+}
+
+func (r *boards) ClearBoardsCreatorTx(ctx context.Context, tx *sql.Tx, userID string) (*contract.BoardDeletionData, error) {
+	if err := r.remove(userID); err != nil {
+		return nil, err
+	}
+
+	return &contract.BoardDeletionData{BoardIDs: []string{"board-1"}}, nil
+}
+
+func (r *boards) RestoreBoardsCreator(ctx context.Context, userID string, data *contract.BoardDeletionData) error {
+	r.restore(userID)
+	r.restored = append(r.restored, data.BoardIDs...)
+	return nil
+}
+
+func (r *boards) DeleteTeamBoardsTx(ctx context.Context, tx *sql.Tx, teamID string) (*contract.TeamBoardsDeletionData, []string, error) {
+	return nil, nil, errors.New("team deletion is not part of this saga")
+}
+
+func (r *boards) RestoreTeamBoards(ctx context.Context, data *contract.TeamBoardsDeletionData) error {
+	return errors.New("team deletion is not part of this saga")
+}
+
+// tasks — репозиторий задач task-service.
+type tasks struct {
+	*ledger
+	db *memstore.DB[*gorm.DB]
+}
+
+func (r tasks) RemoveUserFromTasks(ctx context.Context, userID string, beforeCommit taskkafka.BeforeCommit) (*contract.TaskDeletionData, error) {
+	data := &contract.TaskDeletionData{AssignmentIDs: []uint{1}, TaskIDs: []string{"task-1"}}
+
+	err := r.db.InTx(ctx, func(tx *gorm.DB) error {
+		if err := r.remove(userID); err != nil {
+			return err
+		}
+		return beforeCommit(tx, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r tasks) RestoreUserTasks(ctx context.Context, userID string, data *contract.TaskDeletionData) error {
+	r.restore(userID)
+	return nil
+}
+
+func (r tasks) RemoveTeamTasks(ctx context.Context, listIDs []string, beforeCommit taskkafka.TeamBeforeCommit) (*contract.TeamTasksDeletionData, error) {
+	return nil, errors.New("team deletion is not part of this saga")
+}
+
+func (r tasks) RestoreTeamTasks(ctx context.Context, data *contract.TeamTasksDeletionData) error {
+	return errors.New("team deletion is not part of this saga")
+}
+
+// participants — сервисы, которые участвуют в удалении пользователя, с их
+// настоящими обработчиками событий.
+type participants struct {
+	ledgers  map[string]*ledger
+	users    *ledger
+	boards   *boards
+	outboxes []participantOutbox
+	// Сервисы, чей outbox relay пока не отправляет: их ответы опаздывают
+	held map[string]bool
+}
+
+type participantOutbox struct {
+	service string
+	flush   func(ctx context.Context) error
+}
+
+func subscribeParticipants(broker *bus.Broker, logger *slog.Logger) (*participants, error) {
+	p := &participants{ledgers: make(map[string]*ledger), users: newLedger(), held: make(map[string]bool)}
+
+	auth := newService[*gorm.DB]("auth-service", broker, logger)
+	authUsers := users[*gorm.DB]{newLedger()}
+	p.ledgers["delete_auth_user"] = authUsers.ledger
+
+	team := newService[*sql.Tx]("team-service", broker, logger)
+	teamService := teams{ledger: newLedger(), db: team.db}
+	p.ledgers["delete_team_user"] = teamService.ledger
+
+	board := newService[*sql.Tx]("board-service", broker, logger)
+	p.boards = &boards{ledger: newLedger()}
+	p.ledgers["delete_board_user"] = p.boards.ledger
+
+	task := newService[*gorm.DB]("task-service", broker, logger)
+	taskRepo := tasks{ledger: newLedger(), db: task.db}
+	p.ledgers["delete_task_user"] = taskRepo.ledger
+
+	user := newService[*sql.Tx]("user-service", broker, logger)
+
+	subscriptions := []struct {
+		group   string
+		handler bus.Handler
+		topic   string
+	}{
+		{"auth-service", authkafka.NewAuthConsumer(authUsers, auth.inbox, auth.outbox, logger), contract.AuthCommandsTopic},
+		{"team-service", teamkafka.NewTeamConsumer(teamService, team.inbox, team.outbox, logger), contract.TeamCommandsTopic},
+		{"board-service", boardkafka.NewBoardConsumer(p.boards, board.inbox, board.outbox, logger), contract.BoardCommandsTopic},
+		{"task-service", taskkafka.NewTaskConsumer(taskRepo, task.inbox, task.outbox, logger), contract.TaskCommandsTopic},
+		{"user-service", userkafka.NewUserConsumer(users[*sql.Tx]{p.users}, user.inbox, user.outbox, logger), contract.UserDeletionSagaTopic},
+	}
+	for _, sub := range subscriptions {
+		if err := broker.Subscribe(sub.group, sub.handler, sub.topic); err != nil {
+			return nil, err
+		}
+	}
+
+	p.outboxes = []participantOutbox{
+		{"auth-service", auth.outbox.Flush},
+		{"team-service", team.outbox.Flush},
+		{"board-service", board.outbox.Flush},
+		{"task-service", task.outbox.Flush},
+		{"user-service", user.outbox.Flush},
+	}
+	return p, nil
+}
+
+// relay отправляет в брокер всё, что участники записали в свои outbox,
+// кроме задержанных.
+func (p *participants) relay(ctx context.Context) error {
+	for _, outbox := range p.outboxes {
+		if p.held[outbox.service] {
+			continue
+		}
+		if err := outbox.flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,448 @@
+package e2e
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"saga-orchestrator/internal/config"
+	"saga-orchestrator/internal/events"
+	"saga-orchestrator/internal/leader"
+	"saga-orchestrator/internal/saga"
+	"saga-orchestrator/internal/storage"
+	contract "shiroyama/events"
+	"shiroyama/events/bus"
+)
+
+// racingStorage перед записью версии conflictAt один раз пересохраняет
+// сагу, как сделал бы монитор таймаутов, пока обрабатывается ответ.
+type racingStorage struct {
+	*storage.MemoryStorage
+	conflictAt int
+	raced      bool
+}
+
+func (s *racingStorage) SaveSagaState(ctx context.Context, state *events.SagaState) error {
+	if !s.raced && s.conflictAt > 0 && state.Version == s.conflictAt {
+		s.raced = true
+
+		current, err := s.GetSagaState(ctx, state.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.MemoryStorage.SaveSagaState(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	return s.MemoryStorage.SaveSagaState(ctx, state)
+}
+
+type userDeletionRun struct {
+	now          time.Time
+	broker       *bus.Broker
+	storage      *racingStorage
+	orchestrator *saga.Orchestrator
+	participants *participants
+}
+
+func newUserDeletionRun(t *testing.T, cfg config.SagaConfig) *userDeletionRun {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	// Одна попытка доставки: повторы консьюмера не должны скрывать ошибки
+	// обработчиков
+	run := &userDeletionRun{
+		now:    time.Now(),
+		broker: bus.NewBroker(1),
+	}
+	run.storage = &racingStorage{MemoryStorage: storage.NewMemoryStorage(run.clock)}
+	run.orchestrator = saga.NewOrchestrator(run.broker, run.storage, run.storage, leader.Standalone{}, logger, cfg).
+		WithClock(run.clock)
+
+	err := run.broker.Subscribe("saga-orchestrator", run.orchestrator,
+		contract.UserDeletionSagaTopic,
+		contract.AuthEventsTopic,
+		contract.TeamEventsTopic,
+		contract.BoardEventsTopic,
+		contract.TaskEventsTopic,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run.participants, err = subscribeParticipants(run.broker, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return run
+}
+
+func (r *userDeletionRun) clock() time.Time {
+	return r.now
+}
+
+func (r *userDeletionRun) step(name string) *ledger {
+	return r.participants.ledgers[name]
+}
+
+func (r *userDeletionRun) start(t *testing.T, userID string, trace events.Trace) {
+	t.Helper()
+
+	trigger := events.NewEvent(events.UserDeletionRequested, userID, "", nil).WithTrace(trace)
+	if err := r.broker.PublishEvent(contract.UserDeletionSagaTopic, trigger); err != nil {
+		t.Fatal(err)
+	}
+	r.drain(t)
+}
+
+// drain доставляет события и отправляет outbox участников, пока сага не
+// перестанет порождать новые события.
+func (r *userDeletionRun) drain(t *testing.T) {
+	t.Helper()
+
+	ctx := context.Background()
+	for {
+		if err := r.broker.Drain(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		published := len(r.broker.Published())
+		if err := r.participants.relay(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if len(r.broker.Published()) == published {
+			break
+		}
+	}
+
+	for _, deadLetter := range r.broker.DeadLetters() {
+		t.Errorf("%s failed to handle %s: %v", deadLetter.Group, deadLetter.Event.Type, deadLetter.Err)
+	}
+}
+
+func (r *userDeletionRun) onlySaga(t *testing.T) *events.SagaState {
+	t.Helper()
+
+	var found []*events.SagaState
+	for _, status := range []events.SagaStatus{
+		events.SagaStatusPending,
+		events.SagaStatusInProgress,
+		events.SagaStatusCompleted,
+		events.SagaStatusRollingBack,
+		events.SagaStatusRolledBack,
+		events.SagaStatusCompensationFailed,
+	} {
+		sagas, err := r.storage.GetSagasByStatus(context.Background(), status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found = append(found, sagas...)
+	}
+
+	if len(found) != 1 {
+		t.Fatalf("expected exactly one saga, got %d", len(found))
+	}
+	return found[0]
+}
+
+func (r *userDeletionRun) published(topic string) []events.EventType {
+	var types []events.EventType
+	for _, event := range r.broker.PublishedTo(topic) {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// outcome возвращает итоговые события саги. В тот же топик пишет и
+// user-service, но его события не относятся ни к одной саге.
+func (r *userDeletionRun) outcome(sagaID string) []events.EventType {
+	var types []events.EventType
+	for _, event := range r.broker.PublishedTo(contract.UserDeletionSagaTopic) {
+		if event.SagaID == sagaID {
+			types = append(types, event.Type)
+		}
+	}
+	return types
+}
+
+func sagaConfig() config.SagaConfig {
+	return config.SagaConfig{
+		Timeout:                time.Minute,
+		RetryInterval:          10 * time.Second,
+		MaxRetryBackoff:        5 * time.Minute,
+		MaxRetries:             3,
+		MaxCompensationRetries: 5,
+	}
+}
+
+func TestUserDeletionSagaCompletes(t *testing.T) {
+	run := newUserDeletionRun(t, sagaConfig())
+	run.start(t, "user-1", events.Trace{})
+
+	state := run.onlySaga(t)
+	if state.Status != events.SagaStatusCompleted {
+		t.Fatalf("expected status %s, got %s", events.SagaStatusCompleted, state.Status)
+	}
+
+	for name, l := range run.participants.ledgers {
+		if !l.deleted["user-1"] {
+			t.Errorf("%s: user was not deleted", name)
+		}
+	}
+	if !run.participants.users.deleted["user-1"] {
+		t.Error("user-service: user was not deleted")
+	}
+
+	if outcome := run.outcome(state.ID); !slices.Equal(outcome, []events.EventType{events.UserDeletionCompleted}) {
+		t.Fatalf("expected %s, got %v", events.UserDeletionCompleted, outcome)
+	}
+
+	// Компенсация должна получить то, что участник вернул при удалении
+	if _, ok := state.StepResults["delete_team_user"]["rollback_data"]; !ok {
+		t.Fatal("rollback_data of delete_team_user was not stored")
+	}
+}
+
+func TestUserDeletionSagaCompensatesCompletedSteps(t *testing.T) {
+	cfg := sagaConfig()
+	cfg.MaxRetries = 0
+
+	run := newUserDeletionRun(t, cfg)
+	run.step("delete_task_user").fail = true
+	run.start(t, "user-1", events.Trace{})
+
+	state := run.onlySaga(t)
+	if state.Status != events.SagaStatusRolledBack {
+		t.Fatalf("expected status %s, got %s", events.SagaStatusRolledBack, state.Status)
+	}
+
+	for _, name := range []string{"delete_auth_user", "delete_team_user", "delete_board_user"} {
+		l := run.step(name)
+		if l.deleted["user-1"] {
+			t.Errorf("%s: user was not restored", name)
+		}
+		if l.compensations != 1 {
+			t.Errorf("%s: expected 1 compensation, got %d", name, l.compensations)
+		}
+	}
+
+	if got := run.step("delete_task_user").compensations; got != 0 {
+		t.Errorf("failed step must not be compensated, got %d compensations", got)
+	}
+
+	// rollback_data board-service прошла через сагу и вернулась в компенсацию
+	if !slices.Equal(run.participants.boards.restored, []string{"board-1"}) {
+		t.Errorf("board-service restored %v instead of its rollback_data", run.participants.boards.restored)
+	}
+
+	// Компенсации идут в обратном порядке: доски, команды, затем auth
+	board := lastIndex(run.broker, events.BoardUserDeleteRollback)
+	team := lastIndex(run.broker, events.TeamUserDeleteRollback)
+	auth := lastIndex(run.broker, events.AuthUserDeleteRollback)
+	if board < 0 || team < 0 || auth < 0 || board > team || team > auth {
+		t.Fatalf("unexpected compensation order: board %d, team %d, auth %d", board, team, auth)
+	}
+
+	if outcome := run.outcome(state.ID); !slices.Equal(outcome, []events.EventType{events.UserDeletionRollback}) {
+		t.Fatalf("expected %s, got %v", events.UserDeletionRollback, outcome)
+	}
+	if run.participants.users.deleted["user-1"] {
+		t.Error("user-service: user was not restored after the rollback")
+	}
+}
+
+func TestUserDeletionSagaStopsAtFailedStep(t *testing.T) {
+	cfg := sagaConfig()
+	cfg.MaxRetries = 0
+
+	run := newUserDeletionRun(t, cfg)
+	run.step("delete_board_user").fail = true
+	run.start(t, "user-1", events.Trace{})
+
+	if state := run.onlySaga(t); state.Status != events.SagaStatusRolledBack {
+		t.Fatalf("expected status %s, got %s", events.SagaStatusRolledBack, state.Status)
+	}
+
+	if got := run.step("delete_board_user").compensations; got != 0 {
+		t.Errorf("failed step must not be compensated, got %d compensations", got)
+	}
+	if got := run.step("delete_task_user").commands; got != 0 {
+		t.Errorf("steps after the failed one must not run, got %d commands", got)
+	}
+}
+
+// Участник выполнил шаг, но ответил уже после дедлайна. Шаг должен
+// откатиться вместе с выполненными, причём с rollback_data из запоздалого
+// ответа.
+func TestUserDeletionSagaCompensatesStepAnsweredAfterDeadline(t *testing.T) {
+	cfg := sagaConfig()
+	cfg.MaxRetries = 0
+
+	run := newUserDeletionRun(t, cfg)
+	run.participants.held["board-service"] = true
+	run.start(t, "user-1", events.Trace{})
+
+	state := run.onlySaga(t)
+	if state.Status != events.SagaStatusInProgress || state.CurrentStep != "delete_board_user" {
+		t.Fatalf("expected saga to wait for delete_board_user, got %s at %s", state.Status, state.CurrentStep)
+	}
+	if !run.step("delete_board_user").deleted["user-1"] {
+		t.Fatal("board-service did not apply the step")
+	}
+
+	run.now = state.StepDeadline.Add(time.Second)
+	if err := run.orchestrator.CheckStepTimeouts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	delete(run.participants.held, "board-service")
+	run.drain(t)
+
+	state = run.onlySaga(t)
+	if state.Status != events.SagaStatusRolledBack {
+		t.Fatalf("expected status %s, got %s", events.SagaStatusRolledBack, state.Status)
+	}
+
+	for _, name := range []string{"delete_auth_user", "delete_team_user", "delete_board_user"} {
+		if run.step(name).deleted["user-1"] {
+			t.Errorf("%s: user was not restored", name)
+		}
+	}
+	if !slices.Equal(run.participants.boards.restored, []string{"board-1"}) {
+		t.Errorf("board-service restored %v instead of the rollback_data of its late reply", run.participants.boards.restored)
+	}
+	if got := run.step("delete_task_user").commands; got != 0 {
+		t.Errorf("steps after the timed out one must not run, got %d commands", got)
+	}
+}
+
+// Kafka доставляет сообщения как минимум один раз: после ребалансировки
+// консьюмеры перечитывают уже обработанное. Повтор всех событий не должен
+// ни двигать сагу, ни заново удалять пользователя.
+func TestUserDeletionSagaIgnoresRedelivery(t *testing.T) {
+	run := newUserDeletionRun(t, sagaConfig())
+	run.start(t, "user-1", events.Trace{})
+
+	before := run.onlySaga(t)
+	published := len(run.broker.Published())
+
+	for _, message := range run.broker.Published() {
+		if err := run.broker.PublishEvent(message.Topic, message.Event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run.drain(t)
+
+	after := run.onlySaga(t)
+	if after.Status != events.SagaStatusCompleted || after.UpdatedAt != before.UpdatedAt {
+		t.Fatalf("redelivery changed the saga: %s -> %s", before.Status, after.Status)
+	}
+
+	for name, l := range run.participants.ledgers {
+		if l.commands != 1 {
+			t.Errorf("%s: expected 1 command to be handled, got %d", name, l.commands)
+		}
+	}
+	if got := run.participants.users.commands; got != 1 {
+		t.Errorf("user-service: expected 1 deletion, got %d", got)
+	}
+
+	if got := len(run.broker.Published()); got != 2*published {
+		t.Fatalf("redelivery produced %d new events", got-2*published)
+	}
+}
+
+// Отказ, пришедший после успеха того же шага (ответ на повтор команды),
+// не должен запускать компенсацию.
+func TestUserDeletionSagaIgnoresStaleStepFailure(t *testing.T) {
+	run := newUserDeletionRun(t, sagaConfig())
+	run.step("delete_task_user").fail = true
+	run.start(t, "user-1", events.Trace{})
+
+	state := run.onlySaga(t)
+	if state.Status != events.SagaStatusInProgress || state.CurrentStep != "delete_task_user" {
+		t.Fatalf("expected saga to wait for delete_task_user retry, got %s at %s", state.Status, state.CurrentStep)
+	}
+
+	stale := events.NewEvent(events.TeamUserDeleteFailed, "user-1", state.ID, map[string]interface{}{
+		"error": "database is down",
+	})
+	if err := run.broker.PublishEvent(contract.TeamEventsTopic, stale); err != nil {
+		t.Fatal(err)
+	}
+	run.drain(t)
+
+	after := run.onlySaga(t)
+	if after.Status != events.SagaStatusInProgress || after.FailedStep != "delete_task_user" {
+		t.Fatalf("stale failure changed the saga: %s, failed step %q", after.Status, after.FailedStep)
+	}
+	if got := run.step("delete_team_user").compensations; got != 0 {
+		t.Fatalf("stale failure triggered %d compensations", got)
+	}
+}
+
+// Ответ участника, применённый к устаревшему состоянию, не затирает чужую
+// запись, а применяется заново к свежему.
+func TestUserDeletionSagaReappliesReplyAfterConflict(t *testing.T) {
+	run := newUserDeletionRun(t, sagaConfig())
+	// Версия 2 — сага после отправки первой команды: конфликт случится на
+	// записи, применяющей ответ на неё
+	run.storage.conflictAt = 2
+	run.start(t, "user-1", events.Trace{})
+
+	if !run.storage.raced {
+		t.Fatal("concurrent write was not injected")
+	}
+
+	state := run.onlySaga(t)
+	if state.Status != events.SagaStatusCompleted {
+		t.Fatalf("expected status %s, got %s", events.SagaStatusCompleted, state.Status)
+	}
+	for name, l := range run.participants.ledgers {
+		if l.commands != 1 {
+			t.Errorf("%s: expected 1 command, got %d", name, l.commands)
+		}
+	}
+}
+
+func TestUserDeletionSagaPropagatesTrace(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	cfg := sagaConfig()
+	cfg.MaxRetries = 0
+
+	run := newUserDeletionRun(t, cfg)
+	run.step("delete_task_user").fail = true
+	run.start(t, "user-1", events.Trace{
+		RequestID:   "req-1",
+		Traceparent: "00-" + traceID + "-00f067aa0ba902b7-01",
+	})
+
+	if state := run.onlySaga(t); state.Status != events.SagaStatusRolledBack {
+		t.Fatalf("expected status %s, got %s", events.SagaStatusRolledBack, state.Status)
+	}
+
+	// Команды, ответы из outbox участников, компенсации и итог саги
+	// относятся к одной трассе
+	for _, message := range run.broker.Published() {
+		trace := message.Event.Trace()
+		if trace.RequestID != "req-1" || trace.TraceID() != traceID {
+			t.Errorf("%s lost the trace: %+v", message.Event.Type, message.Event.Headers)
+		}
+	}
+}
+
+func lastIndex(broker *bus.Broker, eventType events.EventType) int {
+	index := -1
+	for i, message := range broker.Published() {
+		if message.Event.Type == eventType {
+			index = i
+		}
+	}
+	return index
+}
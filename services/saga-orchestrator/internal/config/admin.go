@@ -0,0 +1,6 @@
+package config
+
+type AdminConfig struct {
+	// Пустой токен отключает admin API
+	Token string `yaml:"token" env:"SAGA_ADMIN_TOKEN"`
+}
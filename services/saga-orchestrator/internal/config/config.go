@@ -8,12 +8,17 @@ import (
 )
 
 type Config struct {
-	HTTPPort    int         `yaml:"http-port"`
-	MetricsPort int         `yaml:"metrics-port"`
-	LogLevel    string      `yaml:"env" env-default:"local"`
-	Kafka       KafkaConfig `yaml:"kafka"`
-	Redis       RedisConfig `yaml:"redis"`
-	Saga        SagaConfig  `yaml:"saga"`
+	HTTPPort    int            `yaml:"http-port"`
+	MetricsPort int            `yaml:"metrics-port"`
+	LogLevel    string         `yaml:"env" env-default:"local"`
+	Kafka       KafkaConfig    `yaml:"kafka"`
+	Storage     string         `yaml:"storage" env-default:"redis"`
+	Redis       RedisConfig    `yaml:"redis"`
+	DB          DatabaseConfig `yaml:"db"`
+	Saga        SagaConfig     `yaml:"saga"`
+	Admin       AdminConfig    `yaml:"admin"`
+	Inbox       InboxConfig    `yaml:"inbox"`
+	Leader      LeaderConfig   `yaml:"leader"`
 }
 
 func MustLoad() *Config {
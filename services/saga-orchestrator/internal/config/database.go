@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+type DatabaseConfig struct {
+	Host               string        `yaml:"host"`
+	Port               int           `yaml:"port" env-default:"5432"`
+	User               string        `yaml:"user"`
+	Password           string        `yaml:"password"`
+	Name               string        `yaml:"name"`
+	MaxOpenConnections int           `yaml:"max_open_connections"`
+	MaxIdleConnections int           `yaml:"max_idle_connections"`
+	MaxLifetime        time.Duration `yaml:"max_lifetime"`
+}
@@ -0,0 +1,8 @@
+package config
+
+import "time"
+
+type InboxConfig struct {
+	// Сколько хранить ID обработанных событий
+	Retention time.Duration `yaml:"retention" env-default:"168h"`
+}
@@ -16,9 +16,16 @@ type ProducerConfig struct {
 }
 
 type ConsumerConfig struct {
-	GroupID           string        `yaml:"groupId"`
-	SessionTimeout    time.Duration `yaml:"sessionTimeout"`
-	HeartbeatInterval time.Duration `yaml:"heartbeatInterval"`
-	AutoOffsetReset   string        `yaml:"autoOffsetReset"`
-	MaxProcessingTime time.Duration `yaml:"maxProcessingTime"`
+	GroupID           string           `yaml:"groupId"`
+	SessionTimeout    time.Duration    `yaml:"sessionTimeout"`
+	HeartbeatInterval time.Duration    `yaml:"heartbeatInterval"`
+	AutoOffsetReset   string           `yaml:"autoOffsetReset"`
+	MaxProcessingTime time.Duration    `yaml:"maxProcessingTime"`
+	DeadLetter        DeadLetterConfig `yaml:"deadLetter"`
+}
+
+type DeadLetterConfig struct {
+	// Сколько раз обработать сообщение, прежде чем отправить его в <topic>.dlq
+	MaxAttempts int           `yaml:"maxAttempts" env-default:"3"`
+	Backoff     time.Duration `yaml:"backoff" env-default:"500ms"`
 }
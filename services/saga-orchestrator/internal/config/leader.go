@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+type LeaderConfig struct {
+	// Выключенные выборы допустимы только при одной реплике оркестратора
+	Enabled       bool          `yaml:"enabled" env-default:"true"`
+	Key           string        `yaml:"key" env-default:"saga:leader"`
+	TTL           time.Duration `yaml:"ttl" env-default:"15s"`
+	RenewInterval time.Duration `yaml:"renewInterval" env-default:"5s"`
+}
@@ -3,8 +3,12 @@ package config
 import "time"
 
 type SagaConfig struct {
-	Timeout         time.Duration `yaml:"timeout"`
-	RetryInterval   time.Duration `yaml:"retryInterval"`
-	MaxRetries      int           `yaml:"maxRetries"`
-	CleanupInterval time.Duration `yaml:"cleanupInterval"`
+	Timeout                  time.Duration `yaml:"timeout"`
+	RetryInterval            time.Duration `yaml:"retryInterval"`
+	MaxRetryBackoff          time.Duration `yaml:"maxRetryBackoff" env-default:"5m"`
+	RetryPollPeriod          time.Duration `yaml:"retryPollPeriod" env-default:"1s"`
+	MaxRetries               int           `yaml:"maxRetries"`
+	MaxCompensationRetries   int           `yaml:"maxCompensationRetries" env-default:"5"`
+	CleanupInterval          time.Duration `yaml:"cleanupInterval"`
+	StepTimeoutCheckInterval time.Duration `yaml:"stepTimeoutCheckInterval" env-default:"5s"`
 }
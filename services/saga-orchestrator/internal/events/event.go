@@ -1,13 +1,24 @@
 package events
 
-import "time"
-
-type Event struct {
-	ID        string                 `json:"id"`
-	Type      EventType              `json:"type"`
-	UserID    string                 `json:"user_id"`
-	SagaID    string                 `json:"saga_id"`
-	Timestamp time.Time              `json:"timestamp"`
-	Data      map[string]interface{} `json:"data"`
-	Headers   map[string]string      `json:"headers"`
+import contract "shiroyama/events"
+
+// Event и типы событий определены в общем модуле контрактов shiroyama/events.
+// Здесь только псевдонимы, чтобы оркестратор собирался против общего контракта.
+type (
+	Event     = contract.Event
+	EventType = contract.EventType
+	Trace     = contract.Trace
+)
+
+func NewEvent(eventType EventType, userID, sagaID string, data map[string]interface{}) Event {
+	return contract.New(eventType, userID, sagaID, data)
+}
+
+// Marshal и Unmarshal проверяют событие по JSON Schema контракта.
+func Marshal(event Event) ([]byte, error) {
+	return contract.Marshal(event)
+}
+
+func Unmarshal(payload []byte) (Event, error) {
+	return contract.Unmarshal(payload)
 }
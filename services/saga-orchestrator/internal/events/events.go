@@ -1,39 +1,74 @@
 package events
 
-type EventType string
+import contract "shiroyama/events"
 
 const (
-	UserDeletionRequested EventType = "UserDeletionRequested"
-	UserDeletionCompleted EventType = "UserDeletionCompleted"
-	UserDeletionRollback  EventType = "UserDeletionRollback"
-
-	AuthUserDeleteRequested EventType = "AuthUserDeleteRequested"
-	AuthUserDeleted         EventType = "AuthUserDeleted"
-	AuthUserDeleteFailed    EventType = "AuthUserDeleteFailed"
-	AuthUserDeleteRollback  EventType = "AuthUserDeleteRollback"
-
-	TeamUserDeleteRequested EventType = "TeamUserDeleteRequested"
-	TeamUserDeleted         EventType = "TeamUserDeleted"
-	TeamUserDeleteFailed    EventType = "TeamUserDeleteFailed"
-	TeamUserDeleteRollback  EventType = "TeamUserDeleteRollback"
-
-	BoardUserDeleteRequested EventType = "BoardUserDeleteRequested"
-	BoardUserDeleted         EventType = "BoardUserDeleted"
-	BoardUserDeleteFailed    EventType = "BoardUserDeleteFailed"
-	BoardUserDeleteRollback  EventType = "BoardUserDeleteRollback"
-
-	TaskUserDeleteRequested EventType = "TaskUserDeleteRequested"
-	TaskUserDeleted         EventType = "TaskUserDeleted"
-	TaskUserDeleteFailed    EventType = "TaskUserDeleteFailed"
-	TaskUserDeleteRollback  EventType = "TaskUserDeleteRollback"
+	UserDeletionRequested = contract.UserDeletionRequested
+	UserDeletionCompleted = contract.UserDeletionCompleted
+	UserDeletionRollback  = contract.UserDeletionRollback
+
+	AuthUserDeleteRequested         = contract.AuthUserDeleteRequested
+	AuthUserDeleted                 = contract.AuthUserDeleted
+	AuthUserDeleteFailed            = contract.AuthUserDeleteFailed
+	AuthUserDeleteRollback          = contract.AuthUserDeleteRollback
+	AuthUserDeleteRollbackCompleted = contract.AuthUserDeleteRollbackCompleted
+	AuthUserDeleteRollbackFailed    = contract.AuthUserDeleteRollbackFailed
+
+	TeamUserDeleteRequested         = contract.TeamUserDeleteRequested
+	TeamUserDeleted                 = contract.TeamUserDeleted
+	TeamUserDeleteFailed            = contract.TeamUserDeleteFailed
+	TeamUserDeleteRollback          = contract.TeamUserDeleteRollback
+	TeamUserDeleteRollbackCompleted = contract.TeamUserDeleteRollbackCompleted
+	TeamUserDeleteRollbackFailed    = contract.TeamUserDeleteRollbackFailed
+
+	BoardUserDeleteRequested         = contract.BoardUserDeleteRequested
+	BoardUserDeleted                 = contract.BoardUserDeleted
+	BoardUserDeleteFailed            = contract.BoardUserDeleteFailed
+	BoardUserDeleteRollback          = contract.BoardUserDeleteRollback
+	BoardUserDeleteRollbackCompleted = contract.BoardUserDeleteRollbackCompleted
+	BoardUserDeleteRollbackFailed    = contract.BoardUserDeleteRollbackFailed
+
+	TaskUserDeleteRequested         = contract.TaskUserDeleteRequested
+	TaskUserDeleted                 = contract.TaskUserDeleted
+	TaskUserDeleteFailed            = contract.TaskUserDeleteFailed
+	TaskUserDeleteRollback          = contract.TaskUserDeleteRollback
+	TaskUserDeleteRollbackCompleted = contract.TaskUserDeleteRollbackCompleted
+	TaskUserDeleteRollbackFailed    = contract.TaskUserDeleteRollbackFailed
+
+	TeamDeletionRequested = contract.TeamDeletionRequested
+	TeamDeletionCompleted = contract.TeamDeletionCompleted
+	TeamDeletionRollback  = contract.TeamDeletionRollback
+
+	TeamDeleteRequested         = contract.TeamDeleteRequested
+	TeamDeleted                 = contract.TeamDeleted
+	TeamDeleteFailed            = contract.TeamDeleteFailed
+	TeamDeleteRollback          = contract.TeamDeleteRollback
+	TeamDeleteRollbackCompleted = contract.TeamDeleteRollbackCompleted
+	TeamDeleteRollbackFailed    = contract.TeamDeleteRollbackFailed
+
+	BoardTeamDeleteRequested         = contract.BoardTeamDeleteRequested
+	BoardTeamDeleted                 = contract.BoardTeamDeleted
+	BoardTeamDeleteFailed            = contract.BoardTeamDeleteFailed
+	BoardTeamDeleteRollback          = contract.BoardTeamDeleteRollback
+	BoardTeamDeleteRollbackCompleted = contract.BoardTeamDeleteRollbackCompleted
+	BoardTeamDeleteRollbackFailed    = contract.BoardTeamDeleteRollbackFailed
+
+	TaskTeamDeleteRequested         = contract.TaskTeamDeleteRequested
+	TaskTeamDeleted                 = contract.TaskTeamDeleted
+	TaskTeamDeleteFailed            = contract.TaskTeamDeleteFailed
+	TaskTeamDeleteRollback          = contract.TaskTeamDeleteRollback
+	TaskTeamDeleteRollbackCompleted = contract.TaskTeamDeleteRollbackCompleted
+	TaskTeamDeleteRollbackFailed    = contract.TaskTeamDeleteRollbackFailed
 )
 
 type SagaStatus string
 
 const (
-	SagaStatusPending     SagaStatus = "pending"
-	SagaStatusInProgress  SagaStatus = "in_progress"
-	SagaStatusCompleted   SagaStatus = "completed"
-	SagaStatusRollingBack SagaStatus = "rolling_back"
-	SagaStatusRolledBack  SagaStatus = "rolled_back"
+	SagaStatusPending            SagaStatus = "pending"
+	SagaStatusInProgress         SagaStatus = "in_progress"
+	SagaStatusCompleted          SagaStatus = "completed"
+	SagaStatusRollingBack        SagaStatus = "rolling_back"
+	SagaStatusRolledBack         SagaStatus = "rolled_back"
+	SagaStatusResolved           SagaStatus = "resolved"
+	SagaStatusCompensationFailed SagaStatus = "compensation_failed"
 )
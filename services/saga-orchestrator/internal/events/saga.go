@@ -1,17 +1,51 @@
 package events
 
-import "time"
+import (
+	"errors"
+	"time"
+
+	contract "shiroyama/events"
+)
+
+var ErrSagaNotFound = errors.New("saga not found")
+
+// ErrSagaConflict возвращает Storage.SaveSagaState, если сагу успел изменить
+// другой обработчик: состояние нужно перечитать и применить событие заново.
+var ErrSagaConflict = errors.New("saga state was modified concurrently")
 
 type SagaState struct {
 	ID             string
+	Type           string
 	UserID         string
 	Status         SagaStatus
 	CurrentStep    string
+	StepStartedAt  time.Time
+	StepDeadline   time.Time
 	FailedStep     string
 	CompletedSteps []string
-	RetryCount     int
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	ExpiresAt      time.Time
-	Metadata       map[string]string
+	// Данные из ответов участников по шагам; возвращаются в событии компенсации шага
+	StepResults   map[string]map[string]interface{}
+	RetryCount    int
+	StepRetries   map[string]int
+	ScheduledStep string
+	NextRetryAt   time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ExpiresAt     time.Time
+	// Параметры саги из события-триггера (Definition.Params), например team_id
+	Metadata map[string]string
+	// Шаги, откат которых участник ещё не подтвердил, в порядке компенсации:
+	// первый из них отправлен и ждёт ответа
+	PendingCompensations []string
+	CompensationRetries  map[string]int
+	// Заголовки события-триггера: по ним команды и итоговые события саги
+	// продолжают трассу запроса, даже если отправлены по таймауту
+	Headers map[string]string
+	// Номер сохранённой версии: SaveSagaState записывает состояние, только
+	// если в хранилище та же версия, и увеличивает его
+	Version int
+}
+
+func (s *SagaState) Trace() Trace {
+	return contract.TraceFromHeaders(s.Headers)
 }
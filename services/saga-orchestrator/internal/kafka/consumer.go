@@ -2,24 +2,25 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/IBM/sarama"
 	"saga-orchestrator/internal/config"
 	"saga-orchestrator/internal/events"
+	"saga-orchestrator/internal/metrics"
+	contract "shiroyama/events"
+	"shiroyama/events/bus"
+	"shiroyama/messaging/dlq"
 )
 
-type EventHandler interface {
-	HandleEvent(ctx context.Context, event events.Event) error
-}
-
 type ConsumerGroup struct {
 	consumer sarama.ConsumerGroup
-	handler  EventHandler
+	handler  bus.Handler
+	dlq      *dlq.Queue
 	log      *slog.Logger
 	topics   []string
 	cfg      config.ConsumerConfig
@@ -30,7 +31,8 @@ func NewConsumerGroup(
 	brokers []string,
 	groupID string,
 	consumerConfig config.ConsumerConfig,
-	handler EventHandler,
+	handler bus.Handler,
+	producer *Producer,
 	log *slog.Logger,
 ) (*ConsumerGroup, error) {
 	cfg := sarama.NewConfig()
@@ -49,16 +51,26 @@ func NewConsumerGroup(
 	}
 
 	topics := []string{
-		"user-deletion-saga",
-		"auth-service-events",
-		"team-service-events",
-		"board-service-events",
-		"task-service-events",
+		contract.UserDeletionSagaTopic,
+		contract.TeamDeletionSagaTopic,
+		contract.AuthEventsTopic,
+		contract.TeamEventsTopic,
+		contract.BoardEventsTopic,
+		contract.TaskEventsTopic,
 	}
 
+	deadLetters := dlq.New(producer, groupID, dlq.Config{
+		MaxAttempts: consumerConfig.DeadLetter.MaxAttempts,
+		Backoff:     consumerConfig.DeadLetter.Backoff,
+		OnDeadLetter: func(message *sarama.ConsumerMessage) {
+			metrics.DeadLettered.WithLabelValues(message.Topic).Inc()
+		},
+	}, log)
+
 	return &ConsumerGroup{
 		consumer: consumer,
 		handler:  handler,
+		dlq:      deadLetters,
 		log:      log,
 		topics:   topics,
 		cfg:      consumerConfig,
@@ -75,7 +87,7 @@ func (cg *ConsumerGroup) Start(ctx context.Context) error {
 				return
 			case err := <-cg.consumer.Errors():
 				if err != nil {
-					cg.log.Error("Consumer error", err)
+					cg.log.Error("Consumer error", "error", err)
 				}
 			}
 		}
@@ -90,7 +102,7 @@ func (cg *ConsumerGroup) Start(ctx context.Context) error {
 				return
 			default:
 				if err := cg.consumer.Consume(ctx, cg.topics, cg); err != nil {
-					cg.log.Info("Info from consumer", err)
+					cg.log.Info("Info from consumer", "error", err)
 					time.Sleep(time.Second)
 				}
 			}
@@ -118,9 +130,20 @@ func (cg *ConsumerGroup) ConsumeClaim(session sarama.ConsumerGroupSession, claim
 				return nil
 			}
 
-			if err := cg.processMessage(session.Context(), message); err != nil {
-				cg.log.Error("Error processing message", err)
-				continue
+			metrics.ConsumerLag.
+				WithLabelValues(message.Topic, strconv.Itoa(int(message.Partition))).
+				Set(float64(claim.HighWaterMarkOffset() - message.Offset - 1))
+
+			if err := cg.dlq.Process(session.Context(), message, cg.processMessage); err != nil {
+				// Сообщение не обработано и не попало в DLQ — не коммитим offset,
+				// его перечитают после ребалансировки или рестарта
+				cg.log.Error("Error processing message",
+					"topic", message.Topic,
+					"partition", message.Partition,
+					"offset", message.Offset,
+					"error", err,
+				)
+				return err
 			}
 
 			session.MarkMessage(message, "")
@@ -137,27 +160,29 @@ func (cg *ConsumerGroup) processMessage(ctx context.Context, message *sarama.Con
 	processingCtx, cancel := context.WithTimeout(ctx, cg.cfg.MaxProcessingTime)
 	defer cancel()
 
-	var event events.Event
-	if err := json.Unmarshal(message.Value, &event); err != nil {
-		//metrics.EventsProcessed.WithLabelValues("unknown", "unmarshal_error").Inc()
-		return fmt.Errorf("failed to unmarshal event: %w", err)
+	// Событие, нарушающее контракт, не починится повтором — сразу в DLQ
+	event, err := events.Unmarshal(message.Value)
+	if err != nil {
+		metrics.EventsProcessed.WithLabelValues("unknown", "unmarshal_error").Inc()
+		return dlq.Permanent(fmt.Errorf("failed to unmarshal event: %w", err))
 	}
 
 	cg.log.Debug("Processing event",
 		"type", event.Type,
 		"saga", event.SagaID,
 		"user", event.UserID,
+		"trace", event.Trace(),
 	)
 
 	if err := cg.handler.HandleEvent(processingCtx, event); err != nil {
-		//metrics.EventsProcessed.WithLabelValues(string(event.Type), "handler_error").Inc()
+		metrics.EventsProcessed.WithLabelValues(string(event.Type), "handler_error").Inc()
 		return fmt.Errorf("handler error: %w", err)
 	}
 
-	//metrics.EventsProcessed.WithLabelValues(string(event.Type), "success").Inc()
+	metrics.EventsProcessed.WithLabelValues(string(event.Type), "success").Inc()
 
 	duration := time.Since(start)
-	cg.log.Debug("Event processed in", duration, ":", event.Type)
+	cg.log.Debug("Event processed", "type", event.Type, "duration", duration)
 
 	return nil
 }
@@ -1,9 +1,9 @@
 package kafka
 
 import (
-	"encoding/json"
 	"fmt"
 	"saga-orchestrator/internal/events"
+	"saga-orchestrator/internal/metrics"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -47,15 +47,15 @@ func NewProducer(brokers []string, producerCfg config.ProducerConfig) (*Producer
 	}, nil
 }
 func (p *Producer) PublishEvent(topic string, event events.Event) error {
-	eventBytes, err := json.Marshal(event)
+	eventBytes, err := events.Marshal(event)
 	if err != nil {
-		//metrics.EventsProcessed.WithLabelValues(string(event.Type), "marshal_error").Inc()
+		metrics.EventsPublished.WithLabelValues(string(event.Type), "marshal_error").Inc()
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
 	message := &sarama.ProducerMessage{
 		Topic:     topic,
-		Key:       sarama.StringEncoder(event.UserID),
+		Key:       sarama.StringEncoder(event.Key()),
 		Value:     sarama.ByteEncoder(eventBytes),
 		Timestamp: event.Timestamp,
 		Headers: []sarama.RecordHeader{
@@ -79,9 +79,11 @@ func (p *Producer) PublishEvent(topic string, event events.Event) error {
 
 	_, _, err = p.producer.SendMessage(message)
 	if err != nil {
+		metrics.EventsPublished.WithLabelValues(string(event.Type), "send_error").Inc()
 		return fmt.Errorf("failed to send message: %w", err)
 	}
 
+	metrics.EventsPublished.WithLabelValues(string(event.Type), "success").Inc()
 	return nil
 }
 
@@ -109,6 +111,15 @@ func (p *Producer) PublishEventWithRetry(topic string, event events.Event, maxRe
 	return fmt.Errorf("failed after %d attempts: %w", maxRetries+1, lastErr)
 }
 
+// SendMessage отправляет готовое сообщение как есть — нужно для DLQ и replay,
+// где значение и заголовки не должны меняться.
+func (p *Producer) SendMessage(message *sarama.ProducerMessage) error {
+	if _, _, err := p.producer.SendMessage(message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
 func (p *Producer) Close() error {
 	return p.producer.Close()
 }
@@ -0,0 +1,188 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"saga-orchestrator/internal/config"
+	"saga-orchestrator/internal/metrics"
+)
+
+// Продлеваем и снимаем lease, только если он всё ещё наш: иначе реплика,
+// потерявшая лидерство, могла бы отобрать его у новой.
+var (
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// Standalone считает реплику лидером всегда — для запуска без выборов.
+type Standalone struct{}
+
+func (Standalone) IsLeader() bool {
+	return true
+}
+
+// RedisLease выбирает лидера через ключ с TTL в Redis. Реплика считает себя
+// лидером до момента, когда lease истёк бы по её собственным часам, поэтому
+// после потери связи с Redis она перестаёт работать раньше, чем ключ
+// достанется другой реплике.
+type RedisLease struct {
+	client        *redis.Client
+	key           string
+	id            string
+	ttl           time.Duration
+	renewInterval time.Duration
+	logger        *slog.Logger
+
+	mu         sync.Mutex
+	validUntil time.Time
+}
+
+func NewRedisLease(redisCfg config.RedisConfig, cfg config.LeaderConfig, logger *slog.Logger) (*RedisLease, error) {
+	if cfg.RenewInterval <= 0 || cfg.RenewInterval >= cfg.TTL {
+		return nil, fmt.Errorf("leader renew interval %s must be positive and shorter than ttl %s", cfg.RenewInterval, cfg.TTL)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", redisCfg.Host, redisCfg.Port),
+		Password: redisCfg.Password,
+		DB:       redisCfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &RedisLease{
+		client:        client,
+		key:           cfg.Key,
+		id:            hostname + "-" + uuid.New().String(),
+		ttl:           cfg.TTL,
+		renewInterval: cfg.RenewInterval,
+		logger:        logger.With(slog.String("component", "leader")),
+	}, nil
+}
+
+func (l *RedisLease) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return time.Now().Before(l.validUntil)
+}
+
+// Run пытается захватить или продлить lease каждые RenewInterval, пока не
+// отменён ctx, а при остановке отпускает его, чтобы другая реплика не ждала TTL.
+func (l *RedisLease) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.renewInterval)
+	defer ticker.Stop()
+
+	l.tick(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.release()
+			return
+		case <-ticker.C:
+			l.tick(ctx)
+		}
+	}
+}
+
+func (l *RedisLease) Close() error {
+	return l.client.Close()
+}
+
+func (l *RedisLease) tick(ctx context.Context) {
+	wasLeader := l.IsLeader()
+
+	// Срок отсчитываем от момента до запроса, чтобы локальный lease истекал не позже ключа в Redis
+	started := time.Now()
+	held, err := l.acquire(ctx)
+	if err != nil {
+		// Lease истечёт сам, если Redis не ответит до validUntil
+		l.logger.Error("Failed to renew leader lease", slog.Any("error", err))
+	} else {
+		l.mu.Lock()
+		if held {
+			l.validUntil = started.Add(l.ttl)
+		} else {
+			l.validUntil = time.Time{}
+		}
+		l.mu.Unlock()
+	}
+
+	isLeader := l.IsLeader()
+	if isLeader != wasLeader {
+		if isLeader {
+			l.logger.Info("Acquired leadership", slog.String("id", l.id))
+		} else {
+			l.logger.Warn("Lost leadership", slog.String("id", l.id))
+		}
+	}
+
+	if isLeader {
+		metrics.Leader.Set(1)
+	} else {
+		metrics.Leader.Set(0)
+	}
+}
+
+func (l *RedisLease) acquire(ctx context.Context) (bool, error) {
+	renewed, err := renewScript.Run(ctx, l.client, []string{l.key}, l.id, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lease: %w", err)
+	}
+	if renewed == 1 {
+		return true, nil
+	}
+
+	acquired, err := l.client.SetNX(ctx, l.key, l.id, l.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	return acquired, nil
+}
+
+func (l *RedisLease) release() {
+	l.mu.Lock()
+	l.validUntil = time.Time{}
+	l.mu.Unlock()
+	metrics.Leader.Set(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	released, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.id).Int()
+	if err != nil {
+		l.logger.Error("Failed to release leader lease", slog.Any("error", err))
+		return
+	}
+
+	if released == 1 {
+		l.logger.Info("Released leadership", slog.String("id", l.id))
+	}
+}
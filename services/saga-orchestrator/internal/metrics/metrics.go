@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "saga_orchestrator"
+
+var (
+	SagasStarted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sagas_started_total",
+		Help:      "Number of started sagas.",
+	}, []string{"saga_type"})
+
+	SagasCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sagas_completed_total",
+		Help:      "Number of sagas that completed successfully.",
+	}, []string{"saga_type"})
+
+	SagasFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sagas_failed_total",
+		Help:      "Number of sagas that started compensation, by the step that failed.",
+	}, []string{"saga_type", "step"})
+
+	SagaDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "saga_duration_seconds",
+		Help:      "Time from saga start to completion or rollback.",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"saga_type", "outcome"})
+
+	StepDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "step_duration_seconds",
+		Help:      "Time from sending a step command to receiving the participant reply.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"saga_type", "step", "outcome"})
+
+	StepRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "step_retries_total",
+		Help:      "Number of step retries.",
+	}, []string{"saga_type", "step"})
+
+	StepTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "step_timeouts_total",
+		Help:      "Number of steps whose participant did not reply within the step timeout.",
+	}, []string{"saga_type", "step"})
+
+	CompensationRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "compensation_retries_total",
+		Help:      "Number of compensation retries after a participant failed or did not confirm a rollback.",
+	}, []string{"saga_type", "step"})
+
+	// Сага, застрявшая в compensation_failed, требует ручного разбора —
+	// на рост этого счётчика должен срабатывать пейджинг дежурного.
+	CompensationsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "compensations_failed_total",
+		Help:      "Number of sagas moved to compensation_failed after exhausting compensation retries.",
+	}, []string{"saga_type", "step"})
+
+	DeadLettered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dead_lettered_total",
+		Help:      "Number of consumed messages moved to a dead letter topic.",
+	}, []string{"topic"})
+
+	EventsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_processed_total",
+		Help:      "Number of consumed Kafka events by processing result.",
+	}, []string{"event_type", "status"})
+
+	EventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_published_total",
+		Help:      "Number of events published to Kafka by result.",
+	}, []string{"event_type", "status"})
+
+	ConsumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "consumer_lag_messages",
+		Help:      "Messages between the last consumed offset and the partition high watermark.",
+	}, []string{"topic", "partition"})
+
+	Leader = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "leader",
+		Help:      "1 if this replica holds the lease for timeout and retry handling.",
+	})
+)
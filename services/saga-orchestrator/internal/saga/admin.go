@@ -0,0 +1,132 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"saga-orchestrator/internal/events"
+	"saga-orchestrator/internal/metrics"
+)
+
+// ErrInvalidSagaState возвращается, когда ручное действие нельзя применить
+// к саге в её текущем статусе.
+var ErrInvalidSagaState = errors.New("invalid saga state")
+
+// isTerminal сообщает, что оркестратор больше не двигает сагу сам. Из
+// compensation_failed её выводит только дежурный.
+func isTerminal(status events.SagaStatus) bool {
+	return status == events.SagaStatusCompleted ||
+		status == events.SagaStatusRolledBack ||
+		status == events.SagaStatusResolved ||
+		status == events.SagaStatusCompensationFailed
+}
+
+// RetryStep повторно отправляет команду шага. Если шаг не указан, повторяется
+// упавший шаг, а при его отсутствии — текущий.
+func (o *Orchestrator) RetryStep(ctx context.Context, sagaID, stepName string) error {
+	sagaState, err := o.storage.GetSagaState(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+
+	if isTerminal(sagaState.Status) || sagaState.Status == events.SagaStatusRollingBack {
+		return fmt.Errorf("%w: cannot retry saga in status %s", ErrInvalidSagaState, sagaState.Status)
+	}
+
+	def, err := o.definitionFor(sagaState)
+	if err != nil {
+		return err
+	}
+
+	if stepName == "" {
+		stepName = sagaState.FailedStep
+	}
+	if stepName == "" {
+		stepName = sagaState.CurrentStep
+	}
+
+	step, ok := def.Step(stepName)
+	if !ok {
+		return fmt.Errorf("%w: unknown step %q", ErrInvalidSagaState, stepName)
+	}
+	if o.isStepCompleted(sagaState, step.Name) {
+		return fmt.Errorf("%w: step %s is already completed", ErrInvalidSagaState, step.Name)
+	}
+
+	sagaState.FailedStep = ""
+	sagaState.ExpiresAt = o.now().Add(o.config.Timeout)
+
+	o.logger.Info("Manual step retry",
+		slog.String("saga_id", sagaState.ID),
+		slog.String("step", step.Name),
+	)
+
+	return o.executeStep(ctx, sagaState, step)
+}
+
+// ForceCompensation откатывает все выполненные шаги саги независимо от того,
+// упал ли какой-либо из них. Для саги в compensation_failed откат продолжается
+// с неподтверждённого шага.
+func (o *Orchestrator) ForceCompensation(ctx context.Context, sagaID string) error {
+	sagaState, err := o.storage.GetSagaState(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+
+	if sagaState.Status == events.SagaStatusRolledBack || sagaState.Status == events.SagaStatusResolved {
+		return fmt.Errorf("%w: cannot compensate saga in status %s", ErrInvalidSagaState, sagaState.Status)
+	}
+
+	def, err := o.definitionFor(sagaState)
+	if err != nil {
+		return err
+	}
+
+	o.logger.Warn("Manual compensation", slog.String("saga_id", sagaState.ID))
+
+	if sagaState.Status == events.SagaStatusCompensationFailed {
+		sagaState.CompensationRetries = nil
+	} else {
+		metrics.SagasFailed.WithLabelValues(def.Type, "manual").Inc()
+	}
+
+	return o.startCompensation(ctx, sagaState, def, "")
+}
+
+// MarkResolved закрывает зависшую сагу без отправки команд участникам —
+// например, когда дежурный поправил данные вручную.
+func (o *Orchestrator) MarkResolved(ctx context.Context, sagaID, reason string) error {
+	sagaState, err := o.storage.GetSagaState(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+
+	if isTerminal(sagaState.Status) && sagaState.Status != events.SagaStatusCompensationFailed {
+		return fmt.Errorf("%w: saga is already %s", ErrInvalidSagaState, sagaState.Status)
+	}
+
+	if sagaState.Metadata == nil {
+		sagaState.Metadata = make(map[string]string)
+	}
+	sagaState.Metadata["resolved_from"] = string(sagaState.Status)
+	sagaState.Metadata["resolved_at"] = o.now().Format(time.RFC3339)
+	if reason != "" {
+		sagaState.Metadata["resolution"] = reason
+	}
+
+	sagaState.Status = events.SagaStatusResolved
+	sagaState.UpdatedAt = o.now()
+
+	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
+		return fmt.Errorf("failed to resolve saga: %w", err)
+	}
+
+	o.logger.Warn("Saga marked resolved",
+		slog.String("saga_id", sagaState.ID),
+		slog.String("reason", reason),
+	)
+	return nil
+}
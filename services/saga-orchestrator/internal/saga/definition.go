@@ -0,0 +1,239 @@
+package saga
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"saga-orchestrator/internal/events"
+)
+
+// Step описывает один шаг саги: команду участнику, ответы, которые он
+// присылает, и событие компенсации с ответами на него.
+type Step struct {
+	Name           string
+	Topic          string
+	EventType      events.EventType
+	CompensateType events.EventType
+	SuccessType    events.EventType
+	FailureType    events.EventType
+	// Участник подтверждает компенсацию одним из этих событий; пока ответа
+	// нет, откат саги не считается завершённым.
+	CompensatedType        events.EventType
+	CompensationFailedType events.EventType
+	Timeout                time.Duration
+	// Inputs — ключи data, которые передаются в команду и компенсацию шага:
+	// параметры саги или значения из ответов уже выполненных шагов.
+	Inputs []string
+}
+
+// Definition декларативно описывает распределённый процесс: каким событием
+// он запускается, какие шаги выполняются по порядку и какие события
+// публикуются по его завершению или откату.
+type Definition struct {
+	Type           string
+	TriggerType    events.EventType
+	CompletedType  events.EventType
+	RolledBackType events.EventType
+	Topic          string
+	// Params — обязательные строковые ключи data события-триггера. Они
+	// сохраняются в Metadata саги и публикуются в её итоговых событиях.
+	Params []string
+	Steps  []Step
+}
+
+func (d *Definition) Step(name string) (Step, bool) {
+	for _, step := range d.Steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+func (d *Definition) validate() error {
+	if d.Type == "" {
+		return fmt.Errorf("saga type is required")
+	}
+	if d.TriggerType == "" {
+		return fmt.Errorf("saga %s: trigger event type is required", d.Type)
+	}
+	if d.Topic == "" {
+		return fmt.Errorf("saga %s: topic is required", d.Type)
+	}
+	for _, eventType := range []events.EventType{d.TriggerType, d.CompletedType, d.RolledBackType} {
+		if eventType != "" && !eventType.IsKnown() {
+			return fmt.Errorf("saga %s: event %s is not in the contract", d.Type, eventType)
+		}
+	}
+	if len(d.Steps) == 0 {
+		return fmt.Errorf("saga %s: at least one step is required", d.Type)
+	}
+	for _, param := range d.Params {
+		if param == "" {
+			return fmt.Errorf("saga %s: param name is required", d.Type)
+		}
+	}
+
+	names := make(map[string]struct{}, len(d.Steps))
+	replies := make(map[events.EventType]struct{}, len(d.Steps)*4)
+
+	for _, step := range d.Steps {
+		if step.Name == "" || step.Topic == "" || step.EventType == "" {
+			return fmt.Errorf("saga %s: step name, topic and event type are required", d.Type)
+		}
+		if step.SuccessType == "" || step.FailureType == "" {
+			return fmt.Errorf("saga %s: step %s must declare success and failure events", d.Type, step.Name)
+		}
+		hasCompensation := step.CompensateType != ""
+		if hasCompensation != (step.CompensatedType != "") || hasCompensation != (step.CompensationFailedType != "") {
+			return fmt.Errorf("saga %s: step %s must declare compensation together with its reply events", d.Type, step.Name)
+		}
+		if _, ok := names[step.Name]; ok {
+			return fmt.Errorf("saga %s: duplicate step %s", d.Type, step.Name)
+		}
+		for _, eventType := range []events.EventType{
+			step.EventType, step.CompensateType, step.SuccessType, step.FailureType,
+			step.CompensatedType, step.CompensationFailedType,
+		} {
+			if eventType != "" && !eventType.IsKnown() {
+				return fmt.Errorf("saga %s: step %s uses event %s that is not in the contract", d.Type, step.Name, eventType)
+			}
+		}
+		names[step.Name] = struct{}{}
+
+		for _, reply := range []events.EventType{step.SuccessType, step.FailureType, step.CompensatedType, step.CompensationFailedType} {
+			if reply == "" {
+				continue
+			}
+			if _, ok := replies[reply]; ok {
+				return fmt.Errorf("saga %s: reply event %s is used by more than one step", d.Type, reply)
+			}
+			replies[reply] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+type stepOutcome int
+
+const (
+	stepSucceeded stepOutcome = iota
+	stepFailed
+	compensationSucceeded
+	compensationFailed
+)
+
+type replyRoute struct {
+	sagaType string
+	step     string
+	outcome  stepOutcome
+}
+
+// Registry хранит определения саг и индексирует их по событию-триггеру и
+// по ответам участников.
+type Registry struct {
+	definitions map[string]*Definition
+	triggers    map[events.EventType]*Definition
+	replies     map[events.EventType][]replyRoute
+}
+
+func NewRegistry(definitions ...Definition) (*Registry, error) {
+	registry := &Registry{
+		definitions: make(map[string]*Definition),
+		triggers:    make(map[events.EventType]*Definition),
+		replies:     make(map[events.EventType][]replyRoute),
+	}
+
+	for _, definition := range definitions {
+		if err := registry.Register(definition); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+func (r *Registry) Register(definition Definition) error {
+	if err := definition.validate(); err != nil {
+		return err
+	}
+
+	if _, ok := r.definitions[definition.Type]; ok {
+		return fmt.Errorf("saga %s is already registered", definition.Type)
+	}
+
+	if existing, ok := r.triggers[definition.TriggerType]; ok {
+		return fmt.Errorf("trigger %s is already used by saga %s", definition.TriggerType, existing.Type)
+	}
+
+	def := definition
+	r.definitions[def.Type] = &def
+	r.triggers[def.TriggerType] = &def
+
+	for _, step := range def.Steps {
+		r.replies[step.SuccessType] = append(r.replies[step.SuccessType], replyRoute{
+			sagaType: def.Type,
+			step:     step.Name,
+			outcome:  stepSucceeded,
+		})
+		r.replies[step.FailureType] = append(r.replies[step.FailureType], replyRoute{
+			sagaType: def.Type,
+			step:     step.Name,
+			outcome:  stepFailed,
+		})
+
+		if step.CompensateType == "" {
+			continue
+		}
+		r.replies[step.CompensatedType] = append(r.replies[step.CompensatedType], replyRoute{
+			sagaType: def.Type,
+			step:     step.Name,
+			outcome:  compensationSucceeded,
+		})
+		r.replies[step.CompensationFailedType] = append(r.replies[step.CompensationFailedType], replyRoute{
+			sagaType: def.Type,
+			step:     step.Name,
+			outcome:  compensationFailed,
+		})
+	}
+
+	return nil
+}
+
+func (r *Registry) Get(sagaType string) (*Definition, bool) {
+	definition, ok := r.definitions[sagaType]
+	return definition, ok
+}
+
+func (r *Registry) ByTrigger(eventType events.EventType) (*Definition, bool) {
+	definition, ok := r.triggers[eventType]
+	return definition, ok
+}
+
+func (r *Registry) IsReply(eventType events.EventType) bool {
+	_, ok := r.replies[eventType]
+	return ok
+}
+
+// routeReply находит шаг саги данного типа, к которому относится ответ участника.
+func (r *Registry) routeReply(sagaType string, eventType events.EventType) (string, stepOutcome, bool) {
+	for _, rt := range r.replies[eventType] {
+		if rt.sagaType == sagaType {
+			return rt.step, rt.outcome, true
+		}
+	}
+	return "", stepSucceeded, false
+}
+
+func (r *Registry) Definitions() []*Definition {
+	definitions := make([]*Definition, 0, len(r.definitions))
+	for _, definition := range r.definitions {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Type < definitions[j].Type
+	})
+	return definitions
+}
@@ -0,0 +1,10 @@
+package saga
+
+// DefaultDefinitions возвращает все саги, которые поддерживает оркестратор.
+// Новый процесс добавляется отдельным файлом с определением и строкой здесь.
+func DefaultDefinitions() []Definition {
+	return []Definition{
+		userDeletionDefinition(),
+		teamDeletionDefinition(),
+	}
+}
@@ -2,343 +2,676 @@ package saga
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"saga-orchestrator/internal/config"
 	"saga-orchestrator/internal/events"
-	"saga-orchestrator/internal/kafka"
+	"saga-orchestrator/internal/metrics"
+	"shiroyama/events/bus"
 )
 
+// Сколько раз ответ участника применяется заново после конфликта версий,
+// прежде чем ошибка уйдёт в обработку повторов консьюмера.
+const maxConflictRetries = 3
+
+// Storage хранит состояние саг. SaveSagaState — compare-and-set по
+// SagaState.Version: событие, таймаут, повтор и ручное действие могут менять
+// одну сагу одновременно, и запись по устаревшему состоянию возвращает
+// events.ErrSagaConflict вместо того, чтобы затереть чужие изменения.
 type Storage interface {
 	SaveSagaState(ctx context.Context, state *events.SagaState) error
 	GetSagaState(ctx context.Context, sagaID string) (*events.SagaState, error)
 	DeleteSagaState(ctx context.Context, sagaID string) error
 	GetExpiredSagas(ctx context.Context) ([]*events.SagaState, error)
 	GetSagasByStatus(ctx context.Context, status events.SagaStatus) ([]*events.SagaState, error)
-	UpdateSagaStep(ctx context.Context, sagaID string, step string, status events.SagaStatus) error
-	IncrementRetryCount(ctx context.Context, sagaID string) error
+	GetDueRetries(ctx context.Context) ([]*events.SagaState, error)
+	GetStepTimeouts(ctx context.Context) ([]*events.SagaState, error)
 	Close() error
 }
 
+// Inbox хранит ID обработанных событий, чтобы повторная доставка из Kafka
+// не двигала сагу второй раз.
+type Inbox interface {
+	IsEventProcessed(ctx context.Context, eventID string) (bool, error)
+	MarkEventProcessed(ctx context.Context, eventID string) error
+	PurgeProcessedEvents(ctx context.Context) error
+}
+
+// Leader сообщает, владеет ли реплика фоновой обработкой саг: таймаутами,
+// повторами и компенсацией по ним. Без этого две реплики отправили бы
+// одну и ту же компенсацию дважды.
+type Leader interface {
+	IsLeader() bool
+}
+
 type Orchestrator struct {
-	producer *kafka.Producer
+	producer bus.Publisher
 	storage  Storage
+	inbox    Inbox
+	leader   Leader
 	logger   *slog.Logger
 	config   config.SagaConfig
-	steps    map[string][]Step
+	registry *Registry
+	now      func() time.Time
 }
 
-type Step struct {
-	Name           string
-	Topic          string
-	EventType      events.EventType
-	CompensateType events.EventType
-	Timeout        time.Duration
-}
+func NewOrchestrator(producer bus.Publisher, storage Storage, inbox Inbox, leader Leader, log *slog.Logger, cfg config.SagaConfig) *Orchestrator {
+	registry, err := NewRegistry(DefaultDefinitions()...)
+	if err != nil {
+		panic(fmt.Sprintf("invalid saga definitions: %v", err))
+	}
 
-func NewOrchestrator(producer *kafka.Producer, storage Storage, log *slog.Logger, cfg config.SagaConfig) *Orchestrator {
-	orchestrator := &Orchestrator{
+	return &Orchestrator{
 		producer: producer,
 		storage:  storage,
+		inbox:    inbox,
+		leader:   leader,
 		logger:   log,
 		config:   cfg,
-		steps:    make(map[string][]Step),
-	}
-
-	orchestrator.initializeSagaSteps()
-	return orchestrator
-}
-
-func (o *Orchestrator) initializeSagaSteps() {
-	o.steps["user_deletion"] = []Step{
-		{
-			Name:           "delete_auth_user",
-			Topic:          "auth-service-commands",
-			EventType:      events.AuthUserDeleteRequested,
-			CompensateType: events.AuthUserDeleteRollback,
-			Timeout:        30 * time.Second,
-		},
-		{
-			Name:           "delete_team_user",
-			Topic:          "team-service-commands",
-			EventType:      events.TeamUserDeleteRequested,
-			CompensateType: events.TeamUserDeleteRollback,
-			Timeout:        30 * time.Second,
-		},
-		{
-			Name:           "delete_board_user",
-			Topic:          "board-service-commands",
-			EventType:      events.BoardUserDeleteRequested,
-			CompensateType: events.BoardUserDeleteRollback,
-			Timeout:        30 * time.Second,
-		},
-		{
-			Name:           "delete_task_user",
-			Topic:          "task-service-commands",
-			EventType:      events.TaskUserDeleteRequested,
-			CompensateType: events.TaskUserDeleteRollback,
-			Timeout:        30 * time.Second,
-		},
+		registry: registry,
+		now:      time.Now,
 	}
 }
 
+// WithClock подменяет источник времени: симулятор саг двигает его вперёд,
+// чтобы проверять таймауты и повторы без ожидания.
+func (o *Orchestrator) WithClock(now func() time.Time) *Orchestrator {
+	o.now = now
+	return o
+}
+
 func (o *Orchestrator) HandleEvent(ctx context.Context, event events.Event) error {
-	switch event.Type {
-	case events.UserDeletionRequested:
-		return o.handleUserDeletionRequested(ctx, event)
-	case events.AuthUserDeleted:
-		return o.handleStepCompleted(ctx, event, "delete_auth_user")
-	case events.TeamUserDeleted:
-		return o.handleStepCompleted(ctx, event, "delete_team_user")
-	case events.BoardUserDeleted:
-		return o.handleStepCompleted(ctx, event, "delete_board_user")
-	case events.TaskUserDeleted:
-		return o.handleStepCompleted(ctx, event, "delete_task_user")
-	case events.AuthUserDeleteFailed:
-		return o.handleStepFailed(ctx, event, "delete_auth_user")
-	case events.TeamUserDeleteFailed:
-		return o.handleStepFailed(ctx, event, "delete_team_user")
-	case events.BoardUserDeleteFailed:
-		return o.handleStepFailed(ctx, event, "delete_board_user")
-	case events.TaskUserDeleteFailed:
-		return o.handleStepFailed(ctx, event, "delete_task_user")
-	default:
-		o.logger.Warn("Unknown event type: %s", event.Type)
+	if event.ID != "" {
+		processed, err := o.inbox.IsEventProcessed(ctx, event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check inbox: %w", err)
+		}
+		if processed {
+			o.logger.Info("Skipping already processed event",
+				slog.String("event_id", event.ID),
+				slog.String("event_type", string(event.Type)),
+			)
+			return nil
+		}
+	}
+
+	if err := o.handleEvent(ctx, event); err != nil {
+		return err
+	}
+
+	if event.ID != "" {
+		if err := o.inbox.MarkEventProcessed(ctx, event.ID); err != nil {
+			return fmt.Errorf("failed to mark event processed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (o *Orchestrator) handleEvent(ctx context.Context, event events.Event) error {
+	if def, ok := o.registry.ByTrigger(event.Type); ok {
+		return o.startSaga(ctx, def, event)
+	}
+
+	if !o.registry.IsReply(event.Type) {
+		o.logger.Warn("Unknown event type", slog.String("event_type", string(event.Type)))
 		return nil
 	}
+
+	// Пока ответ применялся, сагу мог изменить таймаут или повтор: событие
+	// применяется заново к свежему состоянию
+	for attempt := 1; ; attempt++ {
+		err := o.handleReply(ctx, event)
+		if !errors.Is(err, events.ErrSagaConflict) || attempt == maxConflictRetries {
+			return err
+		}
+
+		o.logger.Info("Saga state changed concurrently, reapplying event",
+			slog.String("saga_id", event.SagaID),
+			slog.String("event_type", string(event.Type)),
+			slog.Int("attempt", attempt),
+		)
+	}
+}
+
+func (o *Orchestrator) handleReply(ctx context.Context, event events.Event) error {
+	sagaState, err := o.storage.GetSagaState(ctx, event.SagaID)
+	if err != nil {
+		return fmt.Errorf("failed to get saga state: %w", err)
+	}
+
+	def, err := o.definitionFor(sagaState)
+	if err != nil {
+		return err
+	}
+
+	stepName, outcome, ok := o.registry.routeReply(def.Type, event.Type)
+	if !ok {
+		o.logger.Warn("Event does not belong to saga",
+			slog.String("event_type", string(event.Type)),
+			slog.String("saga_id", sagaState.ID),
+			slog.String("saga_type", def.Type),
+		)
+		return nil
+	}
+
+	switch outcome {
+	case stepFailed:
+		return o.handleStepFailed(ctx, sagaState, def, stepName, false)
+	case compensationSucceeded:
+		return o.handleCompensationCompleted(ctx, sagaState, def, stepName)
+	case compensationFailed:
+		reason, _ := event.Data["error"].(string)
+		return o.handleCompensationFailed(ctx, sagaState, def, stepName, reason)
+	}
+	return o.handleStepCompleted(ctx, sagaState, def, stepName, event.Data)
+}
+
+// definitionFor возвращает определение саги по её состоянию. Состояния,
+// сохранённые до появления поля Type, относятся к удалению пользователя.
+func (o *Orchestrator) definitionFor(sagaState *events.SagaState) (*Definition, error) {
+	sagaType := sagaState.Type
+	if sagaType == "" {
+		sagaType = UserDeletionSaga
+	}
+
+	def, ok := o.registry.Get(sagaType)
+	if !ok {
+		return nil, fmt.Errorf("unknown saga type %q for saga %s", sagaType, sagaState.ID)
+	}
+	return def, nil
 }
 
-func (o *Orchestrator) handleUserDeletionRequested(ctx context.Context, event events.Event) error {
+func (o *Orchestrator) startSaga(ctx context.Context, def *Definition, event events.Event) error {
+	metadata := make(map[string]string, len(def.Params))
+	for _, param := range def.Params {
+		value, _ := event.Data[param].(string)
+		if value == "" {
+			return fmt.Errorf("saga %s: trigger event %s has no %s", def.Type, event.ID, param)
+		}
+		metadata[param] = value
+	}
+
 	sagaID := uuid.New().String()
 
+	now := o.now()
 	sagaState := &events.SagaState{
 		ID:             sagaID,
+		Type:           def.Type,
 		UserID:         event.UserID,
 		Status:         events.SagaStatusPending,
 		CurrentStep:    "",
 		CompletedSteps: []string{},
 		RetryCount:     0,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-		ExpiresAt:      time.Now().Add(o.config.Timeout),
-		Metadata:       make(map[string]string),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		ExpiresAt:      now.Add(o.config.Timeout),
+		Metadata:       metadata,
+		Headers:        event.Headers,
 	}
 
 	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
 		return fmt.Errorf("failed to save saga state: %w", err)
 	}
 
-	//metrics.SagasStarted.WithLabelValues("user_deletion").Inc()
-	o.logger.Info("Started user deletion saga: %s for user: %s", sagaID, event.UserID)
+	metrics.SagasStarted.WithLabelValues(def.Type).Inc()
+	o.logger.Info("Started saga",
+		slog.String("saga_type", def.Type),
+		slog.String("saga_id", sagaID),
+		slog.String("user_id", event.UserID),
+		slog.Any("trace", event.Trace()),
+	)
 
-	return o.executeNextStep(ctx, sagaState)
+	return o.executeNextStep(ctx, sagaState, def)
 }
 
-func (o *Orchestrator) handleStepCompleted(ctx context.Context, event events.Event, stepName string) error {
-	sagaState, err := o.storage.GetSagaState(ctx, event.SagaID)
-	if err != nil {
-		return fmt.Errorf("failed to get saga state: %w", err)
+func (o *Orchestrator) handleStepCompleted(ctx context.Context, sagaState *events.SagaState, def *Definition, stepName string, result map[string]interface{}) error {
+	if o.awaitsRollbackData(sagaState, stepName) {
+		return o.handleLateStepCompletion(ctx, sagaState, def, stepName, result)
 	}
 
 	if sagaState.Status != events.SagaStatusInProgress {
-		o.logger.Warn("Received step completion for saga in wrong state: %s, status: %s",
-			event.SagaID, sagaState.Status)
+		o.logger.Warn("Received step completion for saga in wrong state",
+			slog.String("saga_id", sagaState.ID),
+			slog.String("status", string(sagaState.Status)),
+		)
 		return nil
 	}
 
-	sagaState.CompletedSteps = append(sagaState.CompletedSteps, stepName)
-	sagaState.UpdatedAt = time.Now()
-
-	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
-		return fmt.Errorf("failed to update saga state: %w", err)
+	if o.isStepCompleted(sagaState, stepName) {
+		return nil
 	}
 
-	o.logger.Info("Step %s completed for saga: %s", stepName, event.SagaID)
+	o.observeStep(sagaState, def, stepName, "completed")
 
-	return o.executeNextStep(ctx, sagaState)
+	sagaState.CompletedSteps = append(sagaState.CompletedSteps, stepName)
+	if len(result) > 0 {
+		if sagaState.StepResults == nil {
+			sagaState.StepResults = make(map[string]map[string]interface{})
+		}
+		sagaState.StepResults[stepName] = result
+	}
+	sagaState.StepDeadline = time.Time{}
+	sagaState.FailedStep = ""
+	sagaState.ScheduledStep = ""
+	sagaState.NextRetryAt = time.Time{}
+	sagaState.UpdatedAt = o.now()
+
+	o.logger.Info("Step completed", slog.String("step", stepName), slog.String("saga_id", sagaState.ID))
+
+	// Выполнение шага сохраняется вместе со следующим шагом или завершением
+	// саги: одна запись на переход, чтобы конфликт версий не оставил сагу
+	// между ними
+	return o.executeNextStep(ctx, sagaState, def)
 }
 
-func (o *Orchestrator) handleStepFailed(ctx context.Context, event events.Event, stepName string) error {
-	sagaState, err := o.storage.GetSagaState(ctx, event.SagaID)
-	if err != nil {
-		return fmt.Errorf("failed to get saga state: %w", err)
+// handleStepFailed обрабатывает отказ участника или, если timedOut, истёкший
+// дедлайн шага.
+func (o *Orchestrator) handleStepFailed(ctx context.Context, sagaState *events.SagaState, def *Definition, stepName string, timedOut bool) error {
+	if sagaState.Status != events.SagaStatusInProgress {
+		o.logger.Warn("Received step failure for saga in wrong state",
+			slog.String("saga_id", sagaState.ID),
+			slog.String("status", string(sagaState.Status)),
+		)
+		return nil
+	}
+
+	// Запоздалый отказ по уже выполненному или не текущему шагу (например,
+	// ответ на повтор, отправленный до успешного) не должен откатывать сагу
+	if stepName != sagaState.CurrentStep || o.isStepCompleted(sagaState, stepName) {
+		o.logger.Warn("Ignoring failure of a step that is not in progress",
+			slog.String("saga_id", sagaState.ID),
+			slog.String("step", stepName),
+			slog.String("current_step", sagaState.CurrentStep),
+		)
+		return nil
 	}
 
+	o.observeStep(sagaState, def, stepName, "failed")
+
+	sagaState.StepDeadline = time.Time{}
 	sagaState.FailedStep = stepName
-	sagaState.UpdatedAt = time.Now()
+	sagaState.UpdatedAt = o.now()
 
-	if sagaState.RetryCount < o.config.MaxRetries {
-		return o.retrySagaStep(ctx, sagaState, stepName)
+	if sagaState.StepRetries[stepName] < o.config.MaxRetries {
+		return o.retrySagaStep(ctx, sagaState, def, stepName)
 	}
 
-	o.logger.Error("Step %s failed for saga: %s, starting compensation", stepName, event.SagaID)
-	//metrics.SagasFailed.WithLabelValues("user_deletion", stepName).Inc()
-
-	return o.startCompensation(ctx, sagaState)
+	o.logger.Error("Step failed, starting compensation",
+		slog.String("step", stepName),
+		slog.String("saga_id", sagaState.ID),
+	)
+	metrics.SagasFailed.WithLabelValues(def.Type, stepName).Inc()
+
+	// Участник, не ответивший вовремя, мог всё-таки выполнить шаг, а его
+	// запоздалый ответ уже не сделает шаг выполненным. Поэтому шаг тоже
+	// откатывается: компенсации идемпотентны
+	unanswered := ""
+	if timedOut {
+		unanswered = stepName
+	}
+	return o.startCompensation(ctx, sagaState, def, unanswered)
 }
 
-func (o *Orchestrator) executeNextStep(ctx context.Context, sagaState *events.SagaState) error {
-	steps := o.steps["user_deletion"]
-
+func (o *Orchestrator) executeNextStep(ctx context.Context, sagaState *events.SagaState, def *Definition) error {
 	// Находим следующий шаг для выполнения
-	for _, step := range steps {
+	for _, step := range def.Steps {
 		if !o.isStepCompleted(sagaState, step.Name) {
 			return o.executeStep(ctx, sagaState, step)
 		}
 	}
 
 	// Все шаги выполнены успешно
-	return o.completeSaga(ctx, sagaState)
+	return o.completeSaga(ctx, sagaState, def)
 }
 
 func (o *Orchestrator) executeStep(ctx context.Context, sagaState *events.SagaState, step Step) error {
 	sagaState.CurrentStep = step.Name
+	sagaState.StepStartedAt = o.now()
+	sagaState.StepDeadline = time.Time{}
+	if step.Timeout > 0 {
+		sagaState.StepDeadline = sagaState.StepStartedAt.Add(step.Timeout)
+	}
+	sagaState.ScheduledStep = ""
+	sagaState.NextRetryAt = time.Time{}
 	sagaState.Status = events.SagaStatusInProgress
-	sagaState.UpdatedAt = time.Now()
+	sagaState.UpdatedAt = o.now()
 
 	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
 		return fmt.Errorf("failed to update saga state: %w", err)
 	}
 
-	event := events.Event{
-		ID:        uuid.New().String(),
-		Type:      step.EventType,
-		UserID:    sagaState.UserID,
-		Timestamp: time.Now(),
-		SagaID:    sagaState.ID,
-		Data:      make(map[string]interface{}),
-	}
+	event := events.NewEvent(step.EventType, sagaState.UserID, sagaState.ID, o.stepInput(sagaState, step)).
+		WithTrace(sagaState.Trace())
 
 	if err := o.producer.PublishEvent(step.Topic, event); err != nil {
 		return fmt.Errorf("failed to publish step event: %w", err)
 	}
 
-	o.logger.Info("Executed step %s for saga: %s", step.Name, sagaState.ID)
+	o.logger.Info("Executed step", slog.String("step", step.Name), slog.String("saga_id", sagaState.ID))
 	return nil
 }
 
-func (o *Orchestrator) retrySagaStep(ctx context.Context, sagaState *events.SagaState, stepName string) error {
-	if err := o.storage.IncrementRetryCount(ctx, sagaState.ID); err != nil {
-		return fmt.Errorf("failed to increment retry count: %w", err)
+// startCompensation откатывает выполненные шаги по одному в обратном порядке:
+// следующая компенсация отправляется только после подтверждения предыдущей.
+// Если откат уже начинался, он продолжается с первого неподтверждённого шага.
+// Шаг unanswered откатывается вместе с выполненными, хотя ответа по нему нет.
+func (o *Orchestrator) startCompensation(ctx context.Context, sagaState *events.SagaState, def *Definition, unanswered string) error {
+	if len(sagaState.PendingCompensations) == 0 {
+		var pending []string
+		for i := len(def.Steps) - 1; i >= 0; i-- {
+			step := def.Steps[i]
+			if step.CompensateType != "" && (step.Name == unanswered || o.isStepCompleted(sagaState, step.Name)) {
+				pending = append(pending, step.Name)
+			}
+		}
+		sagaState.PendingCompensations = pending
 	}
 
-	o.logger.Info("Retrying step %s for saga: %s (attempt %d)",
-		stepName, sagaState.ID, sagaState.RetryCount+1)
+	sagaState.Status = events.SagaStatusRollingBack
+	sagaState.StepDeadline = time.Time{}
+	sagaState.ScheduledStep = ""
+	sagaState.NextRetryAt = time.Time{}
+	sagaState.UpdatedAt = o.now()
 
-	// Находим шаг для повтора
-	steps := o.steps["user_deletion"]
-	for _, step := range steps {
-		if step.Name == stepName {
-			time.Sleep(o.config.RetryInterval)
-			return o.executeStep(ctx, sagaState, step)
-		}
+	return o.compensateNext(ctx, sagaState, def)
+}
+
+func (o *Orchestrator) compensateNext(ctx context.Context, sagaState *events.SagaState, def *Definition) error {
+	if len(sagaState.PendingCompensations) == 0 {
+		return o.finalizeSagaRollback(ctx, sagaState, def)
+	}
+
+	step, ok := def.Step(sagaState.PendingCompensations[0])
+	if !ok {
+		return fmt.Errorf("step not found: %s", sagaState.PendingCompensations[0])
 	}
 
-	return fmt.Errorf("step not found: %s", stepName)
+	return o.compensateStep(ctx, sagaState, step)
 }
 
-func (o *Orchestrator) startCompensation(ctx context.Context, sagaState *events.SagaState) error {
-	sagaState.Status = events.SagaStatusRollingBack
-	sagaState.UpdatedAt = time.Now()
+// compensateStep отправляет участнику то, что он вернул при выполнении шага
+// (например, rollback_data), чтобы откат восстановил ровно удалённые данные.
+// Состояние с дедлайном сохраняется до публикации: если отправка не удалась,
+// компенсацию повторит обработка таймаутов.
+func (o *Orchestrator) compensateStep(ctx context.Context, sagaState *events.SagaState, step Step) error {
+	sagaState.CurrentStep = step.Name
+	sagaState.StepStartedAt = o.now()
+	sagaState.StepDeadline = time.Time{}
+	if step.Timeout > 0 {
+		sagaState.StepDeadline = sagaState.StepStartedAt.Add(step.Timeout)
+	}
+	sagaState.ScheduledStep = ""
+	sagaState.NextRetryAt = time.Time{}
+	sagaState.UpdatedAt = o.now()
 
 	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
 		return fmt.Errorf("failed to update saga state: %w", err)
 	}
 
-	steps := o.steps["user_deletion"]
+	data := make(map[string]interface{}, len(sagaState.StepResults[step.Name])+len(step.Inputs))
+	for key, value := range o.stepInput(sagaState, step) {
+		data[key] = value
+	}
+	for key, value := range sagaState.StepResults[step.Name] {
+		data[key] = value
+	}
 
-	// Выполняем компенсацию в обратном порядке
-	for i := len(steps) - 1; i >= 0; i-- {
-		step := steps[i]
-		if o.isStepCompleted(sagaState, step.Name) {
-			if err := o.compensateStep(ctx, sagaState, step); err != nil {
-				o.logger.Error("Failed to compensate step %s: %v", step.Name, err)
-				// Продолжаем компенсацию даже если один шаг не удался
-			}
-		}
+	event := events.NewEvent(step.CompensateType, sagaState.UserID, sagaState.ID, data).
+		WithTrace(sagaState.Trace())
+
+	if err := o.producer.PublishEvent(step.Topic, event); err != nil {
+		return fmt.Errorf("failed to publish compensation event: %w", err)
 	}
 
-	return o.finalizeSagaRollback(ctx, sagaState)
+	o.logger.Info("Sent step compensation", slog.String("step", step.Name), slog.String("saga_id", sagaState.ID))
+	return nil
 }
 
-func (o *Orchestrator) compensateStep(ctx context.Context, sagaState *events.SagaState, step Step) error {
-	event := events.Event{
-		ID:        uuid.New().String(),
-		Type:      step.CompensateType,
-		UserID:    sagaState.UserID,
-		Timestamp: time.Now(),
-		SagaID:    sagaState.ID,
-		Data:      make(map[string]interface{}),
+// handleCompensationCompleted принимает подтверждение отката. Запоздавшее
+// подтверждение поднимает и сагу в compensation_failed: участник всё-таки
+// откатил шаг, и остальные компенсации можно продолжить.
+func (o *Orchestrator) handleCompensationCompleted(ctx context.Context, sagaState *events.SagaState, def *Definition, stepName string) error {
+	if !o.awaitsCompensation(sagaState, stepName) {
+		o.logger.Warn("Received compensation confirmation for saga in wrong state",
+			slog.String("saga_id", sagaState.ID),
+			slog.String("step", stepName),
+			slog.String("status", string(sagaState.Status)),
+		)
+		return nil
 	}
 
-	if err := o.producer.PublishEvent(step.Topic, event); err != nil {
-		return fmt.Errorf("failed to publish compensation event: %w", err)
+	o.logger.Info("Step compensated", slog.String("step", stepName), slog.String("saga_id", sagaState.ID))
+
+	sagaState.PendingCompensations = sagaState.PendingCompensations[1:]
+	sagaState.Status = events.SagaStatusRollingBack
+	sagaState.StepDeadline = time.Time{}
+	sagaState.ScheduledStep = ""
+	sagaState.NextRetryAt = time.Time{}
+	sagaState.UpdatedAt = o.now()
+
+	return o.compensateNext(ctx, sagaState, def)
+}
+
+func (o *Orchestrator) handleCompensationFailed(ctx context.Context, sagaState *events.SagaState, def *Definition, stepName, reason string) error {
+	if sagaState.Status != events.SagaStatusRollingBack || !o.awaitsCompensation(sagaState, stepName) {
+		o.logger.Warn("Received compensation failure for saga in wrong state",
+			slog.String("saga_id", sagaState.ID),
+			slog.String("step", stepName),
+			slog.String("status", string(sagaState.Status)),
+		)
+		return nil
+	}
+
+	o.logger.Warn("Step compensation failed",
+		slog.String("step", stepName),
+		slog.String("saga_id", sagaState.ID),
+		slog.String("reason", reason),
+	)
+
+	sagaState.StepDeadline = time.Time{}
+	sagaState.UpdatedAt = o.now()
+
+	if sagaState.CompensationRetries[stepName] < o.config.MaxCompensationRetries {
+		return o.retryCompensation(ctx, sagaState, def, stepName)
 	}
 
-	o.logger.Info("Compensated step %s for saga: %s", step.Name, sagaState.ID)
+	return o.failCompensation(ctx, sagaState, def, stepName, reason)
+}
+
+// failCompensation останавливает откат: данные участников остались
+// в промежуточном состоянии, и дальше сагу разбирает дежурный — повторяет
+// откат через ForceCompensation или закрывает через MarkResolved.
+func (o *Orchestrator) failCompensation(ctx context.Context, sagaState *events.SagaState, def *Definition, stepName, reason string) error {
+	if sagaState.Metadata == nil {
+		sagaState.Metadata = make(map[string]string)
+	}
+	sagaState.Metadata["compensation_error"] = reason
+
+	sagaState.Status = events.SagaStatusCompensationFailed
+	sagaState.StepDeadline = time.Time{}
+	sagaState.ScheduledStep = ""
+	sagaState.NextRetryAt = time.Time{}
+	sagaState.UpdatedAt = o.now()
+
+	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
+		return fmt.Errorf("failed to mark compensation failed: %w", err)
+	}
+
+	duration := o.now().Sub(sagaState.CreatedAt)
+	metrics.CompensationsFailed.WithLabelValues(def.Type, stepName).Inc()
+	metrics.SagaDuration.WithLabelValues(def.Type, "compensation_failed").Observe(duration.Seconds())
+
+	o.logger.Error("Saga compensation failed, manual intervention required",
+		slog.String("saga_type", def.Type),
+		slog.String("saga_id", sagaState.ID),
+		slog.String("step", stepName),
+		slog.Any("pending_compensations", sagaState.PendingCompensations),
+		slog.Int("attempts", sagaState.CompensationRetries[stepName]+1),
+		slog.String("reason", reason),
+	)
 	return nil
 }
 
-func (o *Orchestrator) completeSaga(ctx context.Context, sagaState *events.SagaState) error {
+// awaitsCompensation сообщает, ждёт ли сага ответа на компенсацию шага.
+// awaitsRollbackData — шаг стоит в очереди отката, хотя участник не ответил
+// на его выполнение: он упал по дедлайну.
+func (o *Orchestrator) awaitsRollbackData(sagaState *events.SagaState, stepName string) bool {
+	if o.isStepCompleted(sagaState, stepName) {
+		return false
+	}
+	return slices.Contains(sagaState.PendingCompensations, stepName)
+}
+
+// handleLateStepCompletion принимает ответ, пришедший после дедлайна шага.
+// Компенсация шага могла уйти без rollback_data: тогда она отправляется ещё
+// раз с данными из ответа, а подтверждение первой отправки продвинет откат
+// как обычно.
+func (o *Orchestrator) handleLateStepCompletion(ctx context.Context, sagaState *events.SagaState, def *Definition, stepName string, result map[string]interface{}) error {
+	o.logger.Warn("Step completed after its deadline",
+		slog.String("saga_id", sagaState.ID),
+		slog.String("step", stepName),
+		slog.String("status", string(sagaState.Status)),
+	)
+
+	if len(result) == 0 {
+		return nil
+	}
+	if sagaState.StepResults == nil {
+		sagaState.StepResults = make(map[string]map[string]interface{})
+	}
+	sagaState.StepResults[stepName] = result
+	sagaState.UpdatedAt = o.now()
+
+	if sagaState.Status == events.SagaStatusRollingBack && o.awaitsCompensation(sagaState, stepName) {
+		step, ok := def.Step(stepName)
+		if !ok {
+			return fmt.Errorf("step not found: %s", stepName)
+		}
+		return o.compensateStep(ctx, sagaState, step)
+	}
+
+	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
+		return fmt.Errorf("failed to update saga state: %w", err)
+	}
+	return nil
+}
+
+func (o *Orchestrator) awaitsCompensation(sagaState *events.SagaState, stepName string) bool {
+	if sagaState.Status != events.SagaStatusRollingBack && sagaState.Status != events.SagaStatusCompensationFailed {
+		return false
+	}
+	return len(sagaState.PendingCompensations) > 0 && sagaState.PendingCompensations[0] == stepName
+}
+
+func (o *Orchestrator) completeSaga(ctx context.Context, sagaState *events.SagaState, def *Definition) error {
 	sagaState.Status = events.SagaStatusCompleted
-	sagaState.UpdatedAt = time.Now()
+	sagaState.UpdatedAt = o.now()
 
 	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
 		return fmt.Errorf("failed to complete saga: %w", err)
 	}
 
 	// Публикуем событие о завершении саги
-	event := events.Event{
-		ID:        uuid.New().String(),
-		Type:      events.UserDeletionCompleted,
-		UserID:    sagaState.UserID,
-		Timestamp: time.Now(),
-		SagaID:    sagaState.ID,
-	}
+	if def.CompletedType != "" {
+		event := events.NewEvent(def.CompletedType, sagaState.UserID, sagaState.ID, sagaParams(sagaState, def)).
+			WithTrace(sagaState.Trace())
 
-	if err := o.producer.PublishEvent("user-deletion-saga", event); err != nil {
-		return fmt.Errorf("failed to publish completion event: %w", err)
+		if err := o.producer.PublishEvent(def.Topic, event); err != nil {
+			return fmt.Errorf("failed to publish completion event: %w", err)
+		}
 	}
 
-	duration := time.Since(sagaState.CreatedAt)
-	//metrics.SagasCompleted.WithLabelValues("user_deletion").Inc()
-	//metrics.SagaDuration.WithLabelValues("user_deletion", "completed").Observe(duration.Seconds())
+	duration := o.now().Sub(sagaState.CreatedAt)
+	metrics.SagasCompleted.WithLabelValues(def.Type).Inc()
+	metrics.SagaDuration.WithLabelValues(def.Type, "completed").Observe(duration.Seconds())
 
-	o.logger.Info("Saga completed successfully: %s in %v", sagaState.ID, duration)
+	o.logger.Info("Saga completed successfully",
+		slog.String("saga_type", def.Type),
+		slog.String("saga_id", sagaState.ID),
+		slog.Duration("duration", duration),
+	)
 	return nil
 }
 
-func (o *Orchestrator) finalizeSagaRollback(ctx context.Context, sagaState *events.SagaState) error {
+func (o *Orchestrator) finalizeSagaRollback(ctx context.Context, sagaState *events.SagaState, def *Definition) error {
 	sagaState.Status = events.SagaStatusRolledBack
-	sagaState.UpdatedAt = time.Now()
+	sagaState.UpdatedAt = o.now()
 
 	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
 		return fmt.Errorf("failed to finalize saga rollback: %w", err)
 	}
 
 	// Публикуем событие об откате саги
-	event := events.Event{
-		ID:        uuid.New().String(),
-		Type:      events.UserDeletionRollback,
-		UserID:    sagaState.UserID,
-		Timestamp: time.Now(),
-		SagaID:    sagaState.ID,
-	}
+	if def.RolledBackType != "" {
+		event := events.NewEvent(def.RolledBackType, sagaState.UserID, sagaState.ID, sagaParams(sagaState, def)).
+			WithTrace(sagaState.Trace())
 
-	if err := o.producer.PublishEvent("user-deletion-saga", event); err != nil {
-		return fmt.Errorf("failed to publish rollback event: %w", err)
+		if err := o.producer.PublishEvent(def.Topic, event); err != nil {
+			return fmt.Errorf("failed to publish rollback event: %w", err)
+		}
 	}
 
-	duration := time.Since(sagaState.CreatedAt)
-	//metrics.SagaDuration.WithLabelValues("user_deletion", "rolled_back").Observe(duration.Seconds())
+	duration := o.now().Sub(sagaState.CreatedAt)
+	metrics.SagaDuration.WithLabelValues(def.Type, "rolled_back").Observe(duration.Seconds())
 
-	o.logger.Info("Saga rolled back: %s in %v", sagaState.ID, duration)
+	o.logger.Info("Saga rolled back",
+		slog.String("saga_type", def.Type),
+		slog.String("saga_id", sagaState.ID),
+		slog.Duration("duration", duration),
+	)
 	return nil
 }
 
+// observeStep фиксирует время от отправки команды шага до ответа участника.
+func (o *Orchestrator) observeStep(sagaState *events.SagaState, def *Definition, stepName, outcome string) {
+	if sagaState.CurrentStep != stepName || sagaState.StepStartedAt.IsZero() {
+		return
+	}
+	metrics.StepDuration.WithLabelValues(def.Type, stepName, outcome).
+		Observe(o.now().Sub(sagaState.StepStartedAt).Seconds())
+}
+
+// stepInput собирает data команды шага по Step.Inputs: сначала из параметров
+// саги, затем из ответов выполненных шагов в порядке их выполнения.
+func (o *Orchestrator) stepInput(sagaState *events.SagaState, step Step) map[string]interface{} {
+	if len(step.Inputs) == 0 {
+		return nil
+	}
+
+	data := make(map[string]interface{}, len(step.Inputs))
+	for _, key := range step.Inputs {
+		if value, ok := sagaState.Metadata[key]; ok {
+			data[key] = value
+			continue
+		}
+		for _, completedStep := range sagaState.CompletedSteps {
+			if value, ok := sagaState.StepResults[completedStep][key]; ok {
+				data[key] = value
+				break
+			}
+		}
+	}
+	return data
+}
+
+func sagaParams(sagaState *events.SagaState, def *Definition) map[string]interface{} {
+	if len(def.Params) == 0 {
+		return nil
+	}
+
+	data := make(map[string]interface{}, len(def.Params))
+	for _, param := range def.Params {
+		data[param] = sagaState.Metadata[param]
+	}
+	return data
+}
+
 func (o *Orchestrator) isStepCompleted(sagaState *events.SagaState, stepName string) bool {
 	for _, completedStep := range sagaState.CompletedSteps {
 		if completedStep == stepName {
@@ -352,46 +685,146 @@ func (o *Orchestrator) StartTimeoutMonitor(ctx context.Context) {
 	ticker := time.NewTicker(o.config.CleanupInterval)
 	defer ticker.Stop()
 
+	stepPeriod := o.config.StepTimeoutCheckInterval
+	if stepPeriod <= 0 {
+		stepPeriod = 5 * time.Second
+	}
+	stepTicker := time.NewTicker(stepPeriod)
+	defer stepTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			o.logger.Info("Timeout monitor stopped")
 			return
 		case <-ticker.C:
-			if err := o.handleTimeouts(ctx); err != nil {
-				o.logger.Error("Error handling timeouts: %v", err)
+			if !o.leader.IsLeader() {
+				continue
+			}
+			if err := o.CheckExpiredSagas(ctx); err != nil {
+				o.logger.Error("Error handling timeouts", slog.Any("error", err))
+			}
+		case <-stepTicker.C:
+			if !o.leader.IsLeader() {
+				continue
+			}
+			if err := o.CheckStepTimeouts(ctx); err != nil {
+				o.logger.Error("Error handling step timeouts", slog.Any("error", err))
 			}
 		}
 	}
 }
 
-func (o *Orchestrator) handleTimeouts(ctx context.Context) error {
+// CheckStepTimeouts считает шаг упавшим, если участник не ответил за
+// Step.Timeout: дальше срабатывает обычная логика повторов и компенсации.
+func (o *Orchestrator) CheckStepTimeouts(ctx context.Context) error {
+	timedOut, err := o.storage.GetStepTimeouts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get step timeouts: %w", err)
+	}
+
+	for _, sagaState := range timedOut {
+		// Lease мог истечь посреди пачки — остаток обработает новый лидер
+		if !o.leader.IsLeader() {
+			return nil
+		}
+
+		if sagaState.CurrentStep == "" {
+			continue
+		}
+
+		def, err := o.definitionFor(sagaState)
+		if err != nil {
+			o.logger.Error("Failed to resolve saga definition", slog.String("saga_id", sagaState.ID), slog.Any("error", err))
+			continue
+		}
+
+		if sagaState.Status == events.SagaStatusRollingBack {
+			o.handleCompensationTimeout(ctx, sagaState, def)
+			continue
+		}
+		if sagaState.Status != events.SagaStatusInProgress {
+			continue
+		}
+
+		o.logger.Warn("Step timed out",
+			slog.String("saga_id", sagaState.ID),
+			slog.String("step", sagaState.CurrentStep),
+			slog.Time("deadline", sagaState.StepDeadline),
+		)
+		metrics.StepTimeouts.WithLabelValues(def.Type, sagaState.CurrentStep).Inc()
+
+		if err := o.handleStepFailed(ctx, sagaState, def, sagaState.CurrentStep, true); err != nil {
+			o.logger.Error("Failed to handle step timeout",
+				slog.String("saga_id", sagaState.ID),
+				slog.String("step", sagaState.CurrentStep),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// handleCompensationTimeout считает неподтверждённый откат неудавшимся,
+// чтобы он повторился или дошёл до compensation_failed.
+func (o *Orchestrator) handleCompensationTimeout(ctx context.Context, sagaState *events.SagaState, def *Definition) {
+	if len(sagaState.PendingCompensations) == 0 {
+		return
+	}
+	stepName := sagaState.PendingCompensations[0]
+
+	o.logger.Warn("Step compensation timed out",
+		slog.String("saga_id", sagaState.ID),
+		slog.String("step", stepName),
+		slog.Time("deadline", sagaState.StepDeadline),
+	)
+	metrics.StepTimeouts.WithLabelValues(def.Type, stepName).Inc()
+
+	if err := o.handleCompensationFailed(ctx, sagaState, def, stepName, "compensation timed out"); err != nil {
+		o.logger.Error("Failed to handle compensation timeout",
+			slog.String("saga_id", sagaState.ID),
+			slog.String("step", stepName),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// CheckExpiredSagas откатывает саги, не завершившиеся за SagaConfig.Timeout.
+// Как и остальные Check*, вызывается по тикеру и напрямую из симулятора.
+func (o *Orchestrator) CheckExpiredSagas(ctx context.Context) error {
+	if err := o.inbox.PurgeProcessedEvents(ctx); err != nil {
+		o.logger.Error("Failed to purge processed events", slog.Any("error", err))
+	}
+
 	expiredSagas, err := o.storage.GetExpiredSagas(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get expired sagas: %w", err)
 	}
 
 	for _, saga := range expiredSagas {
-		o.logger.Warn("Saga timed out: %s (UserID: %s)", saga.ID, saga.UserID)
+		if !o.leader.IsLeader() {
+			return nil
+		}
+
+		o.logger.Warn("Saga timed out", slog.String("saga_id", saga.ID), slog.String("user_id", saga.UserID))
 
-		if saga.Status == events.SagaStatusCompleted || saga.Status == events.SagaStatusRolledBack {
+		if isTerminal(saga.Status) {
 			continue
 		}
 
-		saga.Status = events.SagaStatusRollingBack
-		saga.UpdatedAt = time.Now()
-
-		if err := o.storage.SaveSagaState(ctx, saga); err != nil {
-			o.logger.Error("Failed to mark saga %s as rolling back: %v", saga.ID, err)
+		def, err := o.definitionFor(saga)
+		if err != nil {
+			o.logger.Error("Failed to resolve saga definition", slog.String("saga_id", saga.ID), slog.Any("error", err))
 			continue
 		}
 
-		if err := o.startCompensation(ctx, saga); err != nil {
-			o.logger.Error("Failed to compensate expired saga %s: %v", saga.ID, err)
+		if err := o.startCompensation(ctx, saga, def, ""); err != nil {
+			o.logger.Error("Failed to compensate expired saga", slog.String("saga_id", saga.ID), slog.Any("error", err))
 			continue
 		}
 
-		o.logger.Info("Compensated expired saga: %s", saga.ID)
+		o.logger.Info("Compensated expired saga", slog.String("saga_id", saga.ID))
 	}
 
 	return nil
@@ -0,0 +1,201 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"saga-orchestrator/internal/events"
+	"saga-orchestrator/internal/metrics"
+)
+
+// retrySagaStep не ждёт сам, а записывает повтор в хранилище: его выполнит
+// StartRetryScheduler, когда наступит NextRetryAt. Так обработчик Kafka
+// не блокирует партицию на время backoff.
+func (o *Orchestrator) retrySagaStep(ctx context.Context, sagaState *events.SagaState, def *Definition, stepName string) error {
+	if _, ok := def.Step(stepName); !ok {
+		return fmt.Errorf("step not found: %s", stepName)
+	}
+
+	if sagaState.StepRetries == nil {
+		sagaState.StepRetries = make(map[string]int)
+	}
+
+	attempt := sagaState.StepRetries[stepName] + 1
+	delay := o.retryBackoff(attempt)
+
+	sagaState.StepRetries[stepName] = attempt
+	sagaState.RetryCount++
+	sagaState.ScheduledStep = stepName
+	sagaState.NextRetryAt = o.now().Add(delay)
+	sagaState.UpdatedAt = o.now()
+
+	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
+		return fmt.Errorf("failed to schedule step retry: %w", err)
+	}
+
+	metrics.StepRetries.WithLabelValues(def.Type, stepName).Inc()
+	o.logger.Info("Scheduled step retry",
+		slog.String("step", stepName),
+		slog.String("saga_id", sagaState.ID),
+		slog.Int("attempt", attempt),
+		slog.Duration("delay", delay),
+	)
+
+	return nil
+}
+
+// retryCompensation планирует повтор отката шага так же, как retrySagaStep:
+// событие компенсации отправит StartRetryScheduler.
+func (o *Orchestrator) retryCompensation(ctx context.Context, sagaState *events.SagaState, def *Definition, stepName string) error {
+	if sagaState.CompensationRetries == nil {
+		sagaState.CompensationRetries = make(map[string]int)
+	}
+
+	attempt := sagaState.CompensationRetries[stepName] + 1
+	delay := o.retryBackoff(attempt)
+
+	sagaState.CompensationRetries[stepName] = attempt
+	sagaState.RetryCount++
+	sagaState.ScheduledStep = stepName
+	sagaState.NextRetryAt = o.now().Add(delay)
+	sagaState.UpdatedAt = o.now()
+
+	if err := o.storage.SaveSagaState(ctx, sagaState); err != nil {
+		return fmt.Errorf("failed to schedule compensation retry: %w", err)
+	}
+
+	metrics.CompensationRetries.WithLabelValues(def.Type, stepName).Inc()
+	o.logger.Info("Scheduled compensation retry",
+		slog.String("step", stepName),
+		slog.String("saga_id", sagaState.ID),
+		slog.Int("attempt", attempt),
+		slog.Duration("delay", delay),
+	)
+
+	return nil
+}
+
+// retryBackoff — экспоненциальная задержка RetryInterval*2^(attempt-1), ограниченная
+// MaxRetryBackoff, со случайным разбросом в её второй половине.
+func (o *Orchestrator) retryBackoff(attempt int) time.Duration {
+	backoff := o.config.RetryInterval
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if o.config.MaxRetryBackoff > 0 && backoff >= o.config.MaxRetryBackoff {
+			backoff = o.config.MaxRetryBackoff
+			break
+		}
+	}
+
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
+
+func (o *Orchestrator) StartRetryScheduler(ctx context.Context) {
+	period := o.config.RetryPollPeriod
+	if period <= 0 {
+		period = time.Second
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			o.logger.Info("Retry scheduler stopped")
+			return
+		case <-ticker.C:
+			if !o.leader.IsLeader() {
+				continue
+			}
+			if err := o.FireDueRetries(ctx); err != nil {
+				o.logger.Error("Error firing scheduled retries", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// FireDueRetries отправляет повторы шагов и компенсаций, у которых наступил NextRetryAt.
+func (o *Orchestrator) FireDueRetries(ctx context.Context) error {
+	dueSagas, err := o.storage.GetDueRetries(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get due retries: %w", err)
+	}
+
+	for _, sagaState := range dueSagas {
+		if !o.leader.IsLeader() {
+			return nil
+		}
+
+		if sagaState.ScheduledStep == "" {
+			continue
+		}
+
+		def, err := o.definitionFor(sagaState)
+		if err != nil {
+			o.logger.Error("Failed to resolve saga definition", slog.String("saga_id", sagaState.ID), slog.Any("error", err))
+			continue
+		}
+
+		if sagaState.Status == events.SagaStatusRollingBack {
+			o.fireCompensationRetry(ctx, sagaState, def)
+			continue
+		}
+		if sagaState.Status != events.SagaStatusInProgress {
+			continue
+		}
+
+		step, ok := def.Step(sagaState.ScheduledStep)
+		if !ok {
+			o.logger.Error("Scheduled step not found",
+				slog.String("saga_id", sagaState.ID),
+				slog.String("step", sagaState.ScheduledStep),
+			)
+			continue
+		}
+
+		o.logger.Info("Retrying step",
+			slog.String("step", step.Name),
+			slog.String("saga_id", sagaState.ID),
+			slog.Int("attempt", sagaState.StepRetries[step.Name]),
+		)
+
+		if err := o.executeStep(ctx, sagaState, step); err != nil {
+			o.logger.Error("Failed to retry step",
+				slog.String("step", step.Name),
+				slog.String("saga_id", sagaState.ID),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (o *Orchestrator) fireCompensationRetry(ctx context.Context, sagaState *events.SagaState, def *Definition) {
+	if !o.awaitsCompensation(sagaState, sagaState.ScheduledStep) {
+		return
+	}
+
+	o.logger.Info("Retrying step compensation",
+		slog.String("step", sagaState.ScheduledStep),
+		slog.String("saga_id", sagaState.ID),
+		slog.Int("attempt", sagaState.CompensationRetries[sagaState.ScheduledStep]),
+	)
+
+	if err := o.compensateNext(ctx, sagaState, def); err != nil {
+		o.logger.Error("Failed to retry step compensation",
+			slog.String("step", sagaState.ScheduledStep),
+			slog.String("saga_id", sagaState.ID),
+			slog.Any("error", err),
+		)
+	}
+}
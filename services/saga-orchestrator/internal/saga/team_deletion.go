@@ -0,0 +1,63 @@
+package saga
+
+import (
+	"time"
+
+	"saga-orchestrator/internal/events"
+	contract "shiroyama/events"
+)
+
+const TeamDeletionSaga = "team_deletion"
+
+// teamDeletionDefinition сначала скрывает команду, чтобы в ней не появлялись
+// новые доски, затем помечает удалёнными её доски со списками и задачи этих
+// списков. Задачи хранятся отдельно, поэтому списки досок передаются в шаг
+// task-service из ответа board-service.
+func teamDeletionDefinition() Definition {
+	return Definition{
+		Type:           TeamDeletionSaga,
+		TriggerType:    events.TeamDeletionRequested,
+		CompletedType:  events.TeamDeletionCompleted,
+		RolledBackType: events.TeamDeletionRollback,
+		Topic:          contract.TeamDeletionSagaTopic,
+		Params:         []string{"team_id"},
+		Steps: []Step{
+			{
+				Name:                   "delete_team",
+				Topic:                  contract.TeamCommandsTopic,
+				EventType:              events.TeamDeleteRequested,
+				CompensateType:         events.TeamDeleteRollback,
+				CompensatedType:        events.TeamDeleteRollbackCompleted,
+				CompensationFailedType: events.TeamDeleteRollbackFailed,
+				SuccessType:            events.TeamDeleted,
+				FailureType:            events.TeamDeleteFailed,
+				Timeout:                30 * time.Second,
+				Inputs:                 []string{"team_id"},
+			},
+			{
+				Name:                   "delete_team_boards",
+				Topic:                  contract.BoardCommandsTopic,
+				EventType:              events.BoardTeamDeleteRequested,
+				CompensateType:         events.BoardTeamDeleteRollback,
+				CompensatedType:        events.BoardTeamDeleteRollbackCompleted,
+				CompensationFailedType: events.BoardTeamDeleteRollbackFailed,
+				SuccessType:            events.BoardTeamDeleted,
+				FailureType:            events.BoardTeamDeleteFailed,
+				Timeout:                30 * time.Second,
+				Inputs:                 []string{"team_id"},
+			},
+			{
+				Name:                   "delete_team_tasks",
+				Topic:                  contract.TaskCommandsTopic,
+				EventType:              events.TaskTeamDeleteRequested,
+				CompensateType:         events.TaskTeamDeleteRollback,
+				CompensatedType:        events.TaskTeamDeleteRollbackCompleted,
+				CompensationFailedType: events.TaskTeamDeleteRollbackFailed,
+				SuccessType:            events.TaskTeamDeleted,
+				FailureType:            events.TaskTeamDeleteFailed,
+				Timeout:                30 * time.Second,
+				Inputs:                 []string{"team_id", "list_ids"},
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package saga
+
+import (
+	"time"
+
+	"saga-orchestrator/internal/events"
+	contract "shiroyama/events"
+)
+
+const UserDeletionSaga = "user_deletion"
+
+func userDeletionDefinition() Definition {
+	return Definition{
+		Type:           UserDeletionSaga,
+		TriggerType:    events.UserDeletionRequested,
+		CompletedType:  events.UserDeletionCompleted,
+		RolledBackType: events.UserDeletionRollback,
+		Topic:          contract.UserDeletionSagaTopic,
+		Steps: []Step{
+			{
+				Name:                   "delete_auth_user",
+				Topic:                  contract.AuthCommandsTopic,
+				EventType:              events.AuthUserDeleteRequested,
+				CompensateType:         events.AuthUserDeleteRollback,
+				CompensatedType:        events.AuthUserDeleteRollbackCompleted,
+				CompensationFailedType: events.AuthUserDeleteRollbackFailed,
+				SuccessType:            events.AuthUserDeleted,
+				FailureType:            events.AuthUserDeleteFailed,
+				Timeout:                30 * time.Second,
+			},
+			{
+				Name:                   "delete_team_user",
+				Topic:                  contract.TeamCommandsTopic,
+				EventType:              events.TeamUserDeleteRequested,
+				CompensateType:         events.TeamUserDeleteRollback,
+				CompensatedType:        events.TeamUserDeleteRollbackCompleted,
+				CompensationFailedType: events.TeamUserDeleteRollbackFailed,
+				SuccessType:            events.TeamUserDeleted,
+				FailureType:            events.TeamUserDeleteFailed,
+				Timeout:                30 * time.Second,
+			},
+			{
+				Name:                   "delete_board_user",
+				Topic:                  contract.BoardCommandsTopic,
+				EventType:              events.BoardUserDeleteRequested,
+				CompensateType:         events.BoardUserDeleteRollback,
+				CompensatedType:        events.BoardUserDeleteRollbackCompleted,
+				CompensationFailedType: events.BoardUserDeleteRollbackFailed,
+				SuccessType:            events.BoardUserDeleted,
+				FailureType:            events.BoardUserDeleteFailed,
+				Timeout:                30 * time.Second,
+			},
+			{
+				Name:                   "delete_task_user",
+				Topic:                  contract.TaskCommandsTopic,
+				EventType:              events.TaskUserDeleteRequested,
+				CompensateType:         events.TaskUserDeleteRollback,
+				CompensatedType:        events.TaskUserDeleteRollbackCompleted,
+				CompensationFailedType: events.TaskUserDeleteRollbackFailed,
+				SuccessType:            events.TaskUserDeleted,
+				FailureType:            events.TaskUserDeleteFailed,
+				Timeout:                30 * time.Second,
+			},
+		},
+	}
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"saga-orchestrator/internal/events"
+)
+
+// MemoryStorage держит саги и инбокс в памяти процесса. Нужна симулятору
+// и тестам, где нет Redis и Postgres; сроки сравниваются с переданными
+// часами, чтобы таймауты можно было проверять без ожидания.
+type MemoryStorage struct {
+	mu        sync.Mutex
+	sagas     map[string][]byte
+	processed map[string]time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStorage(now func() time.Time) *MemoryStorage {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &MemoryStorage{
+		sagas:     make(map[string][]byte),
+		processed: make(map[string]time.Time),
+		now:       now,
+	}
+}
+
+// SaveSagaState хранит копию состояния, как и сетевые хранилища: изменения
+// структуры после сохранения не должны попадать в хранилище сами.
+func (ms *MemoryStorage) SaveSagaState(ctx context.Context, state *events.SagaState) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	version := 0
+	if data, ok := ms.sagas[state.ID]; ok {
+		previous, err := decodeState(data)
+		if err != nil {
+			return err
+		}
+		version = previous.Version
+	}
+	if version != state.Version {
+		return fmt.Errorf("%w: %s", events.ErrSagaConflict, state.ID)
+	}
+
+	next := *state
+	next.Version++
+
+	data, err := json.Marshal(&next)
+	if err != nil {
+		return fmt.Errorf("failed to marshal saga state: %w", err)
+	}
+
+	ms.sagas[state.ID] = data
+	state.Version = next.Version
+	return nil
+}
+
+func (ms *MemoryStorage) GetSagaState(ctx context.Context, sagaID string) (*events.SagaState, error) {
+	ms.mu.Lock()
+	data, ok := ms.sagas[sagaID]
+	ms.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", events.ErrSagaNotFound, sagaID)
+	}
+
+	return decodeState(data)
+}
+
+func (ms *MemoryStorage) DeleteSagaState(ctx context.Context, sagaID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.sagas, sagaID)
+	return nil
+}
+
+func (ms *MemoryStorage) GetExpiredSagas(ctx context.Context) ([]*events.SagaState, error) {
+	now := ms.now()
+	return ms.filter(func(state *events.SagaState) bool {
+		return isActive(state.Status) && state.ExpiresAt.Before(now)
+	})
+}
+
+func (ms *MemoryStorage) GetSagasByStatus(ctx context.Context, status events.SagaStatus) ([]*events.SagaState, error) {
+	return ms.filter(func(state *events.SagaState) bool {
+		return state.Status == status
+	})
+}
+
+func (ms *MemoryStorage) GetDueRetries(ctx context.Context) ([]*events.SagaState, error) {
+	now := ms.now()
+	return ms.filter(func(state *events.SagaState) bool {
+		return isScheduled(state.Status) && !state.NextRetryAt.IsZero() && !state.NextRetryAt.After(now)
+	})
+}
+
+func (ms *MemoryStorage) GetStepTimeouts(ctx context.Context) ([]*events.SagaState, error) {
+	now := ms.now()
+	return ms.filter(func(state *events.SagaState) bool {
+		return isScheduled(state.Status) && !state.StepDeadline.IsZero() && !state.StepDeadline.After(now)
+	})
+}
+
+func (ms *MemoryStorage) IsEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	_, ok := ms.processed[eventID]
+	return ok, nil
+}
+
+func (ms *MemoryStorage) MarkEventProcessed(ctx context.Context, eventID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.processed[eventID] = ms.now()
+	return nil
+}
+
+// PurgeProcessedEvents ничего не делает: инбокс живёт столько же, сколько процесс.
+func (ms *MemoryStorage) PurgeProcessedEvents(ctx context.Context) error {
+	return nil
+}
+
+func (ms *MemoryStorage) Close() error {
+	return nil
+}
+
+// filter возвращает подходящие саги в порядке создания, чтобы прогоны
+// симулятора были воспроизводимыми.
+func (ms *MemoryStorage) filter(match func(state *events.SagaState) bool) ([]*events.SagaState, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var sagas []*events.SagaState
+	for _, data := range ms.sagas {
+		state, err := decodeState(data)
+		if err != nil {
+			return nil, err
+		}
+		if match(state) {
+			sagas = append(sagas, state)
+		}
+	}
+
+	sort.Slice(sagas, func(i, j int) bool {
+		if sagas[i].CreatedAt.Equal(sagas[j].CreatedAt) {
+			return sagas[i].ID < sagas[j].ID
+		}
+		return sagas[i].CreatedAt.Before(sagas[j].CreatedAt)
+	})
+
+	return sagas, nil
+}
+
+func decodeState(data []byte) (*events.SagaState, error) {
+	var state events.SagaState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal saga state: %w", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,499 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"saga-orchestrator/internal/config"
+	"saga-orchestrator/internal/events"
+)
+
+// StepRecord — одна запись истории саги: состояние, в которое она перешла.
+type StepRecord struct {
+	SagaID     string
+	Step       string
+	Status     events.SagaStatus
+	FailedStep string
+	RetryCount int
+	RecordedAt time.Time
+}
+
+// PostgresStorage хранит саги без TTL, чтобы завершённые и откаченные
+// процессы оставались доступны для аудита.
+type PostgresStorage struct {
+	db             *sql.DB
+	inboxRetention time.Duration
+}
+
+const sagaColumns = `id, type, user_id, status, current_step, failed_step, completed_steps,
+	retry_count, metadata, created_at, updated_at, expires_at, step_started_at,
+	step_retries, scheduled_step, next_retry_at, step_deadline, step_results,
+	pending_compensations, compensation_retries, headers, version`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func NewPostgresStorage(cfg config.DatabaseConfig, inboxRetention time.Duration) (*PostgresStorage, error) {
+	dsn := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password,
+		cfg.Name,
+	)
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConnections)
+	db.SetMaxIdleConns(cfg.MaxIdleConnections)
+	db.SetConnMaxLifetime(cfg.MaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &PostgresStorage{
+		db:             db,
+		inboxRetention: inboxRetention,
+	}, nil
+}
+
+func (ps *PostgresStorage) SaveSagaState(ctx context.Context, state *events.SagaState) error {
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous, err := ps.getForUpdate(ctx, tx, state.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	version := 0
+	if previous != nil {
+		version = previous.Version
+	}
+	if version != state.Version {
+		return fmt.Errorf("%w: %s", events.ErrSagaConflict, state.ID)
+	}
+
+	next := *state
+	next.Version++
+
+	if err := ps.save(ctx, tx, previous, &next); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit saga state: %w", err)
+	}
+
+	state.Version = next.Version
+	return nil
+}
+
+func (ps *PostgresStorage) GetSagaState(ctx context.Context, sagaID string) (*events.SagaState, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas WHERE id = $1`
+
+	state, err := scanSaga(ps.db.QueryRowContext(ctx, query, sagaID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", events.ErrSagaNotFound, sagaID)
+		}
+		return nil, fmt.Errorf("failed to get saga state: %w", err)
+	}
+
+	return state, nil
+}
+
+// DeleteSagaState удаляет только саму сагу: история переходов остаётся для
+// аудита.
+func (ps *PostgresStorage) DeleteSagaState(ctx context.Context, sagaID string) error {
+	if _, err := ps.db.ExecContext(ctx, `DELETE FROM sagas WHERE id = $1`, sagaID); err != nil {
+		return fmt.Errorf("failed to delete saga state: %w", err)
+	}
+
+	return nil
+}
+
+func (ps *PostgresStorage) GetExpiredSagas(ctx context.Context) ([]*events.SagaState, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas
+		WHERE expires_at < $1 AND status IN ($2, $3)
+		ORDER BY expires_at`
+
+	return ps.querySagas(ctx, query, time.Now(), events.SagaStatusPending, events.SagaStatusInProgress)
+}
+
+func (ps *PostgresStorage) GetSagasByStatus(ctx context.Context, status events.SagaStatus) ([]*events.SagaState, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas WHERE status = $1 ORDER BY created_at`
+
+	return ps.querySagas(ctx, query, status)
+}
+
+func (ps *PostgresStorage) GetDueRetries(ctx context.Context) ([]*events.SagaState, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas
+		WHERE next_retry_at <= $1 AND status IN ($2, $3, $4)
+		ORDER BY next_retry_at`
+
+	return ps.querySagas(ctx, query, time.Now(),
+		events.SagaStatusPending, events.SagaStatusInProgress, events.SagaStatusRollingBack)
+}
+
+func (ps *PostgresStorage) GetStepTimeouts(ctx context.Context) ([]*events.SagaState, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas
+		WHERE step_deadline <= $1 AND status IN ($2, $3, $4)
+		ORDER BY step_deadline`
+
+	return ps.querySagas(ctx, query, time.Now(),
+		events.SagaStatusPending, events.SagaStatusInProgress, events.SagaStatusRollingBack)
+}
+
+// GetSagaHistory возвращает переходы саги в хронологическом порядке.
+func (ps *PostgresStorage) GetSagaHistory(ctx context.Context, sagaID string) ([]StepRecord, error) {
+	rows, err := ps.db.QueryContext(ctx, `
+		SELECT saga_id, step, status, failed_step, retry_count, recorded_at
+		FROM saga_step_history
+		WHERE saga_id = $1
+		ORDER BY recorded_at, id`, sagaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saga history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []StepRecord
+	for rows.Next() {
+		var record StepRecord
+		if err := rows.Scan(
+			&record.SagaID,
+			&record.Step,
+			&record.Status,
+			&record.FailedStep,
+			&record.RetryCount,
+			&record.RecordedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan saga history: %w", err)
+		}
+		history = append(history, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate saga history: %w", err)
+	}
+
+	return history, nil
+}
+
+func (ps *PostgresStorage) IsEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	var exists bool
+	err := ps.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM saga_processed_events WHERE event_id = $1)`, eventID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check processed event: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (ps *PostgresStorage) MarkEventProcessed(ctx context.Context, eventID string) error {
+	_, err := ps.db.ExecContext(ctx, `
+		INSERT INTO saga_processed_events (event_id, processed_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (event_id) DO NOTHING`, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to mark event processed: %w", err)
+	}
+
+	return nil
+}
+
+func (ps *PostgresStorage) PurgeProcessedEvents(ctx context.Context) error {
+	_, err := ps.db.ExecContext(ctx,
+		`DELETE FROM saga_processed_events WHERE processed_at < $1`, time.Now().Add(-ps.inboxRetention))
+	if err != nil {
+		return fmt.Errorf("failed to purge processed events: %w", err)
+	}
+
+	return nil
+}
+
+func (ps *PostgresStorage) Close() error {
+	return ps.db.Close()
+}
+
+func (ps *PostgresStorage) getForUpdate(ctx context.Context, tx *sql.Tx, sagaID string) (*events.SagaState, error) {
+	query := `SELECT ` + sagaColumns + ` FROM sagas WHERE id = $1 FOR UPDATE`
+
+	state, err := scanSaga(tx.QueryRowContext(ctx, query, sagaID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock saga state: %w", err)
+	}
+
+	return state, nil
+}
+
+func (ps *PostgresStorage) save(ctx context.Context, tx *sql.Tx, previous, state *events.SagaState) error {
+	completedSteps, err := json.Marshal(nonNilSteps(state.CompletedSteps))
+	if err != nil {
+		return fmt.Errorf("failed to marshal completed steps: %w", err)
+	}
+
+	metadata, err := json.Marshal(nonNilMetadata(state.Metadata))
+	if err != nil {
+		return fmt.Errorf("failed to marshal saga metadata: %w", err)
+	}
+
+	stepRetries, err := json.Marshal(nonNilRetries(state.StepRetries))
+	if err != nil {
+		return fmt.Errorf("failed to marshal step retries: %w", err)
+	}
+
+	stepResults, err := json.Marshal(nonNilResults(state.StepResults))
+	if err != nil {
+		return fmt.Errorf("failed to marshal step results: %w", err)
+	}
+
+	pendingCompensations, err := json.Marshal(nonNilSteps(state.PendingCompensations))
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending compensations: %w", err)
+	}
+
+	compensationRetries, err := json.Marshal(nonNilRetries(state.CompensationRetries))
+	if err != nil {
+		return fmt.Errorf("failed to marshal compensation retries: %w", err)
+	}
+
+	headers, err := json.Marshal(nonNilMetadata(state.Headers))
+	if err != nil {
+		return fmt.Errorf("failed to marshal saga headers: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO sagas (`+sagaColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+		ON CONFLICT (id) DO UPDATE SET
+			type = EXCLUDED.type,
+			user_id = EXCLUDED.user_id,
+			status = EXCLUDED.status,
+			current_step = EXCLUDED.current_step,
+			failed_step = EXCLUDED.failed_step,
+			completed_steps = EXCLUDED.completed_steps,
+			retry_count = EXCLUDED.retry_count,
+			metadata = EXCLUDED.metadata,
+			updated_at = EXCLUDED.updated_at,
+			expires_at = EXCLUDED.expires_at,
+			step_started_at = EXCLUDED.step_started_at,
+			step_retries = EXCLUDED.step_retries,
+			scheduled_step = EXCLUDED.scheduled_step,
+			next_retry_at = EXCLUDED.next_retry_at,
+			step_deadline = EXCLUDED.step_deadline,
+			step_results = EXCLUDED.step_results,
+			pending_compensations = EXCLUDED.pending_compensations,
+			compensation_retries = EXCLUDED.compensation_retries,
+			headers = EXCLUDED.headers,
+			version = EXCLUDED.version`,
+		state.ID,
+		state.Type,
+		state.UserID,
+		state.Status,
+		state.CurrentStep,
+		state.FailedStep,
+		completedSteps,
+		state.RetryCount,
+		metadata,
+		state.CreatedAt,
+		state.UpdatedAt,
+		state.ExpiresAt,
+		nullTime(state.StepStartedAt),
+		stepRetries,
+		state.ScheduledStep,
+		nullTime(state.NextRetryAt),
+		nullTime(state.StepDeadline),
+		stepResults,
+		pendingCompensations,
+		compensationRetries,
+		headers,
+		state.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save saga state: %w", err)
+	}
+
+	if !transitioned(previous, state) {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO saga_step_history (saga_id, step, status, failed_step, retry_count, recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		state.ID,
+		state.CurrentStep,
+		state.Status,
+		state.FailedStep,
+		state.RetryCount,
+		state.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save saga history: %w", err)
+	}
+
+	return nil
+}
+
+func (ps *PostgresStorage) querySagas(ctx context.Context, query string, args ...any) ([]*events.SagaState, error) {
+	rows, err := ps.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var sagas []*events.SagaState
+	for rows.Next() {
+		state, err := scanSaga(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+		sagas = append(sagas, state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sagas: %w", err)
+	}
+
+	return sagas, nil
+}
+
+func scanSaga(row rowScanner) (*events.SagaState, error) {
+	var (
+		state                events.SagaState
+		completedSteps       []byte
+		metadata             []byte
+		stepStartedAt        sql.NullTime
+		stepRetries          []byte
+		nextRetryAt          sql.NullTime
+		stepDeadline         sql.NullTime
+		stepResults          []byte
+		pendingCompensations []byte
+		compensationRetries  []byte
+		headers              []byte
+	)
+
+	if err := row.Scan(
+		&state.ID,
+		&state.Type,
+		&state.UserID,
+		&state.Status,
+		&state.CurrentStep,
+		&state.FailedStep,
+		&completedSteps,
+		&state.RetryCount,
+		&metadata,
+		&state.CreatedAt,
+		&state.UpdatedAt,
+		&state.ExpiresAt,
+		&stepStartedAt,
+		&stepRetries,
+		&state.ScheduledStep,
+		&nextRetryAt,
+		&stepDeadline,
+		&stepResults,
+		&pendingCompensations,
+		&compensationRetries,
+		&headers,
+		&state.Version,
+	); err != nil {
+		return nil, err
+	}
+
+	state.StepStartedAt = stepStartedAt.Time
+	state.NextRetryAt = nextRetryAt.Time
+	state.StepDeadline = stepDeadline.Time
+
+	if err := json.Unmarshal(completedSteps, &state.CompletedSteps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal completed steps: %w", err)
+	}
+	if err := json.Unmarshal(metadata, &state.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal saga metadata: %w", err)
+	}
+	if err := json.Unmarshal(stepRetries, &state.StepRetries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal step retries: %w", err)
+	}
+	if err := json.Unmarshal(stepResults, &state.StepResults); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal step results: %w", err)
+	}
+	if err := json.Unmarshal(pendingCompensations, &state.PendingCompensations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending compensations: %w", err)
+	}
+	if err := json.Unmarshal(compensationRetries, &state.CompensationRetries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal compensation retries: %w", err)
+	}
+	if err := json.Unmarshal(headers, &state.Headers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal saga headers: %w", err)
+	}
+
+	return &state, nil
+}
+
+// transitioned сообщает, нужно ли писать строку истории: новая сага,
+// смена шага, статуса, очередная попытка или подтверждённая компенсация.
+func transitioned(previous, state *events.SagaState) bool {
+	if previous == nil {
+		return true
+	}
+	return previous.Status != state.Status ||
+		previous.CurrentStep != state.CurrentStep ||
+		previous.FailedStep != state.FailedStep ||
+		previous.RetryCount != state.RetryCount ||
+		len(previous.CompletedSteps) != len(state.CompletedSteps) ||
+		len(previous.PendingCompensations) != len(state.PendingCompensations)
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func nonNilRetries(retries map[string]int) map[string]int {
+	if retries == nil {
+		return map[string]int{}
+	}
+	return retries
+}
+
+func nonNilResults(results map[string]map[string]interface{}) map[string]map[string]interface{} {
+	if results == nil {
+		return map[string]map[string]interface{}{}
+	}
+	return results
+}
+
+func nonNilSteps(steps []string) []string {
+	if steps == nil {
+		return []string{}
+	}
+	return steps
+}
+
+func nonNilMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return map[string]string{}
+	}
+	return metadata
+}
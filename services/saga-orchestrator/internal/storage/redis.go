@@ -6,17 +6,36 @@ import (
 	"errors"
 	"fmt"
 	"github.com/redis/go-redis/v9"
+	"strconv"
 	"time"
 
 	"saga-orchestrator/internal/config"
 	"saga-orchestrator/internal/events"
 )
 
+const (
+	sagaKeyPrefix   = "saga:"
+	statusKeyPrefix = "saga:status:"
+	expiryKey       = "saga:expiry"
+	retriesKey      = "saga:retries"
+	deadlinesKey    = "saga:step-deadlines"
+	inboxKeyPrefix  = "saga-inbox:"
+
+	// Сколько состояние саги живёт в Redis после ExpiresAt, чтобы монитор
+	// таймаутов успел его увидеть и запустить компенсацию.
+	stateRetention = 24 * time.Hour
+
+	maxTxAttempts = 10
+)
+
+var errTxConflict = errors.New("saga state was modified concurrently")
+
 type RedisStorage struct {
-	client *redis.Client
+	client         *redis.Client
+	inboxRetention time.Duration
 }
 
-func NewRedisStorage(cfg config.RedisConfig) (*RedisStorage, error) {
+func NewRedisStorage(cfg config.RedisConfig, inboxRetention time.Duration) (*RedisStorage, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		Password: cfg.Password,
@@ -31,37 +50,56 @@ func NewRedisStorage(cfg config.RedisConfig) (*RedisStorage, error) {
 	}
 
 	return &RedisStorage{
-		client: client,
+		client:         client,
+		inboxRetention: inboxRetention,
 	}, nil
 }
 
-func (rs *RedisStorage) SaveSagaState(ctx context.Context, state *events.SagaState) error {
-	key := fmt.Sprintf("saga:%s", state.ID)
+func sagaKey(sagaID string) string {
+	return sagaKeyPrefix + sagaID
+}
 
-	data, err := json.Marshal(state)
-	if err != nil {
-		return fmt.Errorf("failed to marshal saga state: %w", err)
-	}
+func statusKey(status events.SagaStatus) string {
+	return statusKeyPrefix + string(status)
+}
 
-	ttl := time.Until(state.ExpiresAt)
-	if ttl <= 0 {
-		ttl = 24 * time.Hour
-	}
+func isActive(status events.SagaStatus) bool {
+	return status == events.SagaStatusPending || status == events.SagaStatusInProgress
+}
 
-	if err := rs.client.Set(ctx, key, data, ttl).Err(); err != nil {
-		return fmt.Errorf("failed to save saga state: %w", err)
+// isScheduled сообщает, ждёт ли сага повторов и дедлайнов шагов: у откатываемой
+// саги они относятся к компенсациям.
+func isScheduled(status events.SagaStatus) bool {
+	return isActive(status) || status == events.SagaStatusRollingBack
+}
+
+func (rs *RedisStorage) SaveSagaState(ctx context.Context, state *events.SagaState) error {
+	next := *state
+	next.Version++
+
+	err := rs.withTx(ctx, state.ID, func(previous *events.SagaState) (*events.SagaState, error) {
+		version := 0
+		if previous != nil {
+			version = previous.Version
+		}
+		if version != state.Version {
+			return nil, fmt.Errorf("%w: %s", events.ErrSagaConflict, state.ID)
+		}
+		return &next, nil
+	})
+	if err != nil {
+		return err
 	}
 
+	state.Version = next.Version
 	return nil
 }
 
 func (rs *RedisStorage) GetSagaState(ctx context.Context, sagaID string) (*events.SagaState, error) {
-	key := fmt.Sprintf("saga:%s", sagaID)
-
-	data, err := rs.client.Get(ctx, key).Result()
+	data, err := rs.client.Get(ctx, sagaKey(sagaID)).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return nil, fmt.Errorf("saga not found: %s", sagaID)
+			return nil, fmt.Errorf("%w: %s", events.ErrSagaNotFound, sagaID)
 		}
 		return nil, fmt.Errorf("failed to get saga state: %w", err)
 	}
@@ -75,9 +113,28 @@ func (rs *RedisStorage) GetSagaState(ctx context.Context, sagaID string) (*event
 }
 
 func (rs *RedisStorage) DeleteSagaState(ctx context.Context, sagaID string) error {
-	key := fmt.Sprintf("saga:%s", sagaID)
+	key := sagaKey(sagaID)
 
-	if err := rs.client.Del(ctx, key).Err(); err != nil {
+	txf := func(tx *redis.Tx) error {
+		previous, err := loadState(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			pipe.ZRem(ctx, expiryKey, sagaID)
+			pipe.ZRem(ctx, retriesKey, sagaID)
+			pipe.ZRem(ctx, deadlinesKey, sagaID)
+			if previous != nil {
+				pipe.SRem(ctx, statusKey(previous.Status), sagaID)
+			}
+			return nil
+		})
+		return err
+	}
+
+	if err := rs.watch(ctx, key, txf); err != nil {
 		return fmt.Errorf("failed to delete saga state: %w", err)
 	}
 
@@ -85,122 +142,304 @@ func (rs *RedisStorage) DeleteSagaState(ctx context.Context, sagaID string) erro
 }
 
 func (rs *RedisStorage) GetExpiredSagas(ctx context.Context) ([]*events.SagaState, error) {
-	pattern := "saga:*"
-	var cursor uint64
-	var expiredSagas []*events.SagaState
+	ids, err := rs.client.ZRangeByScore(ctx, expiryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired sagas: %w", err)
+	}
 
-	for {
-		keys, nextCursor, err := rs.client.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan keys: %w", err)
+	sagas, stale, err := rs.loadStates(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiredSagas []*events.SagaState
+	for _, state := range sagas {
+		if isActive(state.Status) {
+			expiredSagas = append(expiredSagas, state)
+		} else {
+			stale = append(stale, state.ID)
 		}
+	}
 
-		for _, key := range keys {
-			data, err := rs.client.Get(ctx, key).Result()
-			if err != nil {
-				if errors.Is(err, redis.Nil) {
-					continue
-				}
-				return nil, fmt.Errorf("failed to get saga state: %w", err)
-			}
+	if len(stale) > 0 {
+		rs.client.ZRem(ctx, expiryKey, toMembers(stale)...)
+	}
 
-			var state events.SagaState
-			if err := json.Unmarshal([]byte(data), &state); err != nil {
-				continue
-			}
+	return expiredSagas, nil
+}
 
-			if time.Now().After(state.ExpiresAt) &&
-				(state.Status == events.SagaStatusPending || state.Status == events.SagaStatusInProgress) {
-				expiredSagas = append(expiredSagas, &state)
-			}
+func (rs *RedisStorage) GetDueRetries(ctx context.Context) ([]*events.SagaState, error) {
+	now := time.Now()
+
+	ids, err := rs.client.ZRangeByScore(ctx, retriesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due retries: %w", err)
+	}
+
+	sagas, stale, err := rs.loadStates(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var dueSagas []*events.SagaState
+	for _, state := range sagas {
+		switch {
+		case !isScheduled(state.Status) || state.NextRetryAt.IsZero():
+			stale = append(stale, state.ID)
+		case !state.NextRetryAt.After(now):
+			dueSagas = append(dueSagas, state)
 		}
+	}
+
+	if len(stale) > 0 {
+		rs.client.ZRem(ctx, retriesKey, toMembers(stale)...)
+	}
+
+	return dueSagas, nil
+}
+
+func (rs *RedisStorage) GetStepTimeouts(ctx context.Context) ([]*events.SagaState, error) {
+	now := time.Now()
+
+	ids, err := rs.client.ZRangeByScore(ctx, deadlinesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step timeouts: %w", err)
+	}
 
-		cursor = nextCursor
-		if cursor == 0 {
-			break
+	sagas, stale, err := rs.loadStates(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var timedOut []*events.SagaState
+	for _, state := range sagas {
+		switch {
+		case !isScheduled(state.Status) || state.StepDeadline.IsZero():
+			stale = append(stale, state.ID)
+		case !state.StepDeadline.After(now):
+			timedOut = append(timedOut, state)
 		}
 	}
 
-	return expiredSagas, nil
+	if len(stale) > 0 {
+		rs.client.ZRem(ctx, deadlinesKey, toMembers(stale)...)
+	}
+
+	return timedOut, nil
 }
 
 func (rs *RedisStorage) GetSagasByStatus(ctx context.Context, status events.SagaStatus) ([]*events.SagaState, error) {
-	pattern := "saga:*"
-	var cursor uint64
+	ids, err := rs.client.SMembers(ctx, statusKey(status)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sagas by status: %w", err)
+	}
+
+	states, stale, err := rs.loadStates(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
 	var sagas []*events.SagaState
+	for _, state := range states {
+		if state.Status == status {
+			sagas = append(sagas, state)
+		} else {
+			stale = append(stale, state.ID)
+		}
+	}
+
+	if len(stale) > 0 {
+		rs.client.SRem(ctx, statusKey(status), toMembers(stale)...)
+	}
+
+	return sagas, nil
+}
+
+func (rs *RedisStorage) IsEventProcessed(ctx context.Context, eventID string) (bool, error) {
+	exists, err := rs.client.Exists(ctx, inboxKeyPrefix+eventID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check processed event: %w", err)
+	}
+
+	return exists > 0, nil
+}
+
+func (rs *RedisStorage) MarkEventProcessed(ctx context.Context, eventID string) error {
+	if err := rs.client.Set(ctx, inboxKeyPrefix+eventID, time.Now().Unix(), rs.inboxRetention).Err(); err != nil {
+		return fmt.Errorf("failed to mark event processed: %w", err)
+	}
 
-	for {
-		keys, nextCursor, err := rs.client.Scan(ctx, cursor, pattern, 100).Result()
+	return nil
+}
+
+// PurgeProcessedEvents ничего не делает: записи инбокса удаляет TTL.
+func (rs *RedisStorage) PurgeProcessedEvents(ctx context.Context) error {
+	return nil
+}
+
+func (rs *RedisStorage) Close() error {
+	return rs.client.Close()
+}
+
+// withTx читает текущее состояние саги под WATCH, применяет mutate и в одном
+// MULTI записывает новое состояние вместе с индексами статусов и сроков.
+func (rs *RedisStorage) withTx(
+	ctx context.Context,
+	sagaID string,
+	mutate func(previous *events.SagaState) (*events.SagaState, error),
+) error {
+	key := sagaKey(sagaID)
+
+	txf := func(tx *redis.Tx) error {
+		previous, err := loadState(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+
+		state, err := mutate(previous)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan keys: %w", err)
+			return err
 		}
 
-		for _, key := range keys {
-			data, err := rs.client.Get(ctx, key).Result()
-			if err != nil {
-				if err == redis.Nil {
-					continue
-				}
-				return nil, fmt.Errorf("failed to get saga state: %w", err)
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to marshal saga state: %w", err)
+		}
+
+		ttl := time.Until(state.ExpiresAt) + stateRetention
+		if ttl <= 0 {
+			ttl = stateRetention
+		}
+		// Неудавшийся откат хранится, пока его не разберёт дежурный
+		if state.Status == events.SagaStatusCompensationFailed {
+			ttl = 0
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, ttl)
+
+			if previous != nil && previous.Status != state.Status {
+				pipe.SRem(ctx, statusKey(previous.Status), state.ID)
+			}
+			pipe.SAdd(ctx, statusKey(state.Status), state.ID)
+
+			if isActive(state.Status) {
+				pipe.ZAdd(ctx, expiryKey, redis.Z{
+					Score:  float64(state.ExpiresAt.UnixMilli()),
+					Member: state.ID,
+				})
+			} else {
+				pipe.ZRem(ctx, expiryKey, state.ID)
 			}
 
-			var state events.SagaState
-			if err := json.Unmarshal([]byte(data), &state); err != nil {
-				continue
+			if isScheduled(state.Status) && !state.NextRetryAt.IsZero() {
+				pipe.ZAdd(ctx, retriesKey, redis.Z{
+					Score:  float64(state.NextRetryAt.UnixMilli()),
+					Member: state.ID,
+				})
+			} else {
+				pipe.ZRem(ctx, retriesKey, state.ID)
 			}
 
-			if state.Status == status {
-				sagas = append(sagas, &state)
+			if isScheduled(state.Status) && !state.StepDeadline.IsZero() {
+				pipe.ZAdd(ctx, deadlinesKey, redis.Z{
+					Score:  float64(state.StepDeadline.UnixMilli()),
+					Member: state.ID,
+				})
+			} else {
+				pipe.ZRem(ctx, deadlinesKey, state.ID)
 			}
-		}
+			return nil
+		})
+		return err
+	}
 
-		cursor = nextCursor
-		if cursor == 0 {
-			break
+	if err := rs.watch(ctx, key, txf); err != nil {
+		return fmt.Errorf("failed to save saga state: %w", err)
+	}
+
+	return nil
+}
+
+func (rs *RedisStorage) watch(ctx context.Context, key string, txf func(tx *redis.Tx) error) error {
+	for i := 0; i < maxTxAttempts; i++ {
+		err := rs.client.Watch(ctx, txf, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
 		}
 	}
 
-	return sagas, nil
+	return errTxConflict
 }
 
-func (rs *RedisStorage) UpdateSagaStep(ctx context.Context, sagaID string, step string, status events.SagaStatus) error {
-	state, err := rs.GetSagaState(ctx, sagaID)
-	if err != nil {
-		return err
+func (rs *RedisStorage) loadStates(ctx context.Context, ids []string) ([]*events.SagaState, []string, error) {
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sagaKey(id)
 	}
 
-	state.CurrentStep = step
-	state.Status = status
-	state.UpdatedAt = time.Now()
+	values, err := rs.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get saga states: %w", err)
+	}
 
-	if status == events.SagaStatusInProgress {
-		found := false
-		for _, completedStep := range state.CompletedSteps {
-			if completedStep == step {
-				found = true
-				break
-			}
+	var (
+		states []*events.SagaState
+		stale  []string
+	)
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// Ключ истёк, а индекс остался — почистим его
+			stale = append(stale, ids[i])
+			continue
 		}
-		if !found {
-			state.CompletedSteps = append(state.CompletedSteps, step)
+
+		var state events.SagaState
+		if err := json.Unmarshal([]byte(data), &state); err != nil {
+			continue
 		}
+		states = append(states, &state)
 	}
 
-	return rs.SaveSagaState(ctx, state)
+	return states, stale, nil
 }
 
-func (rs *RedisStorage) IncrementRetryCount(ctx context.Context, sagaID string) error {
-	state, err := rs.GetSagaState(ctx, sagaID)
+func loadState(ctx context.Context, tx *redis.Tx, key string) (*events.SagaState, error) {
+	data, err := tx.Get(ctx, key).Result()
 	if err != nil {
-		return err
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get saga state: %w", err)
 	}
 
-	state.RetryCount++
-	state.UpdatedAt = time.Now()
+	var state events.SagaState
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal saga state: %w", err)
+	}
 
-	return rs.SaveSagaState(ctx, state)
+	return &state, nil
 }
 
-func (rs *RedisStorage) Close() error {
-	return rs.client.Close()
+func toMembers(ids []string) []interface{} {
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	return members
 }
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saga_step_history;
+DROP TABLE IF EXISTS sagas;
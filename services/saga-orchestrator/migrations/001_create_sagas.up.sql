@@ -0,0 +1,31 @@
+CREATE TABLE sagas (
+    id              UUID PRIMARY KEY,
+    type            VARCHAR(64) NOT NULL,
+    user_id         VARCHAR(64) NOT NULL,
+    status          VARCHAR(32) NOT NULL,
+    current_step    VARCHAR(64) NOT NULL DEFAULT '',
+    failed_step     VARCHAR(64) NOT NULL DEFAULT '',
+    completed_steps JSONB NOT NULL DEFAULT '[]',
+    retry_count     INT NOT NULL DEFAULT 0,
+    metadata        JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_sagas_status ON sagas(status);
+CREATE INDEX idx_sagas_expires_at ON sagas(expires_at) WHERE status IN ('pending', 'in_progress');
+CREATE INDEX idx_sagas_user_id ON sagas(user_id);
+CREATE INDEX idx_sagas_type_created_at ON sagas(type, created_at);
+
+CREATE TABLE saga_step_history (
+    id          BIGSERIAL PRIMARY KEY,
+    saga_id     UUID NOT NULL REFERENCES sagas(id) ON DELETE CASCADE,
+    step        VARCHAR(64) NOT NULL DEFAULT '',
+    status      VARCHAR(32) NOT NULL,
+    failed_step VARCHAR(64) NOT NULL DEFAULT '',
+    retry_count INT NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_saga_step_history_saga_id ON saga_step_history(saga_id, recorded_at);
@@ -0,0 +1 @@
+ALTER TABLE sagas ADD COLUMN step_started_at TIMESTAMPTZ;
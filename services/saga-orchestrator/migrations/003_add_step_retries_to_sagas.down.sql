@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_sagas_next_retry_at;
+
+ALTER TABLE sagas
+    DROP COLUMN IF EXISTS next_retry_at,
+    DROP COLUMN IF EXISTS scheduled_step,
+    DROP COLUMN IF EXISTS step_retries;
@@ -0,0 +1,6 @@
+ALTER TABLE sagas
+    ADD COLUMN step_retries   JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN scheduled_step VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN next_retry_at  TIMESTAMPTZ;
+
+CREATE INDEX idx_sagas_next_retry_at ON sagas(next_retry_at) WHERE next_retry_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_sagas_step_deadline;
+
+ALTER TABLE sagas DROP COLUMN IF EXISTS step_deadline;
@@ -0,0 +1,3 @@
+ALTER TABLE sagas ADD COLUMN step_deadline TIMESTAMPTZ;
+
+CREATE INDEX idx_sagas_step_deadline ON sagas(step_deadline) WHERE step_deadline IS NOT NULL;
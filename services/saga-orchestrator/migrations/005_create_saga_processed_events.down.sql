@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saga_processed_events;
@@ -0,0 +1,6 @@
+CREATE TABLE saga_processed_events (
+    event_id     VARCHAR(64) PRIMARY KEY,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_saga_processed_events_processed_at ON saga_processed_events(processed_at);
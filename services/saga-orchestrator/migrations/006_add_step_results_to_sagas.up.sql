@@ -0,0 +1,2 @@
+ALTER TABLE sagas
+    ADD COLUMN step_results JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+ALTER TABLE sagas
+    DROP COLUMN IF EXISTS compensation_retries,
+    DROP COLUMN IF EXISTS pending_compensations;
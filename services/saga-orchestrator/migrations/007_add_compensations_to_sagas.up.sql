@@ -0,0 +1,3 @@
+ALTER TABLE sagas
+    ADD COLUMN pending_compensations JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN compensation_retries  JSONB NOT NULL DEFAULT '{}';
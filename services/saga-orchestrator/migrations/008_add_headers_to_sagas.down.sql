@@ -0,0 +1,2 @@
+ALTER TABLE sagas
+    DROP COLUMN IF EXISTS headers;
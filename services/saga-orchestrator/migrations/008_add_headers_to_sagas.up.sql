@@ -0,0 +1,2 @@
+ALTER TABLE sagas
+    ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+ALTER TABLE sagas
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+DELETE FROM saga_step_history h
+    WHERE NOT EXISTS (SELECT 1 FROM sagas s WHERE s.id = h.saga_id);
+
+ALTER TABLE saga_step_history
+    ADD CONSTRAINT saga_step_history_saga_id_fkey
+        FOREIGN KEY (saga_id) REFERENCES sagas(id) ON DELETE CASCADE;
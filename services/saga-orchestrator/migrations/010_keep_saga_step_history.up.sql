@@ -0,0 +1,2 @@
+ALTER TABLE saga_step_history
+    DROP CONSTRAINT IF EXISTS saga_step_history_saga_id_fkey;
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -27,14 +28,33 @@ func main() {
 	application := app.New(log, cfg.Grpc.Port, db, cfg)
 	go application.GRPCServer.MustRun()
 
+	ctx, cancel := context.WithCancel(context.Background())
+
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		if err := application.StartKafkaConsumer(ctx); err != nil {
+			log.Error("Kafka consumer failed", "error", err)
+			cancel()
+		}
+	}()
+
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
-	sign := <-signalChan
-
-	log.Info("Stopping application", slog.String("signal", sign.String()))
+	select {
+	case sign := <-signalChan:
+		log.Info("Stopping application", slog.String("signal", sign.String()))
+	case <-ctx.Done():
+	}
 
+	cancel()
+	<-consumerDone
 	application.GRPCServer.Stop()
 
+	if err := application.Close(); err != nil {
+		log.Error("Failed to close application", "error", err)
+	}
+
 	log.Info("Application stopped")
 
 }
@@ -1,14 +1,21 @@
 package app
 
 import (
+	"context"
 	"gorm.io/gorm"
 	"log/slog"
+	"shiroyama/events"
 	grpcapp "taskservice/internal/app/grpc"
 	"taskservice/internal/config"
+	postgresrepo "taskservice/internal/repository"
+	"taskservice/kafka"
 )
 
 type App struct {
-	GRPCServer *grpcapp.App
+	GRPCServer    *grpcapp.App
+	KafkaConsumer *kafka.Consumer
+	KafkaProducer *kafka.Producer
+	logger        *slog.Logger
 }
 
 func New(
@@ -17,7 +24,58 @@ func New(
 	db *gorm.DB,
 	cfg *config.Config,
 ) *App {
+	kafkaProducer, err := kafka.NewKafkaProducer(cfg.Kafka.Brokers)
+	if err != nil {
+		logger.Error("Failed to create Kafka producer", "error", err)
+		panic(err)
+	}
+
+	kafkaConsumer, err := kafka.NewConsumer(kafka.ConsumerConfig{
+		Brokers:    cfg.Kafka.Brokers,
+		GroupID:    cfg.Kafka.GroupID,
+		Repository: postgresrepo.NewTaskRepository(db),
+		Producer:   kafkaProducer,
+		DB:         db,
+		Inbox:      cfg.Kafka.Inbox,
+		DeadLetter: cfg.Kafka.DeadLetter,
+		Outbox:     cfg.Kafka.Outbox,
+		Logger:     logger,
+	})
+	if err != nil {
+		logger.Error("Failed to create Kafka consumer", "error", err)
+		kafkaProducer.Close()
+		panic(err)
+	}
+
 	return &App{
-		GRPCServer: grpcapp.New(logger, grpcPort, db, cfg),
+		GRPCServer:    grpcapp.New(logger, grpcPort, db, cfg),
+		KafkaConsumer: kafkaConsumer,
+		KafkaProducer: kafkaProducer,
+		logger:        logger,
 	}
 }
+
+func (app *App) StartKafkaConsumer(ctx context.Context) error {
+	topics := []string{events.TaskCommandsTopic}
+
+	app.logger.Info("Starting Kafka consumer", "topics", topics)
+	return app.KafkaConsumer.Start(ctx, topics)
+}
+
+func (app *App) Close() error {
+	app.logger.Info("Closing application resources")
+
+	var lastErr error
+
+	if err := app.KafkaConsumer.Close(); err != nil {
+		app.logger.Error("Failed to close Kafka consumer", "error", err)
+		lastErr = err
+	}
+
+	if err := app.KafkaProducer.Close(); err != nil {
+		app.logger.Error("Failed to close Kafka producer", "error", err)
+		lastErr = err
+	}
+
+	return lastErr
+}
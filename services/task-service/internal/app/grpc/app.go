@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 	"log/slog"
 	"net"
+	"shiroyama/authz"
 	"taskservice/internal/clients"
 	"taskservice/internal/config"
 	handler "taskservice/internal/handler/grpc/taskservice"
@@ -21,7 +22,7 @@ type App struct {
 }
 
 func New(log *slog.Logger, port int, db *gorm.DB, cfg *config.Config) *App {
-	gRPCServer := grpc.NewServer()
+	gRPCServer := grpc.NewServer(grpc.UnaryInterceptor(authz.UnaryServerInterceptor(authz.NewJWKS(cfg.JWT.JWKSURL, cfg.JWT.JWKSCacheTTL))))
 	serviceClients, err := clients.NewServiceClients(&clients.ClientConfig{
 		UserServiceAddr:  cfg.Clients.BoardServiceAddr,
 		BoardServiceAddr: cfg.Clients.TeamServiceAddr,
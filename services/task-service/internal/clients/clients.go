@@ -9,6 +9,7 @@ import (
 	userv1 "github.com/cms-crs/protos/gen/go/user_service"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"shiroyama/authz"
 )
 
 type ServiceClients struct {
@@ -30,6 +31,7 @@ func NewServiceClients(cfg *ClientConfig) (*ServiceClients, error) {
 
 	userConn, err := grpc.DialContext(ctx, cfg.UserServiceAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(authz.ForwardToken),
 		grpc.WithBlock(),
 	)
 	if err != nil {
@@ -38,6 +40,7 @@ func NewServiceClients(cfg *ClientConfig) (*ServiceClients, error) {
 
 	boardConn, err := grpc.DialContext(ctx, cfg.BoardServiceAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(authz.ForwardToken),
 		grpc.WithBlock(),
 	)
 	if err != nil {
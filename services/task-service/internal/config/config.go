@@ -10,7 +10,9 @@ type Config struct {
 	Env     string         `yaml:"env" env-default:"local"`
 	Grpc    GRPCConfig     `yaml:"grpc"`
 	DB      DatabaseConfig `yaml:"db"`
+	Kafka   KafkaConfig    `yaml:"kafka"`
 	Clients ClientsConfig  `yaml:"clients"`
+	JWT     JWTConfig      `yaml:"jwt"`
 }
 
 func MustLoad() *Config {
@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+type KafkaConfig struct {
+	Brokers    []string         `yaml:"brokers" env-default:"localhost:29092"`
+	GroupID    string           `yaml:"group_id" env-default:"task-service-group"`
+	Inbox      InboxConfig      `yaml:"inbox"`
+	DeadLetter DeadLetterConfig `yaml:"dead_letter"`
+	Outbox     OutboxConfig     `yaml:"outbox"`
+}
+
+type InboxConfig struct {
+	Retention       time.Duration `yaml:"retention" env-default:"168h"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+}
+
+type OutboxConfig struct {
+	PollInterval    time.Duration `yaml:"poll_interval" env-default:"1s"`
+	BatchSize       int           `yaml:"batch_size" env-default:"100"`
+	Retention       time.Duration `yaml:"retention" env-default:"72h"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+}
+
+type DeadLetterConfig struct {
+	// Сколько раз обработать сообщение, прежде чем отправить его в <topic>.dlq
+	MaxAttempts int           `yaml:"max_attempts" env-default:"3"`
+	Backoff     time.Duration `yaml:"backoff" env-default:"500ms"`
+}
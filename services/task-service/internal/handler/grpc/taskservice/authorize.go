@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"shiroyama/authz"
+)
+
+// caller возвращает пользователя, от имени которого пришёл вызов. Права в
+// команде проверяет сервисный слой: board-service проверяет роль вызывающего
+// по списку задачи (а при создании и переносе — по целевому списку), когда
+// сервис обращается к нему с токеном вызывающего.
+func caller(ctx context.Context) (string, error) {
+	userID, ok := authz.CallerFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "authentication required")
+	}
+	return userID, nil
+}
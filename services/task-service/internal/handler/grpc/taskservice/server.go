@@ -4,6 +4,8 @@ import (
 	"context"
 	taskv1 "github.com/cms-crs/protos/gen/go/task_service"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"log/slog"
 )
@@ -45,6 +47,14 @@ func (h *Handler) CreateTask(ctx context.Context, req *taskv1.CreateTaskRequest)
 
 	log.Info("Create task request received")
 
+	userID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userID != req.CreatedBy {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
 	task, err := h.taskService.CreateTask(ctx, req)
 	if err != nil {
 		log.Error("Failed to create task", "error", err)
@@ -66,6 +76,10 @@ func (h *Handler) GetTask(ctx context.Context, req *taskv1.GetTaskRequest) (*tas
 
 	log.Info("Get task request received")
 
+	if _, err := caller(ctx); err != nil {
+		return nil, err
+	}
+
 	task, err := h.taskService.GetTask(ctx, req)
 	if err != nil {
 		log.Error("Failed to get task", "error", err)
@@ -88,6 +102,10 @@ func (h *Handler) UpdateTask(ctx context.Context, req *taskv1.UpdateTaskRequest)
 
 	log.Info("Update task request received")
 
+	if _, err := caller(ctx); err != nil {
+		return nil, err
+	}
+
 	task, err := h.taskService.UpdateTask(ctx, req)
 	if err != nil {
 		log.Error("Failed to update task", "error", err)
@@ -109,6 +127,10 @@ func (h *Handler) DeleteTask(ctx context.Context, req *taskv1.DeleteTaskRequest)
 
 	log.Info("Delete task request received")
 
+	if _, err := caller(ctx); err != nil {
+		return nil, err
+	}
+
 	result, err := h.taskService.DeleteTask(ctx, req)
 	if err != nil {
 		log.Error("Failed to delete task", "error", err)
@@ -132,6 +154,10 @@ func (h *Handler) MoveTask(ctx context.Context, req *taskv1.MoveTaskRequest) (*t
 
 	log.Info("Move task request received")
 
+	if _, err := caller(ctx); err != nil {
+		return nil, err
+	}
+
 	task, err := h.taskService.MoveTask(ctx, req)
 	if err != nil {
 		log.Error("Failed to move task", "error", err)
@@ -154,6 +180,10 @@ func (h *Handler) AssignUser(ctx context.Context, req *taskv1.AssignUserRequest)
 
 	log.Info("Assign user request received")
 
+	if _, err := caller(ctx); err != nil {
+		return nil, err
+	}
+
 	task, err := h.taskService.AssignUser(ctx, req)
 	if err != nil {
 		log.Error("Failed to assign user", "error", err)
@@ -176,6 +206,10 @@ func (h *Handler) UnassignUser(ctx context.Context, req *taskv1.UnassignUserRequ
 
 	log.Info("Unassign user request received")
 
+	if _, err := caller(ctx); err != nil {
+		return nil, err
+	}
+
 	task, err := h.taskService.UnassignUser(ctx, req)
 	if err != nil {
 		log.Error("Failed to unassign user", "error", err)
@@ -197,6 +231,10 @@ func (h *Handler) GetTasksForLists(ctx context.Context, req *taskv1.GetTasksForL
 
 	log.Info("Get tasks for lists request received")
 
+	if _, err := caller(ctx); err != nil {
+		return nil, err
+	}
+
 	response, err := h.taskService.GetTasksForLists(ctx, req)
 	if err != nil {
 		log.Error("Failed to get tasks for lists", "error", err)
@@ -218,6 +256,10 @@ func (h *Handler) GetTasksForUser(ctx context.Context, req *taskv1.GetTasksForUs
 
 	log.Info("Get tasks for user request received")
 
+	if _, err := caller(ctx); err != nil {
+		return nil, err
+	}
+
 	response, err := h.taskService.GetTasksForUser(ctx, req)
 	if err != nil {
 		log.Error("Failed to get tasks for user", "error", err)
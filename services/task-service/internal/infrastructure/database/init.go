@@ -2,6 +2,7 @@ package database
 
 import (
 	"gorm.io/gorm"
+	"shiroyama/messaging/gormstore"
 	"taskservice/internal/config"
 	"taskservice/internal/entity"
 )
@@ -13,7 +14,7 @@ func MustLoad(cfg *config.Config) *gorm.DB {
 		panic(err.Error())
 	}
 
-	err = db.AutoMigrate(&entity.Task{})
+	err = db.AutoMigrate(&entity.Task{}, &gormstore.ProcessedEvent{}, &gormstore.OutboxEvent{})
 	if err != nil {
 		panic(err.Error())
 	}
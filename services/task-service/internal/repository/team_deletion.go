@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"shiroyama/events"
+	"taskservice/kafka"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RemoveTeamTasks помечает удалёнными задачи списков удаляемой команды и
+// возвращает их ID для отката. beforeCommit выполняется в той же транзакции.
+func (r *TaskRepository) RemoveTeamTasks(ctx context.Context, listIDs []string, beforeCommit kafka.TeamBeforeCommit) (*events.TeamTasksDeletionData, error) {
+	data := &events.TeamTasksDeletionData{
+		TaskIDs: []string{},
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(listIDs) > 0 {
+			if err := tx.Model(&Task{}).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("list_id IN ? AND deleted_at IS NULL", listIDs).
+				Pluck("id", &data.TaskIDs).Error; err != nil {
+				return fmt.Errorf("failed to get team tasks: %w", err)
+			}
+		}
+
+		if len(data.TaskIDs) > 0 {
+			if err := tx.Model(&Task{}).
+				Where("id IN ?", data.TaskIDs).
+				Update("deleted_at", time.Now()).Error; err != nil {
+				return fmt.Errorf("failed to delete team tasks: %w", err)
+			}
+		}
+
+		if beforeCommit != nil {
+			return beforeCommit(tx, data)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// RestoreTeamTasks снимает пометку удаления с задач из rollback_data.
+func (r *TaskRepository) RestoreTeamTasks(ctx context.Context, data *events.TeamTasksDeletionData) error {
+	if len(data.TaskIDs) == 0 {
+		return nil
+	}
+
+	if err := r.db.WithContext(ctx).Model(&Task{}).
+		Where("id IN ? AND deleted_at IS NOT NULL", data.TaskIDs).
+		Update("deleted_at", nil).Error; err != nil {
+		return fmt.Errorf("failed to restore team tasks: %w", err)
+	}
+
+	return nil
+}
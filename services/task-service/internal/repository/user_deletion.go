@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"shiroyama/events"
+	"taskservice/kafka"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RemoveUserFromTasks снимает назначения пользователя (soft delete) и переписывает
+// его задачи на kafka.DeletedUserID. Возвращает ID изменённых строк для отката.
+// beforeCommit выполняется в той же транзакции.
+func (r *TaskRepository) RemoveUserFromTasks(ctx context.Context, userID string, beforeCommit kafka.BeforeCommit) (*events.TaskDeletionData, error) {
+	data := &events.TaskDeletionData{
+		AssignmentIDs: []uint{},
+		TaskIDs:       []string{},
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if err := tx.Model(&TaskAssignment{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND deleted_at IS NULL", userID).
+			Pluck("id", &data.AssignmentIDs).Error; err != nil {
+			return fmt.Errorf("failed to get user assignments: %w", err)
+		}
+
+		if len(data.AssignmentIDs) > 0 {
+			if err := tx.Model(&TaskAssignment{}).
+				Where("id IN ?", data.AssignmentIDs).
+				Update("deleted_at", now).Error; err != nil {
+				return fmt.Errorf("failed to delete user assignments: %w", err)
+			}
+		}
+
+		if err := tx.Model(&Task{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("created_by = ? AND deleted_at IS NULL", userID).
+			Pluck("id", &data.TaskIDs).Error; err != nil {
+			return fmt.Errorf("failed to get user tasks: %w", err)
+		}
+
+		if len(data.TaskIDs) > 0 {
+			if err := tx.Model(&Task{}).
+				Where("id IN ?", data.TaskIDs).
+				Updates(map[string]interface{}{
+					"created_by": kafka.DeletedUserID,
+					"updated_at": now,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to reattribute user tasks: %w", err)
+			}
+		}
+
+		if beforeCommit != nil {
+			return beforeCommit(tx, data)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// RestoreUserTasks возвращает ровно те назначения и задачи, что были изменены
+// RemoveUserFromTasks. Задачи, которые успели переписать на другого автора, не трогаем.
+func (r *TaskRepository) RestoreUserTasks(ctx context.Context, userID string, data *events.TaskDeletionData) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(data.AssignmentIDs) > 0 {
+			if err := tx.Model(&TaskAssignment{}).
+				Where("id IN ? AND user_id = ? AND deleted_at IS NOT NULL", data.AssignmentIDs, userID).
+				Update("deleted_at", nil).Error; err != nil {
+				return fmt.Errorf("failed to restore user assignments: %w", err)
+			}
+		}
+
+		if len(data.TaskIDs) > 0 {
+			if err := tx.Model(&Task{}).
+				Where("id IN ? AND created_by = ?", data.TaskIDs, kafka.DeletedUserID).
+				Updates(map[string]interface{}{
+					"created_by": userID,
+					"updated_at": time.Now(),
+				}).Error; err != nil {
+				return fmt.Errorf("failed to restore tasks creator: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -6,6 +6,8 @@ import (
 	boardv1 "github.com/cms-crs/protos/gen/go/board_service"
 	taskv1 "github.com/cms-crs/protos/gen/go/task_service"
 	userv1 "github.com/cms-crs/protos/gen/go/user_service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log/slog"
 )
 
@@ -50,6 +52,24 @@ func (s *Service) validateListExists(ctx context.Context, listID string) error {
 	return nil
 }
 
+// authorizeTask проверяет право вызывающего менять задачу: board-service
+// проверяет его роль в команде списка задачи так же, как при создании.
+func (s *Service) authorizeTask(ctx context.Context, task *taskv1.Task) error {
+	err := s.validateListExists(ctx, task.ListId)
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.PermissionDenied:
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case codes.Unauthenticated:
+		return status.Error(codes.Unauthenticated, "authentication required")
+	case codes.NotFound:
+		return status.Error(codes.NotFound, "task not found")
+	default:
+		return status.Error(codes.Unavailable, "failed to check permissions")
+	}
+}
+
 func (s *Service) validateBoardExists(ctx context.Context, boardID string) error {
 	_, err := s.boardClient.GetBoard(ctx, &boardv1.GetBoardRequest{Id: boardID})
 	if err != nil {
@@ -22,12 +22,17 @@ func (s *Service) DeleteTask(ctx context.Context, req *taskv1.DeleteTaskRequest)
 		return nil, status.Error(codes.InvalidArgument, "task id is required")
 	}
 
-	_, err := s.taskRepo.GetTask(ctx, req.Id)
+	existing, err := s.taskRepo.GetTask(ctx, req.Id)
 	if err != nil {
 		log.Error("Task not found", "task_id", req.Id, "error", err)
 		return nil, status.Error(codes.NotFound, "task not found")
 	}
 
+	if err := s.authorizeTask(ctx, existing); err != nil {
+		log.Warn("Task access denied", "list_id", existing.ListId, "error", err)
+		return nil, err
+	}
+
 	log.Info("Deleting task")
 
 	err = s.taskRepo.DeleteTask(ctx, req.Id)
@@ -27,12 +27,17 @@ func (s *Service) MoveTask(ctx context.Context, req *taskv1.MoveTaskRequest) (*t
 		return nil, status.Error(codes.InvalidArgument, "to_list_id is required")
 	}
 
-	_, err := s.taskRepo.GetTask(ctx, req.TaskId)
+	existing, err := s.taskRepo.GetTask(ctx, req.TaskId)
 	if err != nil {
 		log.Error("Task not found", "task_id", req.TaskId, "error", err)
 		return nil, status.Error(codes.NotFound, "task not found")
 	}
 
+	if err := s.authorizeTask(ctx, existing); err != nil {
+		log.Warn("Task access denied", "list_id", existing.ListId, "error", err)
+		return nil, err
+	}
+
 	if err := s.validateListExists(ctx, req.ToListId); err != nil {
 		log.Error("Target list validation failed", "list_id", req.ToListId, "error", err)
 		return nil, status.Error(codes.InvalidArgument, "target list not found")
@@ -28,12 +28,17 @@ func (s *Service) UnassignUser(ctx context.Context, req *taskv1.UnassignUserRequ
 		return nil, status.Error(codes.InvalidArgument, "user_id is required")
 	}
 
-	_, err := s.taskRepo.GetTask(ctx, req.TaskId)
+	existing, err := s.taskRepo.GetTask(ctx, req.TaskId)
 	if err != nil {
 		log.Error("Task not found", "task_id", req.TaskId, "error", err)
 		return nil, status.Error(codes.NotFound, "task not found")
 	}
 
+	if err := s.authorizeTask(ctx, existing); err != nil {
+		log.Warn("Task access denied", "list_id", existing.ListId, "error", err)
+		return nil, err
+	}
+
 	_, err = s.userClient.GetUser(ctx, &userv1.GetUserRequest{Id: req.UserId})
 	if err != nil {
 		log.Error("User not found", "user_id", req.UserId, "error", err)
@@ -0,0 +1,179 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"shiroyama/events"
+	"shiroyama/events/bus"
+	"shiroyama/messaging/dlq"
+	"sync"
+	"taskservice/internal/config"
+	"time"
+
+	"github.com/IBM/sarama"
+	"gorm.io/gorm"
+)
+
+type Consumer struct {
+	consumerGroup        sarama.ConsumerGroup
+	handler              bus.Handler
+	dlq                  *dlq.Queue
+	inbox                *Inbox
+	outbox               *Outbox
+	inboxCleanupInterval time.Duration
+	logger               *slog.Logger
+}
+
+type ConsumerConfig struct {
+	Brokers    []string
+	GroupID    string
+	Repository TaskRepository
+	Producer   *Producer
+	DB         *gorm.DB
+	Inbox      config.InboxConfig
+	DeadLetter config.DeadLetterConfig
+	Outbox     config.OutboxConfig
+	Logger     *slog.Logger
+}
+
+func NewConsumer(cfg ConsumerConfig) (*Consumer, error) {
+	inbox := NewInbox(cfg.DB, cfg.GroupID, cfg.Inbox.Retention, cfg.Logger)
+	deadLetters := dlq.New(cfg.Producer, cfg.GroupID, dlq.Config{
+		MaxAttempts: cfg.DeadLetter.MaxAttempts,
+		Backoff:     cfg.DeadLetter.Backoff,
+	}, cfg.Logger)
+	outbox := NewOutbox(cfg.DB, "task-service", cfg.Producer, cfg.Outbox, cfg.Logger)
+	consumerHandler := NewTaskConsumer(cfg.Repository, inbox, outbox, cfg.Logger)
+
+	configSarama := sarama.NewConfig()
+	configSarama.Version = sarama.V2_8_0_0
+	configSarama.Consumer.Offsets.Initial = sarama.OffsetNewest
+	configSarama.Consumer.MaxProcessingTime = 20 * time.Second
+	configSarama.Consumer.Group.Heartbeat.Interval = 6 * time.Second
+	configSarama.Consumer.Return.Errors = true
+
+	consumerGroup, err := sarama.NewConsumerGroup(cfg.Brokers, cfg.GroupID, configSarama)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Consumer{
+		consumerGroup:        consumerGroup,
+		handler:              consumerHandler,
+		dlq:                  deadLetters,
+		inbox:                inbox,
+		outbox:               outbox,
+		inboxCleanupInterval: cfg.Inbox.CleanupInterval,
+		logger:               cfg.Logger,
+	}, nil
+}
+
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error {
+	c.logger.Info("Task consumer setup completed")
+	return nil
+}
+
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
+	c.logger.Info("Task consumer cleanup completed")
+	return nil
+}
+
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case message := <-claim.Messages():
+			if message == nil {
+				return nil
+			}
+
+			if err := c.dlq.Process(session.Context(), message, c.handleMessage); err != nil {
+				// Сообщение не обработано и не попало в DLQ — offset не коммитим
+				c.logger.Error("Failed to handle message",
+					"error", err,
+					"topic", message.Topic,
+					"partition", message.Partition,
+					"offset", message.Offset)
+				return err
+			}
+
+			session.MarkMessage(message, "")
+
+		case <-session.Context().Done():
+			c.logger.Info("Consumer session context cancelled")
+			return nil
+		}
+	}
+}
+
+func (c *Consumer) handleMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
+	event, err := events.Unmarshal(message.Value)
+	if err != nil {
+		return dlq.Permanent(fmt.Errorf("failed to unmarshal event: %w", err))
+	}
+
+	return c.handler.HandleEvent(ctx, event)
+}
+
+func (c *Consumer) Start(ctx context.Context, topics []string) error {
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+
+	go func() {
+		defer wg.Done()
+		c.inbox.StartCleanup(ctx, c.inboxCleanupInterval)
+	}()
+
+	go func() {
+		defer wg.Done()
+		c.outbox.StartRelay(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		for {
+			if err := c.consumerGroup.Consume(ctx, topics, c); err != nil {
+				c.logger.Error("Error from consumer", "error", err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+					continue
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-c.consumerGroup.Errors():
+				if !ok {
+					return
+				}
+				c.logger.Error("Consumer group error", "error", err)
+			}
+		}
+	}()
+
+	c.logger.Info("Kafka consumer started", "topics", topics)
+
+	<-ctx.Done()
+	c.logger.Info("Shutting down Kafka consumer...")
+
+	wg.Wait()
+
+	c.logger.Info("Kafka consumer stopped")
+	return nil
+}
+
+func (c *Consumer) Close() error {
+	return c.consumerGroup.Close()
+}
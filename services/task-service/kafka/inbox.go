@@ -0,0 +1,18 @@
+package kafka
+
+import (
+	"log/slog"
+	"shiroyama/messaging/gormstore"
+	"shiroyama/messaging/inbox"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Inbox запоминает ID обработанных событий, чтобы повторная доставка из
+// Kafka не снимала и не возвращала назначения задач второй раз.
+type Inbox = inbox.Inbox[*gorm.DB]
+
+func NewInbox(db *gorm.DB, consumer string, retention time.Duration, logger *slog.Logger) *Inbox {
+	return inbox.New(gormstore.NewInbox(db), consumer, retention, logger)
+}
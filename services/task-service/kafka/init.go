@@ -0,0 +1,18 @@
+package kafka
+
+import (
+	"shiroyama/events"
+
+	"gorm.io/gorm"
+)
+
+// DeletedUserID — автор, которому переписываются задачи удалённого пользователя.
+// created_by не может быть пустым, поэтому используется нулевой UUID.
+const DeletedUserID = "00000000-0000-0000-0000-000000000000"
+
+// BeforeCommit вызывается внутри транзакции удаления, чтобы ответ саге
+// попал в outbox атомарно с изменениями.
+type BeforeCommit func(tx *gorm.DB, data *events.TaskDeletionData) error
+
+// TeamBeforeCommit — то же для удаления задач команды.
+type TeamBeforeCommit func(tx *gorm.DB, data *events.TeamTasksDeletionData) error
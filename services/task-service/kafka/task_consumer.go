@@ -0,0 +1,262 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"shiroyama/events"
+	"shiroyama/messaging/dlq"
+
+	"gorm.io/gorm"
+)
+
+type TaskRepository interface {
+	RemoveUserFromTasks(ctx context.Context, userID string, beforeCommit BeforeCommit) (*events.TaskDeletionData, error)
+	RestoreUserTasks(ctx context.Context, userID string, data *events.TaskDeletionData) error
+	RemoveTeamTasks(ctx context.Context, listIDs []string, beforeCommit TeamBeforeCommit) (*events.TeamTasksDeletionData, error)
+	RestoreTeamTasks(ctx context.Context, data *events.TeamTasksDeletionData) error
+}
+
+type TaskConsumer struct {
+	taskRepo TaskRepository
+	inbox    *Inbox
+	outbox   *Outbox
+	logger   *slog.Logger
+}
+
+func NewTaskConsumer(repo TaskRepository, inbox *Inbox, outbox *Outbox, logger *slog.Logger) *TaskConsumer {
+	return &TaskConsumer{
+		taskRepo: repo,
+		inbox:    inbox,
+		outbox:   outbox,
+		logger:   logger,
+	}
+}
+
+func (tc *TaskConsumer) HandleEvent(ctx context.Context, event events.Event) error {
+	ctx = events.ContextWithTrace(ctx, event.Trace())
+
+	tc.logger.Info("Processing event",
+		"event_id", event.ID,
+		"event_type", event.Type,
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"trace", event.Trace())
+
+	processed, err := tc.inbox.IsProcessed(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		tc.logger.Info("Skipping already processed event",
+			"event_id", event.ID,
+			"saga_id", event.SagaID)
+		return nil
+	}
+
+	switch event.Type {
+	case events.TaskUserDeleteRequested:
+		return tc.handleTaskUserDeleteRequested(ctx, event)
+	case events.TaskUserDeleteRollback:
+		return tc.handleTaskUserDeleteRollback(ctx, event)
+	case events.TaskTeamDeleteRequested:
+		return tc.handleTaskTeamDeleteRequested(ctx, event)
+	case events.TaskTeamDeleteRollback:
+		return tc.handleTaskTeamDeleteRollback(ctx, event)
+	default:
+		tc.logger.Warn("Unknown event type", "event_type", event.Type)
+		return nil
+	}
+}
+
+func (tc *TaskConsumer) handleTaskUserDeleteRequested(ctx context.Context, event events.Event) error {
+	// Изменения, отметка inbox и ответ саге коммитятся вместе
+	deletionData, err := tc.taskRepo.RemoveUserFromTasks(ctx, event.UserID, func(tx *gorm.DB, data *events.TaskDeletionData) error {
+		if _, err := tc.inbox.MarkProcessedTx(ctx, tx, event.ID); err != nil {
+			return err
+		}
+
+		return tc.outbox.EnqueueTx(ctx, tx, events.TaskEventsTopic, tc.reply(event, events.TaskUserDeleted, map[string]interface{}{
+			"assignments_deleted_count": len(data.AssignmentIDs),
+			"tasks_reattributed_count":  len(data.TaskIDs),
+			"rollback_data":             data,
+		}))
+	})
+	if err != nil {
+		tc.logger.Error("Failed to remove user from tasks",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID,
+			"error", err)
+
+		// Ответ о неудаче — это результат обработки: оркестратор сам решит,
+		// повторить шаг или откатить сагу
+		return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskUserDeleteFailed, map[string]interface{}{
+			"error": err.Error(),
+		}))
+	}
+
+	tc.logger.Info("Removed user from tasks",
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"assignments_count", len(deletionData.AssignmentIDs),
+		"tasks_count", len(deletionData.TaskIDs))
+
+	return nil
+}
+
+func (tc *TaskConsumer) handleTaskUserDeleteRollback(ctx context.Context, event events.Event) error {
+	var deletionData events.TaskDeletionData
+	if rollbackData, exists := event.Data["rollback_data"]; exists {
+		rollbackBytes, err := json.Marshal(rollbackData)
+		if err == nil {
+			err = json.Unmarshal(rollbackBytes, &deletionData)
+		}
+		if err != nil {
+			return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskUserDeleteRollbackFailed, map[string]interface{}{
+				"error": fmt.Sprintf("invalid rollback_data: %v", err),
+			}))
+		}
+	}
+
+	// Без rollback_data восстанавливать нечего: выбирать строки по user_id
+	// нельзя, это вернёт и назначения, снятые вручную до удаления
+	if len(deletionData.AssignmentIDs) == 0 && len(deletionData.TaskIDs) == 0 {
+		tc.logger.Warn("Rollback event has no tasks to restore",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID)
+		return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskUserDeleteRollbackCompleted, nil))
+	}
+
+	if err := tc.taskRepo.RestoreUserTasks(ctx, event.UserID, &deletionData); err != nil {
+		tc.logger.Error("Failed to restore user tasks",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID,
+			"error", err)
+		return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskUserDeleteRollbackFailed, map[string]interface{}{
+			"error": err.Error(),
+		}))
+	}
+
+	tc.logger.Info("Restored user tasks",
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"assignments_count", len(deletionData.AssignmentIDs),
+		"tasks_count", len(deletionData.TaskIDs))
+
+	// Восстановление идемпотентно: если подтверждение не запишется, откат
+	// повторится при повторной доставке
+	return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskUserDeleteRollbackCompleted, nil))
+}
+
+func (tc *TaskConsumer) handleTaskTeamDeleteRequested(ctx context.Context, event events.Event) error {
+	teamID, _ := event.Data["team_id"].(string)
+
+	// Задачи связаны с командой только через списки её досок: их присылает
+	// оркестратор из ответа board-service
+	var listIDs []string
+	if rawListIDs, exists := event.Data["list_ids"]; exists {
+		listBytes, err := json.Marshal(rawListIDs)
+		if err == nil {
+			err = json.Unmarshal(listBytes, &listIDs)
+		}
+		if err != nil {
+			return dlq.Permanent(fmt.Errorf("invalid list_ids: %w", err))
+		}
+	}
+
+	deletionData, err := tc.taskRepo.RemoveTeamTasks(ctx, listIDs, func(tx *gorm.DB, data *events.TeamTasksDeletionData) error {
+		if _, err := tc.inbox.MarkProcessedTx(ctx, tx, event.ID); err != nil {
+			return err
+		}
+
+		return tc.outbox.EnqueueTx(ctx, tx, events.TaskEventsTopic, tc.reply(event, events.TaskTeamDeleted, map[string]interface{}{
+			"team_id":             teamID,
+			"tasks_deleted_count": len(data.TaskIDs),
+			"rollback_data":       data,
+		}))
+	})
+	if err != nil {
+		tc.logger.Error("Failed to remove team tasks",
+			"team_id", teamID,
+			"saga_id", event.SagaID,
+			"error", err)
+
+		return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskTeamDeleteFailed, map[string]interface{}{
+			"team_id": teamID,
+			"error":   err.Error(),
+		}))
+	}
+
+	tc.logger.Info("Removed team tasks",
+		"team_id", teamID,
+		"saga_id", event.SagaID,
+		"lists_count", len(listIDs),
+		"tasks_count", len(deletionData.TaskIDs))
+
+	return nil
+}
+
+func (tc *TaskConsumer) handleTaskTeamDeleteRollback(ctx context.Context, event events.Event) error {
+	teamID, _ := event.Data["team_id"].(string)
+
+	var deletionData events.TeamTasksDeletionData
+	if rollbackData, exists := event.Data["rollback_data"]; exists {
+		rollbackBytes, err := json.Marshal(rollbackData)
+		if err == nil {
+			err = json.Unmarshal(rollbackBytes, &deletionData)
+		}
+		if err != nil {
+			return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskTeamDeleteRollbackFailed, map[string]interface{}{
+				"team_id": teamID,
+				"error":   fmt.Sprintf("invalid rollback_data: %v", err),
+			}))
+		}
+	}
+
+	if len(deletionData.TaskIDs) == 0 {
+		tc.logger.Warn("Rollback event has no team tasks to restore",
+			"team_id", teamID,
+			"saga_id", event.SagaID)
+		return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskTeamDeleteRollbackCompleted, map[string]interface{}{
+			"team_id": teamID,
+		}))
+	}
+
+	if err := tc.taskRepo.RestoreTeamTasks(ctx, &deletionData); err != nil {
+		tc.logger.Error("Failed to restore team tasks",
+			"team_id", teamID,
+			"saga_id", event.SagaID,
+			"error", err)
+		return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskTeamDeleteRollbackFailed, map[string]interface{}{
+			"team_id": teamID,
+			"error":   err.Error(),
+		}))
+	}
+
+	tc.logger.Info("Restored team tasks",
+		"team_id", teamID,
+		"saga_id", event.SagaID,
+		"tasks_count", len(deletionData.TaskIDs))
+
+	return tc.enqueueReply(ctx, event, tc.reply(event, events.TaskTeamDeleteRollbackCompleted, map[string]interface{}{
+		"team_id": teamID,
+	}))
+}
+
+// enqueueReply пишет ответ, не сопровождающий изменения данных, в одной
+// транзакции с отметкой inbox: повторная доставка команды не отправит
+// оркестратору второй ответ.
+func (tc *TaskConsumer) enqueueReply(ctx context.Context, event events.Event, reply events.Event) error {
+	return tc.outbox.InTx(ctx, func(tx *gorm.DB) error {
+		fresh, err := tc.inbox.MarkProcessedTx(ctx, tx, event.ID)
+		if err != nil || !fresh {
+			return err
+		}
+		return tc.outbox.EnqueueTx(ctx, tx, events.TaskEventsTopic, reply)
+	})
+}
+
+func (tc *TaskConsumer) reply(originalEvent events.Event, eventType events.EventType, data map[string]interface{}) events.Event {
+	return events.New(eventType, originalEvent.UserID, originalEvent.SagaID, data)
+}
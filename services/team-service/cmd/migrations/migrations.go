@@ -12,7 +12,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 
-	"taskservice/internal/config"
+	"teamservice/internal/config"
 )
 
 const (
@@ -7,9 +7,9 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
-	"taskservice/internal/app"
-	"taskservice/internal/config"
-	"taskservice/internal/infrastructure/database"
+	"teamservice/internal/app"
+	"teamservice/internal/config"
+	"teamservice/internal/infrastructure/database"
 	"time"
 )
 
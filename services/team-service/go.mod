@@ -1,4 +1,4 @@
-module taskservice
+module teamservice
 
 go 1.24.2
 
@@ -7,6 +7,7 @@ toolchain go1.24.4
 require (
 	github.com/IBM/sarama v1.45.2
 	github.com/cms-crs/protos v0.0.9
+	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/golang-migrate/migrate/v4 v4.18.3
 	github.com/golang/protobuf v1.5.4
 	github.com/google/uuid v1.6.0
@@ -14,6 +15,9 @@ require (
 	github.com/lib/pq v1.10.9
 	google.golang.org/grpc v1.73.0
 	google.golang.org/protobuf v1.36.6
+	shiroyama/authz v0.0.0
+	shiroyama/events v0.0.0
+	shiroyama/messaging v0.0.0
 )
 
 require (
@@ -36,6 +40,7 @@ require (
 	github.com/pierrec/lz4/v4 v4.1.22 // indirect
 	github.com/rcrowley/go-metrics v0.0.0-20201227073835-cf1acfcdf475 // indirect
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2 // indirect
 	go.uber.org/atomic v1.11.0 // indirect
 	golang.org/x/crypto v0.39.0 // indirect
 	golang.org/x/net v0.41.0 // indirect
@@ -45,3 +50,9 @@ require (
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3 // indirect
 )
+
+replace shiroyama/events => ../events
+
+replace shiroyama/messaging => ../messaging
+
+replace shiroyama/authz => ../authz
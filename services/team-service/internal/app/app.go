@@ -4,12 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"log/slog"
-	grpcapp "taskservice/internal/app/grpc"
-	"taskservice/internal/clients"
-	"taskservice/internal/config"
-	"taskservice/internal/kafka"
-	teamRepo "taskservice/internal/repository/team"
-	teamService "taskservice/internal/service/team"
+	"shiroyama/events"
+	grpcapp "teamservice/internal/app/grpc"
+	"teamservice/internal/clients"
+	"teamservice/internal/config"
+	teamRepo "teamservice/internal/repository/team"
+	teamService "teamservice/internal/service/team"
+	"teamservice/kafka"
 )
 
 type App struct {
@@ -42,8 +43,12 @@ func New(
 
 	kafkaConsumer, err := kafka.NewConsumer(kafka.ConsumerConfig{
 		Brokers:     cfg.Kafka.Brokers,
-		GroupID:     "team-service-group",
+		GroupID:     cfg.Kafka.GroupID,
 		TeamService: teamSvc,
+		DB:          db,
+		Inbox:       cfg.Kafka.Inbox,
+		DeadLetter:  cfg.Kafka.DeadLetter,
+		Outbox:      cfg.Kafka.Outbox,
 		Logger:      logger,
 	})
 	if err != nil {
@@ -63,7 +68,7 @@ func New(
 }
 
 func (app *App) StartKafkaConsumer(ctx context.Context) error {
-	topics := []string{"team-service-commands"}
+	topics := []string{events.TeamCommandsTopic}
 
 	app.logger.Info("Starting Kafka consumer", "topics", topics)
 	return app.KafkaConsumer.Start(ctx, topics)
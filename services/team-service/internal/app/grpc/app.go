@@ -6,12 +6,13 @@ import (
 	"google.golang.org/grpc"
 	"log/slog"
 	"net"
-	"taskservice/internal/clients"
-	"taskservice/internal/config"
-	"taskservice/internal/handler"
-	"taskservice/internal/kafka"
-	teamRepo "taskservice/internal/repository/team"
-	teamService "taskservice/internal/service/team"
+	"shiroyama/authz"
+	"teamservice/internal/clients"
+	"teamservice/internal/config"
+	"teamservice/internal/handler"
+	teamRepo "teamservice/internal/repository/team"
+	teamService "teamservice/internal/service/team"
+	"teamservice/kafka"
 )
 
 type App struct {
@@ -23,7 +24,7 @@ type App struct {
 }
 
 func New(log *slog.Logger, port int, db *sql.DB, cfg *config.Config, kafkaProducer *kafka.Producer) *App {
-	gRPCServer := grpc.NewServer()
+	gRPCServer := grpc.NewServer(grpc.UnaryInterceptor(authz.UnaryServerInterceptor(authz.NewJWKS(cfg.JWT.JWKSURL, cfg.JWT.JWKSCacheTTL))))
 
 	userClient, err := clients.NewUserClient(cfg.UserService.Address)
 	if err != nil {
@@ -34,7 +35,10 @@ func New(log *slog.Logger, port int, db *sql.DB, cfg *config.Config, kafkaProduc
 	repository := teamRepo.NewTeamRepository(log, db)
 	service := teamService.NewTeamService(log, repository, userClient)
 
-	handler.Register(gRPCServer, log, service, kafkaProducer)
+	// Relay для этого outbox запускает Kafka consumer
+	outbox := kafka.NewOutbox(db, "team-service", kafkaProducer, cfg.Kafka.Outbox, log)
+
+	handler.Register(gRPCServer, log, service, outbox)
 
 	return &App{
 		log:           log,
@@ -12,6 +12,7 @@ type Config struct {
 	DB          DatabaseConfig `yaml:"db"`
 	Kafka       KafkaConfig    `yaml:"kafka"`
 	UserService UserService    `yaml:"userService"`
+	JWT         JWTConfig      `yaml:"jwt"`
 }
 
 func MustLoad() *Config {
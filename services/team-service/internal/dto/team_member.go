@@ -1,7 +1,7 @@
 package dto
 
 import (
-	"taskservice/internal/entity"
+	"teamservice/internal/entity"
 	"time"
 )
 
@@ -8,4 +8,6 @@ type Team struct {
 	Description string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	// Role заполняется только в выборках по участнику команды
+	Role string
 }
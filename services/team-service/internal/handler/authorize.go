@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"shiroyama/authz"
+)
+
+// authorize проверяет право вызывающего в команде по его роли в
+// team_members. Шлюз проверяет то же самое, но сервис не должен
+// полагаться на то, что вызов пришёл через шлюз.
+func (handler *GrpcHandler) authorize(ctx context.Context, teamID string, permission authz.Permission) error {
+	const op = "gRPC.authorize"
+
+	userID, ok := authz.CallerFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	role, err := handler.teamService.GetMemberRole(ctx, teamID, userID)
+	if err != nil {
+		handler.log.Error("Failed to get member role", "team_id", teamID, "user_id", userID, "error", err, "op", op)
+		return status.Error(codes.Internal, "failed to check permissions")
+	}
+
+	if !authz.Role(role).Can(permission) {
+		handler.log.Info("Permission denied",
+			"team_id", teamID,
+			"user_id", userID,
+			"role", role,
+			"permission", permission,
+			"op", op)
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	teamv1 "github.com/cms-crs/protos/gen/go/team_service"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -11,8 +12,10 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"log/slog"
-	"taskservice/internal/dto"
-	"taskservice/internal/kafka"
+	"shiroyama/authz"
+	"shiroyama/events"
+	"teamservice/internal/dto"
+	"teamservice/kafka"
 )
 
 type TeamService interface {
@@ -20,10 +23,6 @@ type TeamService interface {
 		ctx context.Context,
 		req *dto.CreateTeamRequest,
 	) (*dto.CreateTeamResponse, error)
-	DeleteTeam(
-		ctx context.Context,
-		ID string,
-	) error
 	GetTeam(
 		ctx context.Context,
 		ID string,
@@ -52,26 +51,41 @@ type TeamService interface {
 		ctx context.Context,
 		ID string,
 	) ([]*dto.TeamMember, error)
+	GetMemberRole(
+		ctx context.Context,
+		teamID string,
+		userID string,
+	) (string, error)
 }
 
 type GrpcHandler struct {
 	teamv1.UnimplementedTeamServiceServer
-	log           *slog.Logger
-	teamService   TeamService
-	kafkaProducer *kafka.Producer
+	log         *slog.Logger
+	teamService TeamService
+	outbox      *kafka.Outbox
 }
 
-func Register(gRPC *grpc.Server, log *slog.Logger, teamService TeamService, kafkaProducer *kafka.Producer) {
+func Register(gRPC *grpc.Server, log *slog.Logger, teamService TeamService, outbox *kafka.Outbox) {
 	teamv1.RegisterTeamServiceServer(gRPC, &GrpcHandler{
-		log:           log,
-		teamService:   teamService,
-		kafkaProducer: kafkaProducer,
+		log:         log,
+		teamService: teamService,
+		outbox:      outbox,
 	})
 }
 
 func (handler *GrpcHandler) CreateTeam(ctx context.Context, request *teamv1.CreateTeamRequest) (*teamv1.Team, error) {
 	const op = "gRPC.CreateTeam"
 
+	// Создатель становится администратором, поэтому создавать команду можно
+	// только от своего имени
+	userID, ok := authz.CallerFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if userID != request.CreatedBy {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
 	createTeamRequest := &dto.CreateTeamRequest{
 		Name:        request.Name,
 		Description: request.Description,
@@ -96,17 +110,39 @@ func (handler *GrpcHandler) CreateTeam(ctx context.Context, request *teamv1.Crea
 func (handler *GrpcHandler) DeleteTeam(ctx context.Context, request *teamv1.DeleteTeamRequest) (*empty.Empty, error) {
 	const op = "gRPC.DeleteTeam"
 
-	err := handler.teamService.DeleteTeam(ctx, request.Id)
-	if err != nil {
+	if err := handler.authorize(ctx, request.Id, authz.PermissionManageTeam); err != nil {
+		return nil, err
+	}
+
+	if _, err := handler.teamService.GetTeam(ctx, request.Id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "team not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get team")
+	}
+
+	// Команду, её доски и задачи удаляет сага team_deletion в оркестраторе
+	event := events.New(events.TeamDeletionRequested, "", "", map[string]interface{}{
+		"team_id":      request.Id,
+		"initiated_by": "grpc_request",
+	})
+
+	if err := handler.outbox.Enqueue(ctx, events.TeamDeletionSagaTopic, event); err != nil {
+		handler.log.Error("Failed to initiate team deletion", "team_id", request.Id, "error", err, "op", op)
 		return nil, status.Error(codes.Internal, "failed to delete team")
 	}
 
+	handler.log.Info("Team deletion saga initiated", "team_id", request.Id, "op", op)
 	return &empty.Empty{}, nil
 }
 
 func (handler *GrpcHandler) GetTeam(ctx context.Context, request *teamv1.GetTeamRequest) (*teamv1.Team, error) {
 	const op = "gRPC.GetTeam"
 
+	if err := handler.authorize(ctx, request.Id, authz.PermissionRead); err != nil {
+		return nil, err
+	}
+
 	getTeamResponse, err := handler.teamService.GetTeam(ctx, request.Id)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to get team")
@@ -124,6 +160,10 @@ func (handler *GrpcHandler) GetTeam(ctx context.Context, request *teamv1.GetTeam
 func (handler *GrpcHandler) UpdateTeam(ctx context.Context, request *teamv1.UpdateTeamRequest) (*teamv1.Team, error) {
 	const op = "gRPC.UpdateTeam"
 
+	if err := handler.authorize(ctx, request.Id, authz.PermissionManageTeam); err != nil {
+		return nil, err
+	}
+
 	updateTeamRequest := &dto.UpdateTeamRequest{
 		ID:          request.Id,
 		Name:        request.Name,
@@ -148,6 +188,10 @@ func (handler *GrpcHandler) UpdateTeam(ctx context.Context, request *teamv1.Upda
 func (handler *GrpcHandler) AddUserToTeam(ctx context.Context, request *teamv1.AddUserToTeamRequest) (*teamv1.TeamMember, error) {
 	const op = "gRPC.AddUserToTeam"
 
+	if err := handler.authorize(ctx, request.TeamId, authz.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
 	addUserToTeamRequest := &dto.AddUserToTeamRequest{
 		UserID: request.UserId,
 		TeamID: request.TeamId,
@@ -173,6 +217,10 @@ func (handler *GrpcHandler) AddUserToTeam(ctx context.Context, request *teamv1.A
 }
 
 func (handler *GrpcHandler) GetUserTeams(ctx context.Context, request *teamv1.GetUserTeamsRequest) (*teamv1.GetUserTeamsResponse, error) {
+	if _, ok := authz.CallerFromContext(ctx); !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
 	getTeamResponses, err := handler.teamService.GetUserTeams(ctx, request.UserId)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to get user teams")
@@ -188,6 +236,7 @@ func (handler *GrpcHandler) GetUserTeams(ctx context.Context, request *teamv1.Ge
 				CreatedAt:   timestamppb.New(team.CreatedAt),
 				UpdatedAt:   timestamppb.New(team.UpdatedAt),
 			},
+			Role: team.Role,
 		})
 	}
 
@@ -199,6 +248,10 @@ func (handler *GrpcHandler) GetUserTeams(ctx context.Context, request *teamv1.Ge
 func (handler *GrpcHandler) RemoveUserFromTeam(ctx context.Context, request *teamv1.RemoveUserFromTeamRequest) (*empty.Empty, error) {
 	const op = "gRPC.RemoveUserFromTeam"
 
+	if err := handler.authorize(ctx, request.TeamId, authz.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
 	removeUserFromTeamRequest := &dto.RemoveUserFromTeamRequest{
 		TeamID: request.TeamId,
 		UserID: request.UserId,
@@ -212,6 +265,10 @@ func (handler *GrpcHandler) RemoveUserFromTeam(ctx context.Context, request *tea
 }
 
 func (handler *GrpcHandler) UpdateUserRole(ctx context.Context, request *teamv1.UpdateUserRoleRequest) (*teamv1.TeamMember, error) {
+	if err := handler.authorize(ctx, request.TeamId, authz.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+
 	updateUserRoleRequest := &dto.UpdateUserRoleRequest{
 		TeamID: request.TeamId,
 		UserID: request.UserId,
@@ -232,6 +289,10 @@ func (handler *GrpcHandler) UpdateUserRole(ctx context.Context, request *teamv1.
 }
 
 func (handler *GrpcHandler) GetTeamMembers(ctx context.Context, request *teamv1.GetTeamMembersRequest) (*teamv1.GetTeamMembersResponse, error) {
+	if err := handler.authorize(ctx, request.TeamId, authz.PermissionRead); err != nil {
+		return nil, err
+	}
+
 	teamMembers, err := handler.teamService.GetTeamMembers(ctx, request.TeamId)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to get team members")
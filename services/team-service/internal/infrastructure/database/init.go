@@ -2,7 +2,7 @@ package database
 
 import (
 	"database/sql"
-	"taskservice/internal/config"
+	"teamservice/internal/config"
 )
 
 func MustLoad(cfg *config.Config) *sql.DB {
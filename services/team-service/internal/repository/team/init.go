@@ -6,8 +6,9 @@ import (
 	"fmt"
 	"github.com/lib/pq"
 	"log/slog"
+	"shiroyama/events"
 	"strings"
-	"taskservice/internal/kafka"
+	"teamservice/kafka"
 	"time"
 )
 
@@ -20,7 +21,7 @@ func NewTeamRepository(log *slog.Logger, db *sql.DB) *Repository {
 	return &Repository{log: log, db: db}
 }
 
-func (r *Repository) DeleteUserFromAllTeams(ctx context.Context, userID string) (*kafka.TeamDeletionData, error) {
+func (r *Repository) DeleteUserFromAllTeams(ctx context.Context, userID string, beforeCommit kafka.BeforeCommit) (*events.TeamDeletionData, error) {
 	const op = "TeamRepository.DeleteUserFromAllTeams"
 
 	r.log.Info("Deleting user from all teams", "user_id", userID, "op", op)
@@ -54,9 +55,9 @@ func (r *Repository) DeleteUserFromAllTeams(ctx context.Context, userID string)
 	}
 	defer rows.Close()
 
-	var memberships []kafka.TeamMembershipData
+	var memberships []events.TeamMembershipData
 	for rows.Next() {
-		var membership kafka.TeamMembershipData
+		var membership events.TeamMembershipData
 		var joinedAt time.Time
 
 		if err := rows.Scan(&membership.TeamID, &membership.Role, &joinedAt); err != nil {
@@ -73,7 +74,7 @@ func (r *Repository) DeleteUserFromAllTeams(ctx context.Context, userID string)
 		return nil, fmt.Errorf("%s: error iterating team memberships: %w", op, err)
 	}
 
-	deletionData := &kafka.TeamDeletionData{
+	deletionData := &events.TeamDeletionData{
 		Teams: memberships,
 	}
 
@@ -86,6 +87,13 @@ func (r *Repository) DeleteUserFromAllTeams(ctx context.Context, userID string)
 
 	rowsAffected, _ := result.RowsAffected()
 
+	if beforeCommit != nil {
+		if err := beforeCommit(ctx, tx, deletionData); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("%s: before commit hook failed: %w", op, err)
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
 	}
@@ -99,7 +107,7 @@ func (r *Repository) DeleteUserFromAllTeams(ctx context.Context, userID string)
 	return deletionData, nil
 }
 
-func (r *Repository) RestoreUserTeams(ctx context.Context, userID string, data *kafka.TeamDeletionData) error {
+func (r *Repository) RestoreUserTeams(ctx context.Context, userID string, data *events.TeamDeletionData) error {
 	const op = "TeamRepository.RestoreUserTeams"
 
 	r.log.Info("Restoring user teams", "user_id", userID, "teams_count", len(data.Teams), "op", op)
@@ -189,7 +197,7 @@ func (r *Repository) RestoreUserTeams(ctx context.Context, userID string, data *
 	return nil
 }
 
-func (r *Repository) GetUserTeamMemberships(ctx context.Context, userID string) (*kafka.TeamDeletionData, error) {
+func (r *Repository) GetUserTeamMemberships(ctx context.Context, userID string) (*events.TeamDeletionData, error) {
 	const op = "TeamRepository.GetUserTeamMemberships"
 
 	r.log.Info("Getting user team memberships", "user_id", userID, "op", op)
@@ -206,9 +214,9 @@ func (r *Repository) GetUserTeamMemberships(ctx context.Context, userID string)
 	}
 	defer rows.Close()
 
-	var memberships []kafka.TeamMembershipData
+	var memberships []events.TeamMembershipData
 	for rows.Next() {
-		var membership kafka.TeamMembershipData
+		var membership events.TeamMembershipData
 		var joinedAt time.Time
 
 		if err := rows.Scan(&membership.TeamID, &membership.Role, &joinedAt); err != nil {
@@ -223,7 +231,7 @@ func (r *Repository) GetUserTeamMemberships(ctx context.Context, userID string)
 		return nil, fmt.Errorf("%s: error iterating team memberships: %w", op, err)
 	}
 
-	deletionData := &kafka.TeamDeletionData{
+	deletionData := &events.TeamDeletionData{
 		Teams: memberships,
 	}
 
@@ -3,7 +3,7 @@ package team
 import (
 	"context"
 	"fmt"
-	"taskservice/internal/entity"
+	"teamservice/internal/entity"
 )
 
 func (r *Repository) AddUserToTeam(ctx context.Context, req *entity.TeamMember) error {
@@ -2,7 +2,7 @@ package team
 
 import (
 	"context"
-	"taskservice/internal/entity"
+	"teamservice/internal/entity"
 )
 
 func (r *Repository) CreateTeam(ctx context.Context, team *entity.Team) (*entity.Team, error) {
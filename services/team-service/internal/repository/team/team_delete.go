@@ -2,29 +2,70 @@ package team
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"shiroyama/events"
+	"teamservice/kafka"
 )
 
-func (r *Repository) DeleteTeam(ctx context.Context, ID string) error {
+// DeleteTeam помечает команду удалённой в саге удаления команды. Повторная
+// команда той же саги не ошибка и снова сообщает, что пометку поставила сага;
+// команда, удалённая раньше, остаётся удалённой и после отката.
+func (r *Repository) DeleteTeam(ctx context.Context, ID, sagaID string, beforeCommit kafka.TeamDeleteHook) error {
 	const op = "TeamRepository.DeleteTeam"
 
-	query := `DELETE FROM teams WHERE id = $1`
-
-	result, err := r.db.ExecContext(ctx, query, ID)
-
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		r.log.Error(op, err.Error())
-		return err
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
 	}
+	defer tx.Rollback()
 
-	affected, err := result.RowsAffected()
+	var deletedAt sql.NullTime
+	var deletedBySaga sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT deleted_at, deleted_by_saga FROM teams WHERE id = $1 FOR UPDATE`, ID).
+		Scan(&deletedAt, &deletedBySaga)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: team %s not found", op, ID)
+	}
 	if err != nil {
-		r.log.Error(op, err.Error())
-		return err
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	data := &events.DeletedTeamData{
+		WasDeletedBySaga: !deletedAt.Valid || (deletedBySaga.Valid && deletedBySaga.String == sagaID),
+	}
+
+	if !deletedAt.Valid {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE teams SET deleted_at = NOW(), deleted_by_saga = $2, updated_at = NOW() WHERE id = $1`,
+			ID, sagaID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if beforeCommit != nil {
+		if err := beforeCommit(ctx, tx, data); err != nil {
+			return fmt.Errorf("%s: before commit hook failed: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
 	}
 
-	if affected == 0 {
-		r.log.Error(op, "Team not found")
-		return err
+	return nil
+}
+
+// RestoreTeam снимает пометку удаления при компенсации саги.
+func (r *Repository) RestoreTeam(ctx context.Context, ID string) error {
+	const op = "TeamRepository.RestoreTeam"
+
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE teams SET deleted_at = NULL, deleted_by_saga = NULL, updated_at = NOW() WHERE id = $1`, ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
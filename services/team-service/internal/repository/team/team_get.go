@@ -2,7 +2,7 @@ package team
 
 import (
 	"context"
-	"taskservice/internal/entity"
+	"teamservice/internal/entity"
 )
 
 func (r *Repository) GetTeam(ctx context.Context, ID string) (*entity.Team, error) {
@@ -10,7 +10,7 @@ func (r *Repository) GetTeam(ctx context.Context, ID string) (*entity.Team, erro
 
 	query := `
 		SELECT id, name, description, created_at, updated_at 
-		FROM teams WHERE id = $1
+		FROM teams WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var team entity.Team
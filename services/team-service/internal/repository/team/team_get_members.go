@@ -3,7 +3,7 @@ package team
 import (
 	"context"
 	"fmt"
-	"taskservice/internal/entity"
+	"teamservice/internal/entity"
 )
 
 func (r *Repository) GetTeamMembers(ctx context.Context, ID string) ([]*entity.TeamMember, error) {
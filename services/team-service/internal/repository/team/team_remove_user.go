@@ -3,7 +3,7 @@ package team
 import (
 	"context"
 	"fmt"
-	"taskservice/internal/dto"
+	"teamservice/internal/dto"
 )
 
 func (r *Repository) RemoveUserFromTeam(ctx context.Context, req *dto.RemoveUserFromTeamRequest) error {
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"taskservice/internal/entity"
+	"teamservice/internal/entity"
 )
 
 func (r *Repository) UpdateTeam(ctx context.Context, team *entity.Team) (*entity.Team, error) {
@@ -33,7 +33,7 @@ func (r *Repository) UpdateTeam(ctx context.Context, team *entity.Team) (*entity
 	query := fmt.Sprintf(`
 		UPDATE teams
 		SET %s
-		WHERE id = $%d
+		WHERE id = $%d AND deleted_at IS NULL
 		RETURNING id, name, description, created_at, updated_at
 	`, strings.Join(setClauses, ", "), argIdx)
 
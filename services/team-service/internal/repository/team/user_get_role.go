@@ -0,0 +1,32 @@
+package team
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// GetMemberRole возвращает роль пользователя в команде или пустую строку,
+// если пользователь в ней не состоит либо команда удалена.
+func (r *Repository) GetMemberRole(ctx context.Context, teamID, userID string) (string, error) {
+	const op = "TeamRepository.GetMemberRole"
+
+	query := `
+		SELECT tm.role
+		FROM team_members as tm
+		JOIN teams as t ON tm.team_id = t.id
+		WHERE tm.team_id = $1 AND tm.user_id = $2 AND t.deleted_at IS NULL
+	`
+
+	var role string
+	err := r.db.QueryRowContext(ctx, query, teamID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return role, nil
+}
@@ -3,17 +3,17 @@ package team
 import (
 	"context"
 	"database/sql"
-	"taskservice/internal/entity"
+	"teamservice/internal/entity"
 )
 
 func (r *Repository) GetUserTeams(ctx context.Context, UserID string) ([]*entity.Team, error) {
 	const op = "TeamRepository.GetUserTeams"
 
 	query := `
-		SELECT t.id, t.name, t.description, t.created_at, t.updated_at
+		SELECT t.id, t.name, t.description, t.created_at, t.updated_at, tm.role
 		FROM team_members as tm
 		JOIN teams as t ON tm.team_id = t.id
-		WHERE tm.user_id = $1
+		WHERE tm.user_id = $1 AND t.deleted_at IS NULL
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, UserID)
@@ -38,6 +38,7 @@ func (r *Repository) GetUserTeams(ctx context.Context, UserID string) ([]*entity
 			&team.Description,
 			&team.CreatedAt,
 			&team.UpdatedAt,
+			&team.Role,
 		)
 
 		if err != nil {
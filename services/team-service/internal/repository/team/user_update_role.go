@@ -3,7 +3,7 @@ package team
 import (
 	"context"
 	"fmt"
-	"taskservice/internal/dto"
+	"teamservice/internal/dto"
 )
 
 func (r *Repository) UpdateUserRole(ctx context.Context, req *dto.UpdateUserRoleRequest) error {
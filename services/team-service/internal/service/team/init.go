@@ -3,10 +3,11 @@ package team
 import (
 	"context"
 	"log/slog"
-	"taskservice/internal/clients"
-	"taskservice/internal/dto"
-	"taskservice/internal/entity"
-	"taskservice/internal/kafka"
+	"shiroyama/events"
+	"teamservice/internal/clients"
+	"teamservice/internal/dto"
+	"teamservice/internal/entity"
+	"teamservice/kafka"
 )
 
 type Repository interface {
@@ -17,6 +18,12 @@ type Repository interface {
 	DeleteTeam(
 		ctx context.Context,
 		ID string,
+		sagaID string,
+		beforeCommit kafka.TeamDeleteHook,
+	) error
+	RestoreTeam(
+		ctx context.Context,
+		ID string,
 	) error
 	GetTeam(
 		ctx context.Context,
@@ -46,19 +53,25 @@ type Repository interface {
 		ctx context.Context,
 		ID string,
 	) ([]*entity.TeamMember, error)
+	GetMemberRole(
+		ctx context.Context,
+		teamID string,
+		userID string,
+	) (string, error)
 	DeleteUserFromAllTeams(
 		ctx context.Context,
 		userID string,
-	) (*kafka.TeamDeletionData, error)
+		beforeCommit kafka.BeforeCommit,
+	) (*events.TeamDeletionData, error)
 	RestoreUserTeams(
 		ctx context.Context,
 		userID string,
-		data *kafka.TeamDeletionData,
+		data *events.TeamDeletionData,
 	) error
 	GetUserTeamMemberships(
 		ctx context.Context,
 		userID string,
-	) (*kafka.TeamDeletionData, error)
+	) (*events.TeamDeletionData, error)
 }
 
 type Service struct {
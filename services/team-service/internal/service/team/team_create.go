@@ -3,8 +3,8 @@ package team
 import (
 	"context"
 	"fmt"
-	"taskservice/internal/dto"
-	"taskservice/internal/entity"
+	"teamservice/internal/dto"
+	"teamservice/internal/entity"
 )
 
 func (service *Service) CreateTeam(
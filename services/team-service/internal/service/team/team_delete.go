@@ -1,15 +1,38 @@
 package team
 
-import "context"
+import (
+	"context"
+	"teamservice/kafka"
+)
 
 func (service *Service) DeleteTeam(
 	ctx context.Context,
 	ID string,
+	sagaID string,
+	beforeCommit kafka.TeamDeleteHook,
 ) error {
-	err := service.teamRepository.DeleteTeam(ctx, ID)
-	if err != nil {
+	const op = "TeamService.DeleteTeam"
+
+	if err := service.teamRepository.DeleteTeam(ctx, ID, sagaID, beforeCommit); err != nil {
+		service.log.Error("Failed to delete team", "team_id", ID, "error", err, "op", op)
+		return err
+	}
+
+	service.log.Info("Team marked as deleted", "team_id", ID, "saga_id", sagaID, "op", op)
+	return nil
+}
+
+func (service *Service) RestoreTeam(
+	ctx context.Context,
+	ID string,
+) error {
+	const op = "TeamService.RestoreTeam"
+
+	if err := service.teamRepository.RestoreTeam(ctx, ID); err != nil {
+		service.log.Error("Failed to restore team", "team_id", ID, "error", err, "op", op)
 		return err
 	}
 
+	service.log.Info("Team restored", "team_id", ID, "op", op)
 	return nil
 }
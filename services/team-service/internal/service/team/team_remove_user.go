@@ -2,7 +2,7 @@ package team
 
 import (
 	"context"
-	"taskservice/internal/dto"
+	"teamservice/internal/dto"
 )
 
 func (service *Service) RemoveUserFromTeam(
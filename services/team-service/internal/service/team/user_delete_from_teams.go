@@ -2,15 +2,16 @@ package team
 
 import (
 	"context"
-	"taskservice/internal/kafka"
+	"shiroyama/events"
+	"teamservice/kafka"
 )
 
-func (service *Service) DeleteUserFromAllTeams(ctx context.Context, userID string) (*kafka.TeamDeletionData, error) {
+func (service *Service) DeleteUserFromAllTeams(ctx context.Context, userID string, beforeCommit kafka.BeforeCommit) (*events.TeamDeletionData, error) {
 	const op = "TeamService.DeleteUserFromAllTeams"
 
 	service.log.Info("Deleting user from all teams", "user_id", userID, "op", op)
 
-	deletionData, err := service.teamRepository.DeleteUserFromAllTeams(ctx, userID)
+	deletionData, err := service.teamRepository.DeleteUserFromAllTeams(ctx, userID, beforeCommit)
 	if err != nil {
 		service.log.Error("Failed to delete user from all teams", "user_id", userID, "error", err, "op", op)
 		return nil, err
@@ -0,0 +1,11 @@
+package team
+
+import "context"
+
+func (service *Service) GetMemberRole(
+	ctx context.Context,
+	teamID string,
+	userID string,
+) (string, error) {
+	return service.teamRepository.GetMemberRole(ctx, teamID, userID)
+}
@@ -2,7 +2,7 @@ package team
 
 import (
 	"context"
-	"taskservice/internal/dto"
+	"teamservice/internal/dto"
 )
 
 func (service *Service) GetUserTeams(
@@ -22,6 +22,7 @@ func (service *Service) GetUserTeams(
 			Description: userTeam.Description,
 			CreatedAt:   userTeam.CreatedAt,
 			UpdatedAt:   userTeam.UpdatedAt,
+			Role:        userTeam.Role,
 		})
 	}
 
@@ -2,10 +2,10 @@ package team
 
 import (
 	"context"
-	"taskservice/internal/kafka"
+	"shiroyama/events"
 )
 
-func (service *Service) GetUserTeamMemberships(ctx context.Context, userID string) (*kafka.TeamDeletionData, error) {
+func (service *Service) GetUserTeamMemberships(ctx context.Context, userID string) (*events.TeamDeletionData, error) {
 	const op = "TeamService.GetUserTeamMemberships"
 
 	service.log.Debug("Getting user team memberships", "user_id", userID, "op", op)
@@ -2,10 +2,10 @@ package team
 
 import (
 	"context"
-	"taskservice/internal/kafka"
+	"shiroyama/events"
 )
 
-func (service *Service) RestoreUserTeams(ctx context.Context, userID string, data *kafka.TeamDeletionData) error {
+func (service *Service) RestoreUserTeams(ctx context.Context, userID string, data *events.TeamDeletionData) error {
 	const op = "TeamService.RestoreUserTeams"
 
 	service.log.Info("Restoring user teams", "user_id", userID, "teams_count", len(data.Teams), "op", op)
@@ -3,7 +3,7 @@ package team
 import (
 	"context"
 	"fmt"
-	"taskservice/internal/dto"
+	"teamservice/internal/dto"
 )
 
 func (service *Service) UpdateUserRole(
@@ -0,0 +1,14 @@
+package kafka
+
+import (
+	"context"
+	"database/sql"
+	"shiroyama/events"
+)
+
+// BeforeCommit вызывается внутри транзакции удаления, чтобы ответ саге
+// попал в outbox атомарно с изменениями.
+type BeforeCommit func(ctx context.Context, tx *sql.Tx, data *events.TeamDeletionData) error
+
+// TeamDeleteHook — то же для пометки команды удалённой.
+type TeamDeleteHook func(ctx context.Context, tx *sql.Tx, data *events.DeletedTeamData) error
@@ -0,0 +1,22 @@
+package kafka
+
+import (
+	"database/sql"
+	"log/slog"
+	"shiroyama/messaging/outbox"
+	"shiroyama/messaging/sqlstore"
+	"teamservice/internal/config"
+)
+
+// Outbox сохраняет исходящие события в таблицу outbox_events в той же транзакции,
+// что и изменения данных, а relay публикует их в Kafka.
+type Outbox = outbox.Outbox[*sql.Tx]
+
+func NewOutbox(db *sql.DB, source string, producer *Producer, cfg config.OutboxConfig, logger *slog.Logger) *Outbox {
+	return outbox.New(sqlstore.NewOutbox(db), source, producer, outbox.Config{
+		PollInterval:    cfg.PollInterval,
+		BatchSize:       cfg.BatchSize,
+		Retention:       cfg.Retention,
+		CleanupInterval: cfg.CleanupInterval,
+	}, logger)
+}
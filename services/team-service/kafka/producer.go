@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+	"shiroyama/events"
+)
+
+type Producer struct {
+	producer sarama.SyncProducer
+}
+
+func NewKafkaProducer(brokers []string) (*Producer, error) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Compression = sarama.CompressionSnappy
+	config.Producer.Partitioner = sarama.NewRoundRobinPartitioner
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Producer{producer: producer}, nil
+}
+
+func (p *Producer) PublishEvent(topic string, event events.Event) error {
+	eventBytes, err := events.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.StringEncoder(event.Key()),
+		Value: sarama.ByteEncoder(eventBytes),
+		Headers: []sarama.RecordHeader{
+			{
+				Key:   []byte("event_type"),
+				Value: []byte(event.Type),
+			},
+			{
+				Key:   []byte("saga_id"),
+				Value: []byte(event.SagaID),
+			},
+		},
+	}
+
+	for key, value := range event.Headers {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{
+			Key:   []byte(key),
+			Value: []byte(value),
+		})
+	}
+
+	_, _, err = p.producer.SendMessage(msg)
+	return err
+}
+
+// SendMessage отправляет готовое сообщение как есть — нужно для DLQ,
+// где значение и заголовки не должны меняться.
+func (p *Producer) SendMessage(msg *sarama.ProducerMessage) error {
+	_, _, err := p.producer.SendMessage(msg)
+	return err
+}
+
+func (p *Producer) Close() error {
+	return p.producer.Close()
+}
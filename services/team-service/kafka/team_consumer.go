@@ -0,0 +1,327 @@
+package kafka
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"shiroyama/events"
+)
+
+type TeamService interface {
+	DeleteUserFromAllTeams(ctx context.Context, userID string, beforeCommit BeforeCommit) (*events.TeamDeletionData, error)
+
+	RestoreUserTeams(ctx context.Context, userID string, data *events.TeamDeletionData) error
+
+	GetUserTeamMemberships(ctx context.Context, userID string) (*events.TeamDeletionData, error)
+
+	DeleteTeam(ctx context.Context, ID, sagaID string, beforeCommit TeamDeleteHook) error
+
+	RestoreTeam(ctx context.Context, ID string) error
+}
+
+type TeamConsumer struct {
+	teamService TeamService
+	inbox       *Inbox
+	outbox      *Outbox
+	logger      *slog.Logger
+}
+
+func NewTeamConsumer(teamService TeamService, inbox *Inbox, outbox *Outbox, logger *slog.Logger) *TeamConsumer {
+	return &TeamConsumer{
+		teamService: teamService,
+		inbox:       inbox,
+		outbox:      outbox,
+		logger:      logger,
+	}
+}
+
+// HandleEvent обрабатывает событие, уже прошедшее проверку по контракту.
+// Kafka здесь не нужна, поэтому обработчик можно подписать и на брокер в памяти.
+func (tc *TeamConsumer) HandleEvent(ctx context.Context, event events.Event) error {
+	ctx = events.ContextWithTrace(ctx, event.Trace())
+
+	tc.logger.Info("Processing event",
+		"event_id", event.ID,
+		"event_type", event.Type,
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"trace", event.Trace())
+
+	processed, err := tc.inbox.IsProcessed(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		tc.logger.Info("Skipping already processed event",
+			"event_id", event.ID,
+			"saga_id", event.SagaID)
+		return nil
+	}
+
+	switch event.Type {
+	case events.TeamUserDeleteRequested:
+		return tc.handleTeamUserDeleteRequested(ctx, event)
+	case events.TeamUserDeleteRollback:
+		return tc.handleTeamUserDeleteRollback(ctx, event)
+	case events.TeamDeleteRequested:
+		return tc.handleTeamDeleteRequested(ctx, event)
+	case events.TeamDeleteRollback:
+		return tc.handleTeamDeleteRollback(ctx, event)
+	default:
+		tc.logger.Warn("Unknown event type", "event_type", event.Type)
+		return nil
+	}
+}
+
+func (tc *TeamConsumer) handleTeamUserDeleteRequested(ctx context.Context, event events.Event) error {
+	tc.logger.Info("Handling team user delete requested",
+		"user_id", event.UserID,
+		"saga_id", event.SagaID)
+
+	// Ответ об успехе и отметка inbox пишутся в транзакции удаления
+	deletionData, err := tc.teamService.DeleteUserFromAllTeams(ctx, event.UserID,
+		func(ctx context.Context, tx *sql.Tx, data *events.TeamDeletionData) error {
+			if _, err := tc.inbox.MarkProcessedTx(ctx, tx, event.ID); err != nil {
+				return err
+			}
+			return tc.enqueueSuccessEvent(ctx, tx, event, data)
+		})
+	if err != nil {
+		tc.logger.Error("Failed to delete user from teams",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID,
+			"error", err)
+
+		return tc.enqueueFailureEvent(ctx, event, err)
+	}
+
+	tc.logger.Info("Successfully deleted user from all teams",
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"teams_count", len(deletionData.Teams))
+
+	return nil
+}
+
+func (tc *TeamConsumer) handleTeamUserDeleteRollback(ctx context.Context, event events.Event) error {
+	tc.logger.Info("Handling team user delete rollback",
+		"user_id", event.UserID,
+		"saga_id", event.SagaID)
+
+	// rollback_data приходит из ответа на удаление: оркестратор сохраняет его
+	// в состоянии саги и возвращает в событии компенсации
+	var deletionData *events.TeamDeletionData
+	if rollbackData, exists := event.Data["rollback_data"]; exists {
+		rollbackBytes, err := json.Marshal(rollbackData)
+		if err == nil {
+			err = json.Unmarshal(rollbackBytes, &deletionData)
+		}
+		if err != nil {
+			return tc.enqueueRollbackReply(ctx, events.TeamUserDeleteRollbackFailed, event, map[string]interface{}{
+				"error": fmt.Sprintf("invalid rollback_data: %v", err),
+			})
+		}
+	}
+
+	if deletionData == nil {
+		tc.logger.Warn("Rollback event has no rollback_data, falling back to current memberships",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID)
+
+		var err error
+		deletionData, err = tc.teamService.GetUserTeamMemberships(ctx, event.UserID)
+		if err != nil {
+			tc.logger.Error("Failed to get user team memberships for rollback",
+				"user_id", event.UserID,
+				"saga_id", event.SagaID,
+				"error", err)
+			return tc.enqueueRollbackReply(ctx, events.TeamUserDeleteRollbackFailed, event, map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if err := tc.teamService.RestoreUserTeams(ctx, event.UserID, deletionData); err != nil {
+		tc.logger.Error("Failed to restore user teams",
+			"user_id", event.UserID,
+			"saga_id", event.SagaID,
+			"error", err)
+		return tc.enqueueRollbackReply(ctx, events.TeamUserDeleteRollbackFailed, event, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	tc.logger.Info("Successfully restored user teams",
+		"user_id", event.UserID,
+		"saga_id", event.SagaID,
+		"teams_count", len(deletionData.Teams))
+
+	return tc.enqueueRollbackReply(ctx, events.TeamUserDeleteRollbackCompleted, event, nil)
+}
+
+func (tc *TeamConsumer) handleTeamDeleteRequested(ctx context.Context, event events.Event) error {
+	teamID, _ := event.Data["team_id"].(string)
+
+	tc.logger.Info("Handling team delete requested",
+		"team_id", teamID,
+		"saga_id", event.SagaID)
+
+	// Ответ об успехе и отметка inbox пишутся в транзакции пометки команды
+	err := tc.teamService.DeleteTeam(ctx, teamID, event.SagaID, func(ctx context.Context, tx *sql.Tx, data *events.DeletedTeamData) error {
+		if _, err := tc.inbox.MarkProcessedTx(ctx, tx, event.ID); err != nil {
+			return err
+		}
+
+		deletedEvent := events.New(events.TeamDeleted, event.UserID, event.SagaID, map[string]interface{}{
+			"team_id":       teamID,
+			"rollback_data": data,
+		})
+		return tc.outbox.EnqueueTx(ctx, tx, events.TeamEventsTopic, deletedEvent)
+	})
+	if err != nil {
+		tc.logger.Error("Failed to delete team",
+			"team_id", teamID,
+			"saga_id", event.SagaID,
+			"error", err)
+
+		failedEvent := events.New(events.TeamDeleteFailed, event.UserID, event.SagaID, map[string]interface{}{
+			"team_id": teamID,
+			"error":   err.Error(),
+		})
+		return tc.enqueueReply(ctx, event, failedEvent)
+	}
+
+	tc.logger.Info("Successfully deleted team",
+		"team_id", teamID,
+		"saga_id", event.SagaID)
+
+	return nil
+}
+
+func (tc *TeamConsumer) handleTeamDeleteRollback(ctx context.Context, event events.Event) error {
+	teamID, _ := event.Data["team_id"].(string)
+
+	tc.logger.Info("Handling team delete rollback",
+		"team_id", teamID,
+		"saga_id", event.SagaID)
+
+	var deletionData events.DeletedTeamData
+	if rollbackData, exists := event.Data["rollback_data"]; exists {
+		rollbackBytes, err := json.Marshal(rollbackData)
+		if err == nil {
+			err = json.Unmarshal(rollbackBytes, &deletionData)
+		}
+		if err != nil {
+			return tc.enqueueRollbackReply(ctx, events.TeamDeleteRollbackFailed, event, map[string]interface{}{
+				"team_id": teamID,
+				"error":   fmt.Sprintf("invalid rollback_data: %v", err),
+			})
+		}
+	}
+
+	// Пометку поставила не эта сага (или ответ без rollback_data) — откат не
+	// должен воскрешать команду
+	if !deletionData.WasDeletedBySaga {
+		tc.logger.Info("Team was not deleted by this saga, leaving it deleted",
+			"team_id", teamID,
+			"saga_id", event.SagaID)
+
+		return tc.enqueueRollbackReply(ctx, events.TeamDeleteRollbackCompleted, event, map[string]interface{}{
+			"team_id": teamID,
+		})
+	}
+
+	if err := tc.teamService.RestoreTeam(ctx, teamID); err != nil {
+		tc.logger.Error("Failed to restore team",
+			"team_id", teamID,
+			"saga_id", event.SagaID,
+			"error", err)
+		return tc.enqueueRollbackReply(ctx, events.TeamDeleteRollbackFailed, event, map[string]interface{}{
+			"team_id": teamID,
+			"error":   err.Error(),
+		})
+	}
+
+	tc.logger.Info("Successfully restored team",
+		"team_id", teamID,
+		"saga_id", event.SagaID)
+
+	return tc.enqueueRollbackReply(ctx, events.TeamDeleteRollbackCompleted, event, map[string]interface{}{
+		"team_id": teamID,
+	})
+}
+
+func (tc *TeamConsumer) enqueueSuccessEvent(ctx context.Context, tx *sql.Tx, originalEvent events.Event, deletionData *events.TeamDeletionData) error {
+	successEvent := events.New(events.TeamUserDeleted, originalEvent.UserID, originalEvent.SagaID, map[string]interface{}{
+		"teams_deleted_count": len(deletionData.Teams),
+		"rollback_data":       deletionData,
+	})
+
+	if err := tc.outbox.EnqueueTx(ctx, tx, events.TeamEventsTopic, successEvent); err != nil {
+		tc.logger.Error("Failed to enqueue success event",
+			"user_id", originalEvent.UserID,
+			"saga_id", originalEvent.SagaID,
+			"error", err)
+		return err
+	}
+
+	tc.logger.Info("Enqueued team user deleted event",
+		"user_id", originalEvent.UserID,
+		"saga_id", originalEvent.SagaID)
+
+	return nil
+}
+
+func (tc *TeamConsumer) enqueueFailureEvent(ctx context.Context, originalEvent events.Event, originalError error) error {
+	failureEvent := events.New(events.TeamUserDeleteFailed, originalEvent.UserID, originalEvent.SagaID, map[string]interface{}{
+		"error":  originalError.Error(),
+		"reason": "failed_to_delete_user_from_teams",
+	})
+
+	if err := tc.enqueueReply(ctx, originalEvent, failureEvent); err != nil {
+		tc.logger.Error("Failed to enqueue failure event",
+			"user_id", originalEvent.UserID,
+			"saga_id", originalEvent.SagaID,
+			"error", err)
+		return err
+	}
+
+	tc.logger.Info("Enqueued team user delete failed event",
+		"user_id", originalEvent.UserID,
+		"saga_id", originalEvent.SagaID,
+		"original_error", originalError.Error())
+
+	return nil
+}
+
+// enqueueRollbackReply отвечает оркестратору на компенсацию. Восстановление
+// идемпотентно, поэтому ответ пишется после него: если запись не удалась,
+// при повторной доставке откат просто выполнится ещё раз.
+func (tc *TeamConsumer) enqueueRollbackReply(ctx context.Context, replyType events.EventType, originalEvent events.Event, data map[string]interface{}) error {
+	replyEvent := events.New(replyType, originalEvent.UserID, originalEvent.SagaID, data)
+
+	if err := tc.enqueueReply(ctx, originalEvent, replyEvent); err != nil {
+		tc.logger.Error("Failed to enqueue rollback reply",
+			"event_type", replyType,
+			"saga_id", originalEvent.SagaID,
+			"error", err)
+		return err
+	}
+
+	return nil
+}
+
+// enqueueReply пишет ответ, не сопровождающий изменения данных, в одной
+// транзакции с отметкой inbox: повторная доставка команды не отправит
+// оркестратору второй ответ.
+func (tc *TeamConsumer) enqueueReply(ctx context.Context, originalEvent events.Event, reply events.Event) error {
+	return tc.outbox.InTx(ctx, func(tx *sql.Tx) error {
+		fresh, err := tc.inbox.MarkProcessedTx(ctx, tx, originalEvent.ID)
+		if err != nil || !fresh {
+			return err
+		}
+		return tc.outbox.EnqueueTx(ctx, tx, events.TeamEventsTopic, reply)
+	})
+}
@@ -0,0 +1,13 @@
+CREATE TABLE outbox_events (
+    id         BIGSERIAL PRIMARY KEY,
+    source     VARCHAR(64)  NOT NULL,
+    topic      VARCHAR(255) NOT NULL,
+    event_key  VARCHAR(255) NOT NULL DEFAULT '',
+    payload    JSONB        NOT NULL,
+    headers    JSONB        NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    sent_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_outbox_events_unsent ON outbox_events(source, id) WHERE sent_at IS NULL;
+CREATE INDEX idx_outbox_events_sent_at ON outbox_events(sent_at);
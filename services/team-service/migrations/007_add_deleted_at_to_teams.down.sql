@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_teams_deleted_at;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS deleted_at;
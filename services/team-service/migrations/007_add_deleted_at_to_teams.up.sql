@@ -0,0 +1,3 @@
+ALTER TABLE teams ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_teams_deleted_at ON teams(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE teams ADD COLUMN deleted_by_saga VARCHAR(64);
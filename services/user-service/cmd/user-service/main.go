@@ -5,11 +5,12 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"shiroyama/events"
 	"syscall"
 	"userservice/internal/app"
 	"userservice/internal/config"
 	"userservice/internal/infrastructure/database"
-	"userservice/internal/kafka"
+	"userservice/kafka"
 )
 
 const (
@@ -38,7 +39,7 @@ func main() {
 	}
 
 	go func() {
-		if err := consumer.Start(ctx, []string{"user-deletion-saga"}); err != nil {
+		if err := consumer.Start(ctx, []string{events.UserDeletionSagaTopic}); err != nil {
 			log.Error("Kafka consumer stopped with error", "error", err)
 			cancel()
 		}
@@ -8,9 +8,9 @@ import (
 	"net"
 	"userservice/internal/config"
 	"userservice/internal/handler"
-	"userservice/internal/kafka"
 	userRepo "userservice/internal/repository/user"
 	userService "userservice/internal/service/user"
+	"userservice/kafka"
 )
 
 type App struct {
@@ -21,7 +21,7 @@ type App struct {
 }
 
 func New(log *slog.Logger, port int, db *sql.DB, cfg *config.Config) *App {
-	gRPCServer := grpc.NewServer()
+	gRPCServer := grpc.NewServer(grpc.UnaryInterceptor(traceInterceptor))
 
 	userRepository := userRepo.NewUserRepository(log, db)
 	service := userService.NewUserService(log, userRepository)
@@ -31,7 +31,10 @@ func New(log *slog.Logger, port int, db *sql.DB, cfg *config.Config) *App {
 		panic(err)
 	}
 
-	handler.Register(gRPCServer, log, service, kafkaProducer)
+	// Relay для этого outbox запускает Kafka consumer
+	outbox := kafka.NewOutbox(db, "user-service", kafkaProducer, cfg.Kafka.Outbox, log)
+
+	handler.Register(gRPCServer, log, service, outbox)
 
 	return &App{
 		log:  log,
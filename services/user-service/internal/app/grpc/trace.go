@@ -0,0 +1,33 @@
+package grpcapp
+
+import (
+	"context"
+	"shiroyama/events"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// traceInterceptor переносит request ID и traceparent из метаданных шлюза
+// в контекст запроса. Outbox берёт их оттуда для событий, которые
+// порождает запрос, например для запуска саги удаления пользователя.
+func traceInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	trace := events.Trace{
+		RequestID:   first(md.Get(events.HeaderRequestID)),
+		Traceparent: first(md.Get(events.HeaderTraceparent)),
+	}
+
+	return handler(events.ContextWithTrace(ctx, trace), req)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -9,11 +9,11 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
-	"log"
 	"log/slog"
+	"shiroyama/events"
 	"time"
 	"userservice/internal/dto"
-	"userservice/internal/kafka"
+	"userservice/kafka"
 )
 
 type UserService interface {
@@ -37,16 +37,16 @@ type UserService interface {
 
 type GrpcHandler struct {
 	userv1.UnimplementedUserServiceServer
-	log           *slog.Logger
-	userService   UserService
-	kafkaProducer *kafka.Producer
+	log         *slog.Logger
+	userService UserService
+	outbox      *kafka.Outbox
 }
 
-func Register(gRPC *grpc.Server, log *slog.Logger, userService UserService, kafkaProducer *kafka.Producer) {
+func Register(gRPC *grpc.Server, log *slog.Logger, userService UserService, outbox *kafka.Outbox) {
 	userv1.RegisterUserServiceServer(gRPC, GrpcHandler{
-		log:           log,
-		userService:   userService,
-		kafkaProducer: kafkaProducer,
+		log:         log,
+		userService: userService,
+		outbox:      outbox,
 	})
 }
 
@@ -169,23 +169,19 @@ func (handler GrpcHandler) DeleteUser(ctx context.Context, req *userv1.DeleteUse
 	}
 
 	sagaID := uuid.New().String()
-	event := kafka.Event{
-		ID:        uuid.New().String(),
-		Type:      kafka.UserDeletionRequested,
-		UserID:    req.Id,
-		Timestamp: time.Now(),
-		SagaID:    sagaID,
-		Data: map[string]interface{}{
-			"user_email":    user.Email,
-			"user_username": user.Username,
-			"initiated_by":  "grpc_request",
-		},
-	}
+	event := events.New(events.UserDeletionRequested, req.Id, sagaID, map[string]interface{}{
+		"user_email":    user.Email,
+		"user_username": user.Username,
+		"initiated_by":  "grpc_request",
+	})
 
-	if err := handler.kafkaProducer.PublishEvent("user-deletion-saga", event); err != nil {
+	if err := handler.outbox.Enqueue(ctx, events.UserDeletionSagaTopic, event); err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to initiate user deletion: %v", err)
 	}
 
-	log.Printf("User deletion saga initiated for user %s (saga: %s)", req.Id, sagaID)
+	handler.log.Info("User deletion saga initiated",
+		"user_id", req.Id,
+		"saga_id", sagaID,
+		"trace", events.TraceFromContext(ctx))
 	return &emptypb.Empty{}, nil
 }
@@ -0,0 +1,138 @@
+package kafka
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/IBM/sarama"
+	"log/slog"
+	"shiroyama/events"
+	"shiroyama/events/bus"
+	"shiroyama/messaging/dlq"
+	"sync"
+	"time"
+	"userservice/internal/config"
+	userRepo "userservice/internal/repository/user"
+)
+
+type Consumer struct {
+	consumerGroup sarama.ConsumerGroup
+	handler       bus.Handler
+	dlq           *dlq.Queue
+	inbox         *Inbox
+	outbox        *Outbox
+	cfg           config.KafkaConfig
+	logger        *slog.Logger
+}
+
+func NewConsumer(ctx context.Context, cfg *config.Config, logger *slog.Logger, db *sql.DB) (*Consumer, error) {
+	userRepository := userRepo.NewUserRepository(logger, db)
+
+	producer, err := NewKafkaProducer(cfg.Kafka.Brokers)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := NewInbox(db, "user-service", cfg.Kafka.Inbox.Retention, logger)
+	deadLetters := dlq.New(producer, "user-service", dlq.Config{
+		MaxAttempts: cfg.Kafka.DeadLetter.MaxAttempts,
+		Backoff:     cfg.Kafka.DeadLetter.Backoff,
+	}, logger)
+	outbox := NewOutbox(db, "user-service", producer, cfg.Kafka.Outbox, logger)
+	consumerHandler := NewUserConsumer(userRepository, inbox, outbox, logger)
+
+	configSarama := sarama.NewConfig()
+	configSarama.Version = sarama.V2_8_0_0
+	configSarama.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRange
+	configSarama.Consumer.Offsets.Initial = sarama.OffsetNewest
+
+	consumerGroup, err := sarama.NewConsumerGroup(cfg.Kafka.Brokers, "user-service-group", configSarama)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Consumer{
+		consumerGroup: consumerGroup,
+		handler:       consumerHandler,
+		dlq:           deadLetters,
+		inbox:         inbox,
+		outbox:        outbox,
+		cfg:           cfg.Kafka,
+		logger:        logger,
+	}, nil
+}
+
+func (c *Consumer) Setup(_ sarama.ConsumerGroupSession) error {
+	c.logger.Info("Consumer group session setup")
+	return nil
+}
+
+func (c *Consumer) Cleanup(_ sarama.ConsumerGroupSession) error {
+	c.logger.Info("Consumer group session cleanup")
+	return nil
+}
+
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		c.logger.Info("Consumed message", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+
+		if err := c.dlq.Process(session.Context(), msg, c.handleMessage); err != nil {
+			// Сообщение не обработано и не попало в DLQ — offset не коммитим
+			c.logger.Error("Failed to handle event", "offset", msg.Offset, "error", err)
+			return err
+		}
+
+		session.MarkMessage(msg, "")
+	}
+
+	return nil
+}
+
+func (c *Consumer) handleMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	event, err := events.Unmarshal(msg.Value)
+	if err != nil {
+		return dlq.Permanent(fmt.Errorf("failed to unmarshal event: %w", err))
+	}
+
+	return c.handler.HandleEvent(ctx, event)
+}
+
+func (c *Consumer) Start(ctx context.Context, topics []string) error {
+	wg := &sync.WaitGroup{}
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		c.inbox.StartCleanup(ctx, c.cfg.Inbox.CleanupInterval)
+	}()
+
+	// Relay отправляет и события gRPC-хендлера: они пишутся в ту же таблицу
+	go func() {
+		defer wg.Done()
+		c.outbox.StartRelay(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		for {
+			if err := c.consumerGroup.Consume(ctx, topics, c); err != nil {
+				c.logger.Error("Error from consumer", "error", err)
+				time.Sleep(time.Second)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	<-ctx.Done()
+
+	wg.Wait()
+
+	if err := c.consumerGroup.Close(); err != nil {
+		c.logger.Error("Error closing consumer group", "error", err)
+	}
+
+	c.logger.Info("Kafka consumer stopped")
+	return nil
+}
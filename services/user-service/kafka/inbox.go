@@ -0,0 +1,17 @@
+package kafka
+
+import (
+	"database/sql"
+	"log/slog"
+	"shiroyama/messaging/inbox"
+	"shiroyama/messaging/sqlstore"
+	"time"
+)
+
+// Inbox запоминает ID обработанных событий, чтобы повторная доставка из
+// Kafka не выполняла удаление или восстановление второй раз.
+type Inbox = inbox.Inbox[*sql.Tx]
+
+func NewInbox(db *sql.DB, consumer string, retention time.Duration, logger *slog.Logger) *Inbox {
+	return inbox.New(sqlstore.NewInbox(db), consumer, retention, logger)
+}
@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"shiroyama/events"
+)
+
+type UserRepository interface {
+	SoftDeleteUserTx(ctx context.Context, tx *sql.Tx, userID string) error
+	RestoreUserTx(ctx context.Context, tx *sql.Tx, userID string) error
+}
+
+type UserConsumer struct {
+	userRepo UserRepository
+	inbox    *Inbox
+	outbox   *Outbox
+	logger   *slog.Logger
+}
+
+func NewUserConsumer(repo UserRepository, inbox *Inbox, outbox *Outbox, logger *slog.Logger) *UserConsumer {
+	return &UserConsumer{
+		userRepo: repo,
+		inbox:    inbox,
+		outbox:   outbox,
+		logger:   logger,
+	}
+}
+
+func (uc *UserConsumer) HandleEvent(ctx context.Context, event events.Event) error {
+	ctx = events.ContextWithTrace(ctx, event.Trace())
+
+	uc.logger.Info("Processing event",
+		"event_id", event.ID,
+		"event_type", event.Type,
+		"saga_id", event.SagaID,
+		"trace", event.Trace())
+
+	switch event.Type {
+	case events.UserDeletionRequested:
+		return uc.handleUserDeletion(ctx, event)
+	case events.UserDeletionRollback:
+		return uc.handleUserDeletionRollback(ctx, event)
+	default:
+		uc.logger.Warn("Unhandled event type", "type", event.Type)
+		return nil
+	}
+}
+
+func (uc *UserConsumer) handleUserDeletion(ctx context.Context, event events.Event) error {
+	var (
+		fresh     bool
+		deleteErr error
+	)
+
+	err := uc.outbox.InTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		fresh, err = uc.inbox.MarkProcessedTx(ctx, tx, event.ID)
+		if err != nil || !fresh {
+			return err
+		}
+
+		if deleteErr = uc.userRepo.SoftDeleteUserTx(ctx, tx, event.UserID); deleteErr != nil {
+			return deleteErr
+		}
+
+		completedEvent := events.New(events.UserDeletionCompleted, event.UserID, event.SagaID, nil)
+
+		// Событие фиксируется вместе с удалением — relay отправит его после коммита
+		return uc.outbox.EnqueueTx(ctx, tx, events.UserDeletionSagaTopic, completedEvent)
+	})
+	if deleteErr != nil {
+		uc.logger.Error("Failed to delete user", "user_id", event.UserID, "saga_id", event.SagaID, "error", deleteErr)
+
+		// Отказ доставлен саге — повторять событие не нужно
+		return uc.enqueueRollback(ctx, event, deleteErr)
+	}
+	if err != nil {
+		uc.logger.Error("Failed to commit user deletion", "user_id", event.UserID, "saga_id", event.SagaID, "error", err)
+		return err
+	}
+	if !fresh {
+		uc.logger.Info("Skipping already processed event", "event_id", event.ID, "saga_id", event.SagaID)
+		return nil
+	}
+
+	uc.logger.Info("User deleted successfully", "user_id", event.UserID, "saga_id", event.SagaID)
+	return nil
+}
+
+func (uc *UserConsumer) handleUserDeletionRollback(ctx context.Context, event events.Event) error {
+	var (
+		fresh      bool
+		restoreErr error
+	)
+
+	err := uc.outbox.InTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		fresh, err = uc.inbox.MarkProcessedTx(ctx, tx, event.ID)
+		if err != nil || !fresh {
+			return err
+		}
+
+		if restoreErr = uc.userRepo.RestoreUserTx(ctx, tx, event.UserID); restoreErr != nil {
+			return restoreErr
+		}
+
+		return nil
+	})
+	if restoreErr != nil {
+		uc.logger.Error("Failed to restore user during rollback", "user_id", event.UserID, "error", restoreErr)
+		return restoreErr
+	}
+	if err != nil {
+		uc.logger.Error("Failed to commit transaction during rollback handling", "user_id", event.UserID, "error", err)
+		return err
+	}
+	if !fresh {
+		uc.logger.Info("Skipping already processed event", "event_id", event.ID, "saga_id", event.SagaID)
+		return nil
+	}
+
+	uc.logger.Info("User restoration (rollback) completed successfully", "user_id", event.UserID, "saga_id", event.SagaID)
+	return nil
+}
+
+func (uc *UserConsumer) enqueueRollback(ctx context.Context, event events.Event, cause error) error {
+	rollbackEvent := events.New(events.UserDeletionRollback, event.UserID, event.SagaID, map[string]interface{}{
+		"error": cause.Error(),
+	})
+
+	// Удаление откачено, поэтому отметка inbox пишется вместе с событием:
+	// повторная доставка не запустит компенсацию второй раз
+	err := uc.outbox.InTx(ctx, func(tx *sql.Tx) error {
+		fresh, err := uc.inbox.MarkProcessedTx(ctx, tx, event.ID)
+		if err != nil || !fresh {
+			return err
+		}
+		return uc.outbox.EnqueueTx(ctx, tx, events.UserDeletionSagaTopic, rollbackEvent)
+	})
+	if err != nil {
+		uc.logger.Error("Failed to enqueue rollback event", "user_id", event.UserID, "saga_id", event.SagaID, "error", err)
+		return err
+	}
+
+	return nil
+}
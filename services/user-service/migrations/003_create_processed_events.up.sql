@@ -0,0 +1,8 @@
+CREATE TABLE processed_events (
+    event_id     VARCHAR(64) NOT NULL,
+    consumer     VARCHAR(64) NOT NULL,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (event_id, consumer)
+);
+
+CREATE INDEX idx_processed_events_processed_at ON processed_events(processed_at);